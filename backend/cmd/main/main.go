@@ -3,11 +3,13 @@ package main
 import (
 	"log/slog"
 	"maxbot/internal/handlers"
+	"maxbot/internal/notifier"
 	"maxbot/internal/repository"
 	"maxbot/internal/services"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -23,7 +25,24 @@ import (
 
 func main() {
 	repositoryObj := repository.New()
-	serviceObj := &services.Service{Repository: repositoryObj}
+
+	// Without a bot token notifications are only written to the log
+	var notifierObj notifier.Notifier = notifier.Log{}
+	if botToken := os.Getenv("BOT_TOKEN"); botToken != "" {
+		notifierObj = notifier.NewMaxBot(botToken)
+	}
+
+	serviceObj := &services.Service{Repository: repositoryObj, Clock: repositoryObj.Clock, Notifier: notifierObj}
+
+	// How long after sending a log can still be edited or deleted
+	if window := os.Getenv("LOG_EDIT_WINDOW_MINUTES"); window != "" {
+		minutes, err := strconv.Atoi(window)
+		if err != nil || minutes <= 0 {
+			slog.Error("invalid LOG_EDIT_WINDOW_MINUTES, expected a positive number of minutes")
+			os.Exit(1)
+		}
+		serviceObj.LogEditWindow = time.Duration(minutes) * time.Minute
+	}
 	handler := &handlers.HttpHandler{Service: serviceObj}
 
 	// Run Http Server
@@ -36,6 +55,42 @@ func main() {
 	}
 	go server.ListenAndServe()
 
+	// Award badges for what players did before the achievement rules existed
+	go func() {
+		if err := serviceObj.BackfillAchievements(); err != nil {
+			slog.Error("error while backfilling achievements", "error", err.Error())
+		}
+	}()
+
+	// Close duels whose period is over or whose result is already decided, start
+	// the tournament duels left waiting, then close finished seasons
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			if err := serviceObj.ReviewActiveDuels(); err != nil {
+				slog.Error("error while reviewing duels", "error", err.Error())
+			}
+			if err := serviceObj.ResumeTournaments(); err != nil {
+				slog.Error("error while resuming tournaments", "error", err.Error())
+			}
+			if err := serviceObj.CloseEndedSeasons(); err != nil {
+				slog.Error("error while closing seasons", "error", err.Error())
+			}
+		}
+	}()
+
+	// Leaderboards are served from aggregates recalculated in the background
+	go func() {
+		ticker := time.NewTicker(10 * time.Minute)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			if err := serviceObj.RefreshLeaderboards(); err != nil {
+				slog.Error("error while refreshing leaderboards", "error", err.Error())
+			}
+		}
+	}()
+
 	// Graceful Shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
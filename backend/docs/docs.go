@@ -15,7 +15,58 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/duel/acceptInvitation": {
+        "/admin/disputes": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get disputes waiting for a resolution, the oldest first. Admins only",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_models.DisputeDb"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/hideLog": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -23,7 +74,7 @@ const docTemplate = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Accept invitation to duel using invitation hash",
+                "summary": "Hide a log from every timeline or show it again. A hidden log still counts in the duel. Admins only",
                 "parameters": [
                     {
                         "type": "string",
@@ -47,12 +98,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Accept Invitation Dto",
-                        "name": "accept_invitation_dto",
+                        "description": "Hide Log Dto",
+                        "name": "hide_log_dto",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/maxbot_internal_dto.AcceptInvitationDto"
+                            "$ref": "#/definitions/maxbot_internal_dto.HideLogDto"
                         }
                     }
                 ],
@@ -72,15 +123,15 @@ const docTemplate = `{
                 }
             }
         },
-        "/duel/contribute": {
-            "post": {
+        "/admin/reports": {
+            "get": {
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Contribute to duel, sending your message and photo",
+                "summary": "Get the review queue of reports, the oldest first. Admins only",
                 "parameters": [
                     {
                         "type": "string",
@@ -104,20 +155,20 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Create Log Dto",
-                        "name": "create_log_dto",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/maxbot_internal_dto.CreateLogDto"
-                        }
+                        "type": "string",
+                        "description": "open (default) or resolved",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_models.ReportDb"
+                            }
                         }
                     },
                     "400": {
@@ -129,7 +180,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/duel/createNew": {
+        "/admin/resolveDispute": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -137,7 +188,7 @@ const docTemplate = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create new duel",
+                "summary": "Resolve a dispute: upheld - the log does not count, otherwise it counts. An ended duel the log decided is reopened and decided again. Admins only",
                 "parameters": [
                     {
                         "type": "string",
@@ -161,12 +212,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Create New Duel Dto",
-                        "name": "create_new_duel_dto",
+                        "description": "Resolve Dispute Dto",
+                        "name": "resolve_dispute_dto",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/maxbot_internal_dto.CreateNewDuelDto"
+                            "$ref": "#/definitions/maxbot_internal_dto.ResolveDisputeDto"
                         }
                     }
                 ],
@@ -174,7 +225,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/maxbot_internal_dto.InvitationLinkDto"
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
                         }
                     },
                     "400": {
@@ -186,32 +237,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/duel/getDuelLogs": {
-            "get": {
+        "/admin/resolveReport": {
+            "post": {
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get logs of a duel",
+                "summary": "Resolve a report: hide_log hides the reported log, suspend_user suspends the reported user, dismiss does nothing. Admins only",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "duel_id",
-                        "name": "duel_id",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "description": "Resolve Report Dto",
+                        "name": "resolve_report_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ResolveReportDto"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/maxbot_internal_dto.LogDto"
-                            }
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
                         }
                     },
                     "400": {
@@ -223,7 +294,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/habit/createNew": {
+        "/admin/suspendUser": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -231,7 +302,7 @@ const docTemplate = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create new habit",
+                "summary": "Suspend an account or lift the suspension. A suspended user gets 403 on every authenticated request. Admins only",
                 "parameters": [
                     {
                         "type": "string",
@@ -255,12 +326,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Create New Habit Dto",
-                        "name": "create_new_habit_dto",
+                        "description": "Suspend User Dto",
+                        "name": "suspend_user_dto",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/maxbot_internal_dto.CreateNewHabitDto"
+                            "$ref": "#/definitions/maxbot_internal_dto.SuspendUserDto"
                         }
                     }
                 ],
@@ -280,7 +351,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/habit/getUserHabits": {
+        "/coins/audit": {
             "get": {
                 "consumes": [
                     "application/json"
@@ -288,7 +359,61 @@ const docTemplate = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get user habits",
+                "summary": "Check the coin ledger: every operation balanced, every balance equal to its entries. Admins only",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.CoinAuditDto"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/coins/getHistory": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the latest coin ledger entries of the user, the newest first",
                 "parameters": [
                     {
                         "type": "string",
@@ -310,6 +435,12 @@ const docTemplate = `{
                         "name": "photo_url",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "How many entries to return, 50 by default",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -318,7 +449,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/maxbot_internal_dto.HabitDto"
+                                "$ref": "#/definitions/maxbot_internal_models.CoinEntryDb"
                             }
                         }
                     },
@@ -331,7 +462,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/test/makeTestData": {
+        "/duel/acceptDirectInvitation": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -339,7 +470,39 @@ const docTemplate = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Make test data. Creates users witd max id's {MAXID_1, MAXID_2, MAXID_3, MAXID_4}",
+                "summary": "Accept a duel you were invited to directly. The duel starts today",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Direct Invitation Dto",
+                        "name": "direct_invitation_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.DirectInvitationDto"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -356,15 +519,15 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/getUserInfo": {
-            "get": {
+        "/duel/acceptInvitation": {
+            "post": {
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get user information, including duels he is participating in",
+                "summary": "Accept invitation to duel using invitation hash. Team duels are accepted on behalf of team_id",
                 "parameters": [
                     {
                         "type": "string",
@@ -386,13 +549,22 @@ const docTemplate = `{
                         "name": "photo_url",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "description": "Accept Invitation Dto",
+                        "name": "accept_invitation_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.AcceptInvitationDto"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/maxbot_internal_dto.UserDto"
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
                         }
                     },
                     "400": {
@@ -403,213 +575,4844 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "maxbot_internal_dto.AcceptInvitationDto": {
-            "type": "object",
-            "properties": {
-                "invitation_hash": {
-                    "type": "string"
-                }
-            }
-        },
-        "maxbot_internal_dto.CreateLogDto": {
-            "type": "object",
-            "required": [
-                "duel_id",
-                "message"
-            ],
-            "properties": {
-                "duel_id": {
-                    "type": "integer"
-                },
-                "message": {
-                    "description": "required, non-empty",
-                    "type": "string",
-                    "minLength": 1
-                },
-                "photo": {
-                    "description": "base64, optional",
-                    "type": "string"
-                }
-            }
-        },
-        "maxbot_internal_dto.CreateNewDuelDto": {
-            "type": "object",
-            "properties": {
-                "days": {
-                    "type": "integer"
-                },
-                "habit_id": {
-                    "type": "integer"
-                }
-            }
         },
-        "maxbot_internal_dto.CreateNewHabitDto": {
-            "type": "object",
-            "properties": {
-                "habit_category": {
-                    "type": "string"
-                },
-                "habit_name": {
+        "/duel/cancelInvitation": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Cancel a duel you created that nobody has accepted yet, by link or directly. The duel is closed as cancelled and stakes are returned",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Direct Invitation Dto",
+                        "name": "direct_invitation_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.DirectInvitationDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/challenge": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Challenge a user found by opponent_id or opponent_max_id to a 1v1 duel. The user gets a bot message and finds it in /duel/getDirectInvitations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Challenge Dto",
+                        "name": "challenge_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ChallengeDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_models.DuelDb"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/commentOnLog": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Comment on a log you can see or reply to a comment on it. The author of the log and of the comment get a bot message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment On Log Dto",
+                        "name": "comment_on_log_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.CommentOnLogDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.CommentCreatedDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/concedeDispute": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Agree with a dispute on your log: the log stops counting",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Dispute Dto",
+                        "name": "dispute_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.DisputeDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/contribute": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Contribute to duel, sending your message and photo. The log must meet the proof rules of the duel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Create Log Dto",
+                        "name": "create_log_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.CreateLogDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/createNew": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create new duel. proof_rules can require a photo, a minimum message length and a time of day for logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Create New Duel Dto",
+                        "name": "create_new_duel_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.CreateNewDuelDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.InvitationLinkDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/declineDirectInvitation": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Decline a duel you were invited to directly. The duel is closed as declined and stakes are returned",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Direct Invitation Dto",
+                        "name": "direct_invitation_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.DirectInvitationDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/deleteLog": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete your log shortly after sending it (15 minutes by default). The counted day, the check-in reward and the streak update are rolled back; a duel the log decided is decided again",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Delete Log Dto",
+                        "name": "delete_log_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.DeleteLogDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/disputeLog": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Dispute an opponent's log on the day it was logged or the day after, also within a day after the duel ends. With exclude the log does not count until the dispute is resolved (active duels only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Dispute Log Dto",
+                        "name": "dispute_log_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.DisputeLogDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.DisputeDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/editLog": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Change the message or the photo of your log shortly after sending it (15 minutes by default). The log must still meet the proof rules of the duel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Edit Log Dto",
+                        "name": "edit_log_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.EditLogDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/forfeit": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Give up an active 1v1 duel. The opponent wins and both ratings are updated",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Forfeit Duel Dto",
+                        "name": "forfeit_duel_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ForfeitDuelDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/getBackfills": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get backfill requests of a duel you take part in, pending and resolved",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Duel ID",
+                        "name": "duel_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_models.BackfillDb"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/getDirectInvitations": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get duels the user was invited to directly and has not answered yet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_models.DuelDb"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/getDuelLogs": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get logs of a duel. Non-participants only see logs of users whose profile is visible to them",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "duel_id",
+                        "name": "duel_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Max ID of the viewer",
+                        "name": "max_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_dto.LogDto"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/getLogComments": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get comments on a log in the order they were written. Replies have parent_id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Log ID",
+                        "name": "log_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Max ID of the viewer",
+                        "name": "max_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_models.LogCommentDb"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/reactToLog": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "React to a log you can see with one of 👍 🔥 💪 👏 😂 😮. An empty emoji removes your reaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "React To Log Dto",
+                        "name": "react_to_log_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ReactToLogDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/rematch": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Offer a rematch of an ended 1v1 duel: same habit and rules, sent directly to the former opponent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Rematch Dto",
+                        "name": "rematch_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.RematchDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_models.DuelDb"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/requestBackfill": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Ask the opponent to count a check-in for a missed past day of the duel. The check-in counts once an opponent approves it",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Request Backfill Dto",
+                        "name": "request_backfill_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.RequestBackfillDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.BackfillCreatedDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/resolveBackfill": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Approve or reject a backfill request of your opponent. An approved check-in counts like one made on that day and may end the duel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Resolve Backfill Dto",
+                        "name": "resolve_backfill_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ResolveBackfillDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/start": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Start a group duel before all places are taken. Only the creator can do it",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Start Duel Dto",
+                        "name": "start_duel_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.StartDuelDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/duel/withdrawDispute": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Withdraw your dispute: the log counts again",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Dispute Dto",
+                        "name": "dispute_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.DisputeDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/friends/accept": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Accept a friend request sent by the user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Friend Dto",
+                        "name": "friend_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.FriendDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/friends/block": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Block a user. Ends the friendship, hides profiles and logs from each other and forbids friend requests, invitations and joining each other's duels",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Friend Dto",
+                        "name": "friend_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.FriendDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/friends/blocked": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get users blocked by the user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_models.BlockedUserDb"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/friends/list": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get friends of the user with their active duels",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_models.FriendDb"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/friends/remove": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Remove a friend, or cancel or decline a pending friend request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Friend Dto",
+                        "name": "friend_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.FriendDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/friends/request": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Send a friend request. If the user has already sent you one, you become friends at once",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Friend Dto",
+                        "name": "friend_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.FriendDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.FriendRequestResultDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/friends/requests": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get pending friend requests sent to (incoming) and by the user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_models.FriendRequestDb"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/friends/suggestions": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get past opponents who are not friends yet, the most frequent opponents first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_models.FriendSuggestionDb"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/friends/unblock": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Unblock a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Friend Dto",
+                        "name": "friend_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.FriendDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/habit/createNew": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create new habit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Create New Habit Dto",
+                        "name": "create_new_habit_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.CreateNewHabitDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/habit/getUserHabits": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get user habits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_dto.HabitDto"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/get": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a page of the leaderboard. The friends scope is you and your friends",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "wins, streak, rating or completions",
+                        "name": "metric",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "week, month or all (default)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "global (default) or friends",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 20 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.LeaderboardDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/cancel": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Remove your lobby entry and cancel its duel, returning the stake",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Cancel Lobby Dto",
+                        "name": "cancel_lobby_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.CancelLobbyDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/join": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Take a lobby entry: the duel is joined and started",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Join Lobby Dto",
+                        "name": "join_lobby_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.JoinLobbyDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/list": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get open lobby entries, the closest ratings first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit category",
+                        "name": "habit_category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Duel duration",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_models.LobbyEntryDb"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/post": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Look for an opponent in the open lobby. If a player with the same habit category, duration and a close rating is waiting, the duel starts at once, otherwise a lobby entry is published",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Post To Lobby Dto",
+                        "name": "post_to_lobby_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.PostToLobbyDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.LobbyPostResultDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/report/log": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Report a log to the admins. Reason is one of: spam, abuse, inappropriate, fake_proof, other",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Report Log Dto",
+                        "name": "report_log_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ReportLogDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ReportCreatedDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/report/user": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Report a user to the admins. Reason is one of: spam, abuse, inappropriate, fake_proof, other. To stop seeing the user use /friends/block",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Report User Dto",
+                        "name": "report_user_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ReportUserDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ReportCreatedDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/season/createNew": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create a season. Admins only (ADMIN_MAX_IDS), seasons must not overlap",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Create Season Dto",
+                        "name": "create_season_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.CreateSeasonDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_models.SeasonDb"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/season/getStandings": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get season standings: live for a running season, archived with rewards for a closed one",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Season ID, the current season by default",
+                        "name": "season_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "How many places to return, 50 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.SeasonStandingsDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/season/list": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get all seasons, the latest first",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_models.SeasonDb"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/createNew": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create new team. The creator becomes its owner and first member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Create Team Dto",
+                        "name": "create_team_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.CreateTeamDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.TeamDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/getUserTeams": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get teams the user is a member of",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maxbot_internal_dto.TeamDto"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/join": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Join a team using the hash from its invitation link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Join Team Dto",
+                        "name": "join_team_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.JoinTeamDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/team/leave": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Leave a team. Check-ins in duels the team already plays still count",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Leave Team Dto",
+                        "name": "leave_team_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.LeaveTeamDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/test/makeTestData": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Make test data. Creates users witd max id's {MAXID_1, MAXID_2, MAXID_3, MAXID_4}",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/createNew": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create a tournament for a habit. Players register with the invitation link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Create Tournament Dto",
+                        "name": "create_tournament_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.CreateTournamentDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.TournamentDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/getBracket": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get tournament state: players, standings and the bracket with duels of every match",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tournament_id",
+                        "name": "tournament_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.TournamentDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/join": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Register for a tournament using the hash from its invitation link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Join Tournament Dto",
+                        "name": "join_tournament_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.JoinTournamentDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/start": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Close the registration, build the bracket and start the first duels. Only the organizer can do it",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Start Tournament Dto",
+                        "name": "start_tournament_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.StartTournamentDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/getHeadToHead": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the record of the user against another player: shared ended duels, totals, current series, habits and rematch chains",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Opponent ID",
+                        "name": "opponent_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.HeadToHeadDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/getProfile": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the profile of a user. A profile hidden from the viewer has only the name and the photo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Max ID of the viewer",
+                        "name": "max_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ProfileDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/getUserInfo": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get user information, including duels he is participating in",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.UserDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/setVisibility": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Choose who sees your profile and your logs in duels you are not sharing: everyone or friends",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Max ID",
+                        "name": "max_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Photo URL",
+                        "name": "photo_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Set Visibility Dto",
+                        "name": "set_visibility_dto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.SetVisibilityDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.MessageDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/maxbot_internal_dto.ErrorDto"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "maxbot_internal_dto.AcceptInvitationDto": {
+            "type": "object",
+            "properties": {
+                "invitation_hash": {
+                    "type": "string"
+                },
+                "team_id": {
+                    "description": "Команда, которая принимает командную дуэль",
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.AchievementDto": {
+            "type": "object",
+            "properties": {
+                "awarded_at": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.BackfillCreatedDto": {
+            "type": "object",
+            "properties": {
+                "backfill_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.CancelLobbyDto": {
+            "type": "object",
+            "properties": {
+                "entry_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.ChallengeDto": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer"
+                },
+                "habit_id": {
+                    "type": "integer"
+                },
+                "opponent_id": {
+                    "description": "Кого вызываем: внутренний ID",
+                    "type": "integer"
+                },
+                "opponent_max_id": {
+                    "description": "или MAX ID",
+                    "type": "string"
+                },
+                "proof_rules": {
+                    "description": "Требования к отметкам, по умолчанию - без требований",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/maxbot_internal_models.ProofRules"
+                        }
+                    ]
+                },
+                "schedule": {
+                    "description": "По умолчанию - каждый день",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/maxbot_internal_models.Schedule"
+                        }
+                    ]
+                },
+                "scoring_mode": {
+                    "description": "first_to_target (по умолчанию), highest_in_period, volume, last_one_standing",
+                    "type": "string"
+                },
+                "stake": {
+                    "description": "Ставка в монетах, соперник вносит такую же при принятии",
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.CoinAuditDto": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "integer"
+                },
+                "mismatched_accounts": {
+                    "description": "Баланс счёта не равен сумме его проводок",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "ok": {
+                    "type": "boolean"
+                },
+                "transactions": {
+                    "type": "integer"
+                },
+                "unbalanced_transactions": {
+                    "description": "Сумма проводок операции не равна нулю",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "maxbot_internal_dto.CommentCreatedDto": {
+            "type": "object",
+            "properties": {
+                "comment_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.CommentOnLogDto": {
+            "type": "object",
+            "properties": {
+                "log_id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "До 300 символов",
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "Ответ на комментарий, 0 - комментарий к самой отметке",
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.CreateLogDto": {
+            "type": "object",
+            "required": [
+                "duel_id",
+                "message"
+            ],
+            "properties": {
+                "duel_id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "required, non-empty",
+                    "type": "string",
+                    "minLength": 1
+                },
+                "photo": {
+                    "description": "base64, optional",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "required for habits with a daily target",
+                    "type": "number"
+                }
+            }
+        },
+        "maxbot_internal_dto.CreateNewDuelDto": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer"
+                },
+                "habit_id": {
+                    "type": "integer"
+                },
+                "max_participants": {
+                    "description": "2 (по умолчанию) - обычная дуэль, больше - групповой челлендж",
+                    "type": "integer"
+                },
+                "proof_rules": {
+                    "description": "Требования к отметкам, по умолчанию - без требований",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/maxbot_internal_models.ProofRules"
+                        }
+                    ]
+                },
+                "schedule": {
+                    "description": "По умолчанию - каждый день",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/maxbot_internal_models.Schedule"
+                        }
+                    ]
+                },
+                "scoring_mode": {
+                    "description": "first_to_target (по умолчанию), highest_in_period, volume, last_one_standing",
+                    "type": "string"
+                },
+                "stake": {
+                    "description": "Ставка в монетах, соперник вносит такую же при принятии",
+                    "type": "integer"
+                },
+                "team_id": {
+                    "description": "Если указан - дуэль команда на команду",
+                    "type": "integer"
+                },
+                "team_scoring": {
+                    "description": "combined (по умолчанию) или average",
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.CreateNewHabitDto": {
+            "type": "object",
+            "properties": {
+                "daily_target": {
+                    "description": "Сколько нужно набрать за день",
+                    "type": "number"
+                },
+                "habit_category": {
+                    "type": "string"
+                },
+                "habit_name": {
+                    "type": "string"
+                },
+                "unit": {
+                    "description": "Например \"km\" или \"L\"",
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.CreateSeasonDto": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "description": "YYYY-MM-DD, последний день сезона",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.CreateTeamDto": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.CreateTournamentDto": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "description": "Длительность каждой дуэли турнира",
+                    "type": "integer"
+                },
+                "format": {
+                    "description": "single_elimination или round_robin",
+                    "type": "string"
+                },
+                "habit_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scoring_mode": {
+                    "description": "Как в обычной дуэли, по умолчанию first_to_target",
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.DeleteLogDto": {
+            "type": "object",
+            "required": [
+                "log_id"
+            ],
+            "properties": {
+                "log_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.DirectInvitationDto": {
+            "type": "object",
+            "properties": {
+                "duel_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.DisputeDto": {
+            "type": "object",
+            "properties": {
+                "dispute_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.DisputeLogDto": {
+            "type": "object",
+            "properties": {
+                "exclude": {
+                    "description": "true - отметка не засчитывается, пока спор не решён",
+                    "type": "boolean"
+                },
+                "log_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.EditLogDto": {
+            "type": "object",
+            "required": [
+                "log_id",
+                "message"
+            ],
+            "properties": {
+                "log_id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string",
+                    "minLength": 1
+                },
+                "photo": {
+                    "description": "base64, новое фото; пусто - фото не меняется",
+                    "type": "string"
+                },
+                "remove_photo": {
+                    "description": "true - убрать фото, если новое не передано",
+                    "type": "boolean"
+                }
+            }
+        },
+        "maxbot_internal_dto.ErrorDto": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.ForfeitDuelDto": {
+            "type": "object",
+            "properties": {
+                "duel_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.FriendDto": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.FriendRequestResultDto": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "description": "pending - заявка отправлена, accepted - была встречная заявка, вы уже друзья",
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.HabitDto": {
+            "type": "object",
+            "properties": {
+                "best_streak": {
+                    "description": "Лучший стрик пользователя по привычке",
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "daily_target": {
+                    "description": "0 - привычка без числовой цели",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "streak": {
+                    "description": "Текущий стрик пользователя по привычке",
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.HeadToHeadDto": {
+            "type": "object",
+            "properties": {
+                "draws": {
+                    "type": "integer"
+                },
+                "duels": {
+                    "description": "Новые первыми",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_models.HeadToHeadDuelDb"
+                    }
+                },
+                "habits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_dto.HeadToHeadHabitDto"
+                    }
+                },
+                "losses": {
+                    "type": "integer"
+                },
+                "opponent_first_name": {
+                    "type": "string"
+                },
+                "opponent_id": {
+                    "type": "integer"
+                },
+                "opponent_photo_url": {
+                    "type": "string"
+                },
+                "rematch_chains": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_dto.RematchChainDto"
+                    }
+                },
+                "series_length": {
+                    "description": "Сколько последних дуэлей подряд с этим результатом",
+                    "type": "integer"
+                },
+                "series_result": {
+                    "description": "Результат последних дуэлей подряд: win, loss или draw",
+                    "type": "string"
+                },
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.HeadToHeadHabitDto": {
+            "type": "object",
+            "properties": {
+                "draws": {
+                    "type": "integer"
+                },
+                "habit_category": {
+                    "type": "string"
+                },
+                "habit_name": {
+                    "type": "string"
+                },
+                "losses": {
+                    "type": "integer"
+                },
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.HideLogDto": {
+            "type": "object",
+            "properties": {
+                "hidden": {
+                    "description": "false - вернуть лог в ленту",
+                    "type": "boolean"
+                },
+                "log_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.InvitationLinkDto": {
+            "type": "object",
+            "properties": {
+                "invitation_link": {
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.JoinLobbyDto": {
+            "type": "object",
+            "properties": {
+                "entry_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.JoinTeamDto": {
+            "type": "object",
+            "properties": {
+                "invitation_hash": {
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.JoinTournamentDto": {
+            "type": "object",
+            "properties": {
+                "invitation_hash": {
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.LeaderboardDto": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_models.LeaderboardEntryDb"
+                    }
+                },
+                "metric": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "description": "Пусто - это последняя страница",
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "window": {
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.LeaveTeamDto": {
+            "type": "object",
+            "properties": {
+                "team_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.LobbyPostResultDto": {
+            "type": "object",
+            "properties": {
+                "duel_id": {
+                    "type": "integer"
+                },
+                "entry_id": {
+                    "description": "Заявка в лобби, если соперник не найден",
+                    "type": "integer"
+                },
+                "matched": {
+                    "description": "true - соперник найден сразу, дуэль уже началась",
+                    "type": "boolean"
+                }
+            }
+        },
+        "maxbot_internal_dto.LogDto": {
+            "type": "object",
+            "properties": {
+                "comments_count": {
+                    "type": "integer"
+                },
+                "counted": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disputes": {
+                    "description": "История споров по отметке",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_models.DisputeDb"
+                    }
+                },
+                "duel_id": {
+                    "type": "integer"
+                },
+                "log_id": {
+                    "type": "integer"
+                },
+                "max_id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "my_reaction": {
+                    "description": "Реакция смотрящего, пусто - нет",
+                    "type": "string"
+                },
+                "owner_first_name": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "integer"
+                },
+                "photo": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "reactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_dto.ReactionCountDto"
+                    }
+                },
+                "team_id": {
+                    "description": "Команда автора в командной дуэли",
+                    "type": "integer"
+                },
+                "team_name": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "maxbot_internal_dto.MessageDto": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.PostToLobbyDto": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer"
+                },
+                "habit_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.ProfileDto": {
+            "type": "object",
+            "properties": {
+                "achievements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_dto.AchievementDto"
+                    }
+                },
+                "active_duels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_models.DuelDb"
+                    }
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "hidden": {
+                    "description": "true - профиль виден только друзьям, остальные поля пустые",
+                    "type": "boolean"
+                },
+                "is_friend": {
+                    "type": "boolean"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "streak": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.ReactToLogDto": {
+            "type": "object",
+            "properties": {
+                "emoji": {
+                    "description": "👍 🔥 💪 👏 😂 😮, пусто - убрать реакцию",
+                    "type": "string"
+                },
+                "log_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.ReactionCountDto": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "emoji": {
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.RematchChainDto": {
+            "type": "object",
+            "properties": {
+                "draws": {
+                    "type": "integer"
+                },
+                "duel_ids": {
+                    "description": "Дуэль и её реванши по порядку",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "losses": {
+                    "type": "integer"
+                },
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.RematchDto": {
+            "type": "object",
+            "properties": {
+                "duel_id": {
+                    "description": "Закончившаяся дуэль",
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.ReportCreatedDto": {
+            "type": "object",
+            "properties": {
+                "report_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.ReportLogDto": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "description": "Необязательный комментарий, до 500 символов",
+                    "type": "string"
+                },
+                "log_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "spam, abuse, inappropriate, fake_proof или other",
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.ReportUserDto": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "description": "Необязательный комментарий, до 500 символов",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "spam, abuse, inappropriate, fake_proof или other",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.RequestBackfillDto": {
+            "type": "object",
+            "required": [
+                "date",
+                "duel_id",
+                "message"
+            ],
+            "properties": {
+                "date": {
+                    "description": "YYYY-MM-DD, прошедший день дуэли",
+                    "type": "string"
+                },
+                "duel_id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string",
+                    "minLength": 1
+                },
+                "photo": {
+                    "description": "base64, optional",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "required for habits with a daily target",
+                    "type": "number"
+                }
+            }
+        },
+        "maxbot_internal_dto.ResolveBackfillDto": {
+            "type": "object",
+            "properties": {
+                "approved": {
+                    "description": "true - отметка засчитывается, false - запрос отклонён",
+                    "type": "boolean"
+                },
+                "backfill_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.ResolveDisputeDto": {
+            "type": "object",
+            "properties": {
+                "dispute_id": {
+                    "type": "integer"
+                },
+                "upheld": {
+                    "description": "true - отметка не засчитывается, false - остаётся в силе",
+                    "type": "boolean"
+                }
+            }
+        },
+        "maxbot_internal_dto.ResolveReportDto": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "hide_log, suspend_user или dismiss",
+                    "type": "string"
+                },
+                "report_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.SeasonStandingsDto": {
+            "type": "object",
+            "properties": {
+                "season": {
+                    "$ref": "#/definitions/maxbot_internal_models.SeasonDb"
+                },
+                "standings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_models.SeasonStandingDb"
+                    }
+                }
+            }
+        },
+        "maxbot_internal_dto.SetVisibilityDto": {
+            "type": "object",
+            "properties": {
+                "visibility": {
+                    "description": "everyone или friends",
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_dto.StartDuelDto": {
+            "type": "object",
+            "properties": {
+                "duel_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.StartTournamentDto": {
+            "type": "object",
+            "properties": {
+                "tournament_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.SuspendUserDto": {
+            "type": "object",
+            "properties": {
+                "suspended": {
+                    "description": "false - снять блокировку",
+                    "type": "boolean"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.TeamDto": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "invitation_link": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_dto.TeamMemberDto"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.TeamMemberDto": {
+            "type": "object",
+            "properties": {
+                "first_name": {
+                    "type": "string"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.TournamentDto": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "habit_id": {
+                    "type": "integer"
+                },
+                "habit_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invitation_link": {
+                    "type": "string"
+                },
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_models.TournamentMatchDb"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organizer_id": {
+                    "type": "integer"
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_dto.TournamentPlayerDto"
+                    }
+                },
+                "scoring_mode": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "winner_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.TournamentPlayerDto": {
+            "type": "object",
+            "properties": {
+                "eliminated": {
+                    "type": "boolean"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "points": {
+                    "description": "Круговой турнир: 1 за победу, 0.5 за ничью",
+                    "type": "number"
+                },
+                "seed": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_dto.UserDto": {
+            "type": "object",
+            "properties": {
+                "achievements": {
+                    "description": "Полученные бейджи",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_dto.AchievementDto"
+                    }
+                },
+                "category_ratings": {
+                    "description": "Рейтинг по категориям привычек",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_models.CategoryRatingDb"
+                    }
+                },
+                "coins": {
+                    "description": "Баланс монет без замороженных ставок",
+                    "type": "integer"
+                },
+                "duels_info": {
+                    "description": "Дуэльки в которых участвует юзер",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_models.DuelDb"
+                    }
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "frozen_days": {
+                    "description": "Дни, пропуск которых был покрыт заморозкой",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "last_time_contributed": {
+                    "type": "string"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "rating": {
+                    "description": "Общий рейтинг Эло",
+                    "type": "integer"
+                },
+                "rating_history": {
+                    "description": "Последние изменения рейтинга",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_models.RatingHistoryDb"
+                    }
+                },
+                "season": {
+                    "description": "Текущий сезон, null - сезон не идёт",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/maxbot_internal_models.SeasonDb"
+                        }
+                    ]
+                },
+                "season_completions": {
+                    "description": "Засчитанные отметки в текущем сезоне",
+                    "type": "integer"
+                },
+                "season_duels": {
+                    "description": "Завершённые дуэли в текущем сезоне",
+                    "type": "integer"
+                },
+                "season_rank": {
+                    "description": "Место в сезоне, 0 - ещё не играл",
+                    "type": "integer"
+                },
+                "season_wins": {
+                    "description": "Победы в текущем сезоне",
+                    "type": "integer"
+                },
+                "streak": {
+                    "description": "Стрик из привычек",
+                    "type": "integer"
+                },
+                "streak_freezes": {
+                    "description": "Доступные заморозки стрика",
+                    "type": "integer"
+                },
+                "visibility": {
+                    "description": "Кто видит профиль и отметки: everyone или friends",
+                    "type": "string"
+                },
+                "winrate": {
+                    "description": "Винрейт сразу в процентах",
+                    "type": "number"
+                },
+                "wins": {
+                    "description": "Победы",
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_models.BackfillDb": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "day": {
+                    "description": "День, за который просят засчитать отметку",
+                    "type": "string"
+                },
+                "duel_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "log_id": {
+                    "description": "Отметка, созданная после одобрения",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sql.NullInt64"
+                        }
+                    ]
+                },
+                "message": {
+                    "type": "string"
+                },
+                "owner_first_name": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "integer"
+                },
+                "photo": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "resolved_at": {
+                    "$ref": "#/definitions/sql.NullString"
+                },
+                "resolved_by": {
+                    "$ref": "#/definitions/sql.NullInt64"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "maxbot_internal_models.BlockedUserDb": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_models.CategoryRatingDb": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_models.CoinEntryDb": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Положительная - зачисление, отрицательная - списание",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "duel_id": {
+                    "$ref": "#/definitions/sql.NullInt64"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_models.DisputeDb": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disputer_first_name": {
+                    "type": "string"
+                },
+                "disputer_id": {
+                    "type": "integer"
+                },
+                "duel_id": {
+                    "type": "integer"
+                },
+                "excluded": {
+                    "description": "Отметка не засчитывается, пока спор открыт",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "log_id": {
+                    "type": "integer"
+                },
+                "owner_id": {
+                    "description": "Автор оспоренной отметки",
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "resolution": {
+                    "$ref": "#/definitions/sql.NullString"
+                },
+                "resolved_at": {
+                    "$ref": "#/definitions/sql.NullString"
+                },
+                "resolved_by": {
+                    "$ref": "#/definitions/sql.NullInt64"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_models.DuelDb": {
+            "type": "object",
+            "properties": {
+                "duel_type": {
+                    "type": "string"
+                },
+                "duration_in_days": {
+                    "type": "integer"
+                },
+                "end_date": {
+                    "$ref": "#/definitions/sql.NullString"
+                },
+                "habit_category": {
+                    "type": "string"
+                },
+                "habit_daily_target": {
+                    "description": "0 - привычка без числовой цели",
+                    "type": "number"
+                },
+                "habit_id": {
+                    "type": "integer"
+                },
+                "habit_name": {
+                    "type": "string"
+                },
+                "habit_unit": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invited_user_id": {
+                    "description": "Кому отправлено приглашение, если оно не по ссылке",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sql.NullInt64"
+                        }
+                    ]
+                },
+                "max_participants": {
+                    "type": "integer"
+                },
+                "participants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_models.ParticipantDb"
+                    }
+                },
+                "proof_rules": {
+                    "description": "Требования к отметкам",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/maxbot_internal_models.ProofRules"
+                        }
+                    ]
+                },
+                "rematch_of": {
+                    "description": "Дуэль, реваншем которой является эта",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sql.NullInt64"
+                        }
+                    ]
+                },
+                "schedule": {
+                    "$ref": "#/definitions/maxbot_internal_models.Schedule"
+                },
+                "scoring_mode": {
+                    "type": "string"
+                },
+                "stake": {
+                    "description": "Ставка каждого участника в монетах, 0 - без ставки",
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "target": {
+                    "description": "Сколько отметок по расписанию нужно для победы",
+                    "type": "integer"
+                },
+                "team_scoring": {
+                    "type": "string"
+                },
+                "teams": {
+                    "description": "Только для командных дуэлей",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_models.DuelTeamDb"
+                    }
+                },
+                "user1_best_streak": {
+                    "type": "integer"
+                },
+                "user1_completed": {
+                    "type": "integer"
+                },
+                "user1_first_name": {
+                    "type": "string"
+                },
+                "user1_id": {
+                    "type": "integer"
+                },
+                "user1_photo_url": {
+                    "$ref": "#/definitions/sql.NullString"
+                },
+                "user1_streak": {
+                    "type": "integer"
+                },
+                "user1_volume": {
+                    "type": "number"
+                },
+                "user2_best_streak": {
+                    "type": "integer"
+                },
+                "user2_completed": {
+                    "type": "integer"
+                },
+                "user2_first_name": {
+                    "$ref": "#/definitions/sql.NullString"
+                },
+                "user2_id": {
+                    "$ref": "#/definitions/sql.NullInt64"
+                },
+                "user2_photo_url": {
+                    "$ref": "#/definitions/sql.NullString"
+                },
+                "user2_streak": {
+                    "type": "integer"
+                },
+                "user2_volume": {
+                    "type": "number"
+                },
+                "winner_id": {
+                    "$ref": "#/definitions/sql.NullInt64"
+                },
+                "winner_team_id": {
+                    "$ref": "#/definitions/sql.NullInt64"
+                }
+            }
+        },
+        "maxbot_internal_models.DuelTeamDb": {
+            "type": "object",
+            "properties": {
+                "members": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "place": {
+                    "$ref": "#/definitions/sql.NullInt64"
+                },
+                "score": {
+                    "description": "Сумма или среднее число отметок, в зависимости от team_scoring",
+                    "type": "number"
+                },
+                "team_id": {
+                    "type": "integer"
+                },
+                "volume": {
+                    "description": "Сумма или средний объём",
+                    "type": "number"
+                }
+            }
+        },
+        "maxbot_internal_models.FriendDb": {
+            "type": "object",
+            "properties": {
+                "active_duels": {
+                    "description": "Активные дуэли друга",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/maxbot_internal_models.DuelDb"
+                    }
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_models.FriendRequestDb": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "incoming": {
+                    "description": "true - заявка пришла пользователю, false - отправлена им",
+                    "type": "boolean"
+                },
+                "photo_url": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "maxbot_internal_dto.ErrorDto": {
+        "maxbot_internal_models.FriendSuggestionDb": {
             "type": "object",
             "properties": {
-                "details": {
+                "first_name": {
                     "type": "string"
                 },
-                "error": {
+                "photo_url": {
                     "type": "string"
+                },
+                "shared_duels": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "maxbot_internal_dto.HabitDto": {
+        "maxbot_internal_models.HeadToHeadDuelDb": {
             "type": "object",
             "properties": {
-                "category": {
+                "completed": {
+                    "type": "integer"
+                },
+                "duel_id": {
+                    "type": "integer"
+                },
+                "end_date": {
                     "type": "string"
                 },
-                "id": {
+                "habit_category": {
+                    "type": "string"
+                },
+                "habit_name": {
+                    "type": "string"
+                },
+                "opponent_completed": {
                     "type": "integer"
                 },
-                "name": {
+                "rematch_of": {
+                    "$ref": "#/definitions/sql.NullInt64"
+                },
+                "result": {
+                    "type": "string"
+                },
+                "start_date": {
                     "type": "string"
                 }
             }
         },
-        "maxbot_internal_dto.InvitationLinkDto": {
+        "maxbot_internal_models.LeaderboardEntryDb": {
             "type": "object",
             "properties": {
-                "invitation_link": {
+                "first_name": {
+                    "type": "string"
+                },
+                "photo_url": {
                     "type": "string"
+                },
+                "rank": {
+                    "description": "Равные значения делят место",
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "maxbot_internal_dto.LogDto": {
+        "maxbot_internal_models.LobbyEntryDb": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "duel_id": {
+                "days": {
                     "type": "integer"
                 },
-                "log_id": {
+                "duel_id": {
+                    "description": "Дуэль в статусе invited, которая начнётся при подборе соперника",
                     "type": "integer"
                 },
-                "max_id": {
+                "first_name": {
                     "type": "string"
                 },
-                "message": {
+                "habit_category": {
                     "type": "string"
                 },
-                "owner_id": {
+                "habit_name": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "photo": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "photo_url": {
+                    "type": "string"
+                },
+                "rating": {
+                    "description": "Рейтинг автора на момент публикации",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "maxbot_internal_dto.MessageDto": {
+        "maxbot_internal_models.LogCommentDb": {
             "type": "object",
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "log_id": {
+                    "type": "integer"
+                },
                 "message": {
                     "type": "string"
+                },
+                "parent_id": {
+                    "description": "Комментарий, на который это ответ",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sql.NullInt64"
+                        }
+                    ]
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "maxbot_internal_dto.UserDto": {
+        "maxbot_internal_models.ParticipantDb": {
             "type": "object",
             "properties": {
-                "duels_info": {
-                    "description": "Дуэльки в которых участвует юзер",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/maxbot_internal_models.DuelDb"
-                    }
+                "best_streak": {
+                    "type": "integer"
                 },
-                "first_name": {
-                    "type": "string"
+                "completed": {
+                    "type": "integer"
                 },
-                "last_time_contributed": {
+                "first_name": {
                     "type": "string"
                 },
                 "photo_url": {
-                    "type": "string"
+                    "$ref": "#/definitions/sql.NullString"
+                },
+                "place": {
+                    "description": "Место после окончания дуэли",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sql.NullInt64"
+                        }
+                    ]
                 },
                 "streak": {
-                    "description": "Стрик из привычек",
                     "type": "integer"
                 },
-                "winrate": {
-                    "description": "Винрейт сразу в процентах",
+                "team_id": {
+                    "description": "Команда участника в командной дуэли",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sql.NullInt64"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "volume": {
                     "type": "number"
+                }
+            }
+        },
+        "maxbot_internal_models.ProofRules": {
+            "type": "object",
+            "properties": {
+                "min_message_length": {
+                    "description": "Минимальная длина сообщения в символах",
+                    "type": "integer"
                 },
-                "wins": {
-                    "description": "Победы",
+                "not_after": {
+                    "description": "ЧЧ:ММ - отметки с этого времени не принимаются, например 07:00 для \"подъём до 7\"",
+                    "type": "string"
+                },
+                "not_before": {
+                    "description": "ЧЧ:ММ - отметки раньше не принимаются",
+                    "type": "string"
+                },
+                "photo_required": {
+                    "type": "boolean"
+                },
+                "timezone": {
+                    "description": "Часовой пояс для окна времени, например Europe/Moscow. Пусто - время сервера",
+                    "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_models.RatingHistoryDb": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "Пусто - общий рейтинг",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "duel_id": {
+                    "type": "integer"
+                },
+                "new_rating": {
+                    "type": "integer"
+                },
+                "old_rating": {
                     "type": "integer"
+                },
+                "result": {
+                    "type": "string"
                 }
             }
         },
-        "maxbot_internal_models.DuelDb": {
+        "maxbot_internal_models.ReportDb": {
             "type": "object",
             "properties": {
-                "duration_in_days": {
+                "action": {
+                    "$ref": "#/definitions/sql.NullString"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "end_date": {
+                "log_hidden": {
+                    "type": "boolean"
+                },
+                "log_id": {
+                    "description": "Пусто - жалоба на пользователя",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sql.NullInt64"
+                        }
+                    ]
+                },
+                "log_message": {
                     "$ref": "#/definitions/sql.NullString"
                 },
-                "habit_category": {
+                "reason": {
                     "type": "string"
                 },
-                "habit_id": {
+                "reporter_first_name": {
+                    "type": "string"
+                },
+                "reporter_id": {
                     "type": "integer"
                 },
-                "habit_name": {
+                "resolved_by": {
+                    "$ref": "#/definitions/sql.NullInt64"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user_first_name": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "На кого пожаловались: автор лога или сам пользователь",
+                    "type": "integer"
+                },
+                "user_suspended": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "maxbot_internal_models.Schedule": {
+            "type": "object",
+            "properties": {
+                "times_per_week": {
+                    "description": "Для weekly",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "weekdays": {
+                    "description": "ISO дни недели: 1 - понедельник, 7 - воскресенье",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "maxbot_internal_models.SeasonDb": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "description": "Последний день сезона включительно",
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "name": {
+                    "type": "string"
+                },
                 "start_date": {
                     "type": "string"
                 },
                 "status": {
                     "type": "string"
+                }
+            }
+        },
+        "maxbot_internal_models.SeasonStandingDb": {
+            "type": "object",
+            "properties": {
+                "completions": {
+                    "type": "integer"
                 },
-                "user1_completed": {
+                "duels_played": {
                     "type": "integer"
                 },
-                "user1_first_name": {
+                "first_name": {
                     "type": "string"
                 },
-                "user1_id": {
+                "photo_url": {
+                    "type": "string"
+                },
+                "rank": {
                     "type": "integer"
                 },
-                "user1_photo_url": {
-                    "$ref": "#/definitions/sql.NullString"
+                "reward": {
+                    "description": "Награда по итогам закрытого сезона",
+                    "type": "string"
                 },
-                "user2_completed": {
+                "user_id": {
                     "type": "integer"
                 },
-                "user2_first_name": {
-                    "$ref": "#/definitions/sql.NullString"
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "maxbot_internal_models.TournamentMatchDb": {
+            "type": "object",
+            "properties": {
+                "duel_id": {
+                    "$ref": "#/definitions/sql.NullInt64"
                 },
-                "user2_id": {
+                "finished": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "player1_id": {
+                    "description": "Пусто, пока игрок не прошёл из предыдущего раунда",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sql.NullInt64"
+                        }
+                    ]
+                },
+                "player2_id": {
                     "$ref": "#/definitions/sql.NullInt64"
                 },
-                "user2_photo_url": {
-                    "$ref": "#/definitions/sql.NullString"
+                "position": {
+                    "type": "integer"
+                },
+                "round": {
+                    "type": "integer"
                 },
                 "winner_id": {
                     "$ref": "#/definitions/sql.NullInt64"
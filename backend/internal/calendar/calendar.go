@@ -0,0 +1,129 @@
+// Package calendar holds the day rules of duel schedules, shared by the
+// services that validate check-ins and the repository that counts streaks.
+package calendar
+
+import (
+	"maxbot/internal/clock"
+	"maxbot/internal/models"
+	"time"
+)
+
+func isoWeekday(day time.Time) int {
+	weekday := int(day.Weekday())
+	if weekday == 0 {
+		return 7
+	}
+	return weekday
+}
+
+// IsScheduledDay reports whether a check-in can be made on the day. Weekly
+// schedules allow any day, the limit per week is checked separately.
+func IsScheduledDay(schedule models.Schedule, day time.Time) bool {
+	if schedule.Type != models.ScheduleWeekdays {
+		return true
+	}
+	for _, weekday := range schedule.Weekdays {
+		if weekday == isoWeekday(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// WeekBounds returns the first and the last day of the ISO week containing day.
+func WeekBounds(day time.Time) (time.Time, time.Time) {
+	monday := day.AddDate(0, 0, 1-isoWeekday(day))
+	return monday, monday.AddDate(0, 0, 6)
+}
+
+// Period is the schedule of one duel from its first to its last day together
+// with the days the player's check-ins counted in it.
+type Period struct {
+	Schedule models.Schedule
+	Start    time.Time
+	End      time.Time
+	Counted  map[string]bool
+}
+
+// Streak returns the current and the best number of consecutive scheduled
+// check-ins over the periods. Only a missed scheduled check-in breaks the
+// streak: days off of a weekdays schedule do not, and a weekly schedule is
+// broken by a week that is over with fewer check-ins than required. Today
+// is not missed yet.
+func Streak(periods []Period, today time.Time) (int, int) {
+	if len(periods) == 0 {
+		return 0, 0
+	}
+	from, to := periods[0].Start, periods[0].End
+	for _, period := range periods[1:] {
+		if period.Start.Before(from) {
+			from = period.Start
+		}
+		if period.End.After(to) {
+			to = period.End
+		}
+	}
+	if to.After(today) {
+		to = today
+	}
+
+	current, best := 0, 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(clock.DateLayout)
+		checkedIn := false
+		for _, period := range periods {
+			checkedIn = checkedIn || period.Counted[date]
+		}
+		if checkedIn {
+			current++
+			if current > best {
+				best = current
+			}
+		}
+		if day.Before(today) && missed(periods, day, checkedIn) {
+			current = 0
+		}
+	}
+	return current, best
+}
+
+// missed reports whether a scheduled check-in of any period was missed on the
+// day. A weekly check-in is missed on the last day of a week short of check-ins.
+func missed(periods []Period, day time.Time, checkedIn bool) bool {
+	for _, period := range periods {
+		if day.Before(period.Start) || day.After(period.End) {
+			continue
+		}
+		if period.Schedule.Type != models.ScheduleWeekly {
+			if !checkedIn && IsScheduledDay(period.Schedule, day) {
+				return true
+			}
+			continue
+		}
+
+		monday, weekEnd := WeekBounds(day)
+		if weekEnd.After(period.End) {
+			weekEnd = period.End
+		}
+		if !day.Equal(weekEnd) {
+			continue
+		}
+		if monday.Before(period.Start) {
+			monday = period.Start
+		}
+		required, have := 0, 0
+		for d := monday; !d.After(weekEnd); d = d.AddDate(0, 0, 1) {
+			required++
+			if period.Counted[d.Format(clock.DateLayout)] {
+				have++
+			}
+		}
+		if required > period.Schedule.TimesPerWeek {
+			required = period.Schedule.TimesPerWeek
+		}
+		if have < required {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package calendar
+
+import (
+	"maxbot/internal/clock"
+	"maxbot/internal/models"
+	"testing"
+	"time"
+)
+
+func day(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(clock.DateLayout, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func counted(days ...string) map[string]bool {
+	result := map[string]bool{}
+	for _, d := range days {
+		result[d] = true
+	}
+	return result
+}
+
+// The periods start on Monday 2025-03-03 and last two weeks.
+func TestStreak(t *testing.T) {
+	weekdays := models.Schedule{Type: models.ScheduleWeekdays, Weekdays: []int{1, 2, 3, 4, 5}}
+	weekly := models.Schedule{Type: models.ScheduleWeekly, TimesPerWeek: 3}
+	daily := models.Schedule{Type: models.ScheduleDaily}
+
+	tests := []struct {
+		name        string
+		schedule    models.Schedule
+		counted     map[string]bool
+		today       string
+		wantCurrent int
+		wantBest    int
+	}{
+		{
+			name:     "weekdays streak goes on over the weekend",
+			schedule: weekdays,
+			counted: counted("2025-03-03", "2025-03-04", "2025-03-05", "2025-03-06", "2025-03-07",
+				"2025-03-10", "2025-03-11"),
+			today:       "2025-03-11",
+			wantCurrent: 7,
+			wantBest:    7,
+		},
+		{
+			name:        "weekdays streak is not missed today yet",
+			schedule:    weekdays,
+			counted:     counted("2025-03-06", "2025-03-07"),
+			today:       "2025-03-10",
+			wantCurrent: 2,
+			wantBest:    2,
+		},
+		{
+			name:        "missed scheduled weekday breaks the streak",
+			schedule:    weekdays,
+			counted:     counted("2025-03-03", "2025-03-04", "2025-03-06", "2025-03-07", "2025-03-10"),
+			today:       "2025-03-10",
+			wantCurrent: 3,
+			wantBest:    3,
+		},
+		{
+			name:        "missed monday after the weekend breaks the streak",
+			schedule:    weekdays,
+			counted:     counted("2025-03-06", "2025-03-07"),
+			today:       "2025-03-11",
+			wantCurrent: 0,
+			wantBest:    2,
+		},
+		{
+			name:        "weekly days off do not break the streak",
+			schedule:    weekly,
+			counted:     counted("2025-03-03", "2025-03-05", "2025-03-08", "2025-03-11"),
+			today:       "2025-03-12",
+			wantCurrent: 4,
+			wantBest:    4,
+		},
+		{
+			name:        "weekly streak breaks after a week short of check-ins",
+			schedule:    weekly,
+			counted:     counted("2025-03-03", "2025-03-05", "2025-03-11"),
+			today:       "2025-03-12",
+			wantCurrent: 1,
+			wantBest:    2,
+		},
+		{
+			name:        "daily streak breaks on any missed day",
+			schedule:    daily,
+			counted:     counted("2025-03-03", "2025-03-04", "2025-03-06"),
+			today:       "2025-03-07",
+			wantCurrent: 1,
+			wantBest:    2,
+		},
+		{
+			name:        "daily streak is lost after a missed yesterday",
+			schedule:    daily,
+			counted:     counted("2025-03-03", "2025-03-04"),
+			today:       "2025-03-06",
+			wantCurrent: 0,
+			wantBest:    2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period := Period{
+				Schedule: tt.schedule,
+				Start:    day(t, "2025-03-03"),
+				End:      day(t, "2025-03-16"),
+				Counted:  tt.counted,
+			}
+			current, best := Streak([]Period{period}, day(t, tt.today))
+			if current != tt.wantCurrent || best != tt.wantBest {
+				t.Errorf("Streak() = %d, %d, want %d, %d", current, best, tt.wantCurrent, tt.wantBest)
+			}
+		})
+	}
+}
+
+// A habit streak spans several duels: a day off in one duel is still a
+// scheduled day in the other.
+func TestStreakAcrossDuels(t *testing.T) {
+	weekdays := Period{
+		Schedule: models.Schedule{Type: models.ScheduleWeekdays, Weekdays: []int{1, 2, 3, 4, 5}},
+		Start:    day(t, "2025-03-03"),
+		End:      day(t, "2025-03-16"),
+		Counted:  counted("2025-03-06", "2025-03-07", "2025-03-10"),
+	}
+	daily := Period{
+		Schedule: models.Schedule{Type: models.ScheduleDaily},
+		Start:    day(t, "2025-03-08"),
+		End:      day(t, "2025-03-14"),
+		Counted:  counted("2025-03-08"),
+	}
+
+	current, best := Streak([]Period{weekdays, daily}, day(t, "2025-03-10"))
+	if current != 1 || best != 3 {
+		t.Errorf("Streak() = %d, %d, want 1, 3: sunday is scheduled in the daily duel", current, best)
+	}
+}
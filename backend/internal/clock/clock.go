@@ -0,0 +1,66 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// DateLayout is the format used for every DATE value passed to and from the db.
+const DateLayout = "2006-01-02"
+
+type Clock interface {
+	Now() time.Time
+	Today() string
+}
+
+type Real struct{}
+
+var _ Clock = Real{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+func (r Real) Today() string {
+	return r.Now().Format(DateLayout)
+}
+
+// Fake is a manually controlled clock, used to simulate several days of a duel.
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+var _ Clock = &Fake{}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) Today() string {
+	return f.Now().Format(DateLayout)
+}
+
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func (f *Fake) AdvanceDays(days int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.AddDate(0, 0, days)
+}
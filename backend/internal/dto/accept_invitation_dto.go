@@ -2,4 +2,5 @@ package dto
 
 type AcceptInvitationDto struct {
 	InvitationHash string `json:"invitation_hash"`
+	TeamId         int64  `json:"team_id,omitempty"` // Команда, которая принимает командную дуэль
 }
@@ -0,0 +1,8 @@
+package dto
+
+type AchievementDto struct {
+	Code        string `json:"code"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	AwardedAt   string `json:"awarded_at"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type BackfillCreatedDto struct {
+	BackfillId int64 `json:"backfill_id"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type CancelLobbyDto struct {
+	EntryId int64 `json:"entry_id"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "maxbot/internal/models"
+
+type ChallengeDto struct {
+	OpponentId    int64              `json:"opponent_id,omitempty"`     // Кого вызываем: внутренний ID
+	OpponentMaxId string             `json:"opponent_max_id,omitempty"` // или MAX ID
+	HabitId       int                `json:"habit_id"`
+	Days          int                `json:"days"`
+	Schedule      *models.Schedule   `json:"schedule,omitempty"`     // По умолчанию - каждый день
+	ScoringMode   string             `json:"scoring_mode,omitempty"` // first_to_target (по умолчанию), highest_in_period, volume, last_one_standing
+	Stake         int64              `json:"stake,omitempty"`        // Ставка в монетах, соперник вносит такую же при принятии
+	ProofRules    *models.ProofRules `json:"proof_rules,omitempty"`  // Требования к отметкам, по умолчанию - без требований
+}
@@ -0,0 +1,9 @@
+package dto
+
+type CoinAuditDto struct {
+	Ok                     bool    `json:"ok"`
+	Accounts               int     `json:"accounts"`
+	Transactions           int     `json:"transactions"`
+	MismatchedAccounts     []int64 `json:"mismatched_accounts"`     // Баланс счёта не равен сумме его проводок
+	UnbalancedTransactions []int64 `json:"unbalanced_transactions"` // Сумма проводок операции не равна нулю
+}
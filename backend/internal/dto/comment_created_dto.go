@@ -0,0 +1,5 @@
+package dto
+
+type CommentCreatedDto struct {
+	CommentId int64 `json:"comment_id"`
+}
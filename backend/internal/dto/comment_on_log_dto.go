@@ -0,0 +1,7 @@
+package dto
+
+type CommentOnLogDto struct {
+	LogId    int64  `json:"log_id"`
+	ParentId int64  `json:"parent_id,omitempty"` // Ответ на комментарий, 0 - комментарий к самой отметке
+	Message  string `json:"message"`             // До 300 символов
+}
@@ -1,6 +1,15 @@
 package dto
 
+import "maxbot/internal/models"
+
 type CreateNewDuelDto struct {
-	HabitId int `json:"habit_id"`
-	Days    int `json:"days"`
+	HabitId         int                `json:"habit_id"`
+	Days            int                `json:"days"`
+	Schedule        *models.Schedule   `json:"schedule,omitempty"`         // По умолчанию - каждый день
+	ScoringMode     string             `json:"scoring_mode,omitempty"`     // first_to_target (по умолчанию), highest_in_period, volume, last_one_standing
+	MaxParticipants int                `json:"max_participants,omitempty"` // 2 (по умолчанию) - обычная дуэль, больше - групповой челлендж
+	TeamId          int64              `json:"team_id,omitempty"`          // Если указан - дуэль команда на команду
+	TeamScoring     string             `json:"team_scoring,omitempty"`     // combined (по умолчанию) или average
+	Stake           int64              `json:"stake,omitempty"`            // Ставка в монетах, соперник вносит такую же при принятии
+	ProofRules      *models.ProofRules `json:"proof_rules,omitempty"`      // Требования к отметкам, по умолчанию - без требований
 }
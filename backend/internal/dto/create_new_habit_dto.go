@@ -1,6 +1,8 @@
 package dto
 
 type CreateNewHabitDto struct {
-	HabitName     string `json:"habit_name"`
-	HabitCategory string `json:"habit_category"`
+	HabitName     string  `json:"habit_name"`
+	HabitCategory string  `json:"habit_category"`
+	Unit          string  `json:"unit,omitempty"`         // Например "km" или "L"
+	DailyTarget   float64 `json:"daily_target,omitempty"` // Сколько нужно набрать за день
 }
@@ -1,7 +1,8 @@
 package dto
 
 type CreateLogDto struct {
-	DuelID  int64  `json:"duel_id" binding:"required"`
-	Message string `json:"message" binding:"required,min=1"` // required, non-empty
-	Photo   string `json:"photo,omitempty"`                  // base64, optional
+	DuelID  int64    `json:"duel_id" binding:"required"`
+	Message string   `json:"message" binding:"required,min=1"` // required, non-empty
+	Photo   string   `json:"photo,omitempty"`                  // base64, optional
+	Value   *float64 `json:"value,omitempty"`                  // required for habits with a daily target
 }
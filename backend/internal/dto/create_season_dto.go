@@ -0,0 +1,7 @@
+package dto
+
+type CreateSeasonDto struct {
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"` // YYYY-MM-DD
+	EndDate   string `json:"end_date"`   // YYYY-MM-DD, последний день сезона
+}
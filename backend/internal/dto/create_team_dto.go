@@ -0,0 +1,5 @@
+package dto
+
+type CreateTeamDto struct {
+	Name string `json:"name"`
+}
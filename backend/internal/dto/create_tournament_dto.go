@@ -0,0 +1,9 @@
+package dto
+
+type CreateTournamentDto struct {
+	Name        string `json:"name"`
+	HabitId     int    `json:"habit_id"`
+	Days        int    `json:"days"`                   // Длительность каждой дуэли турнира
+	ScoringMode string `json:"scoring_mode,omitempty"` // Как в обычной дуэли, по умолчанию first_to_target
+	Format      string `json:"format"`                 // single_elimination или round_robin
+}
@@ -0,0 +1,5 @@
+package dto
+
+type DeleteLogDto struct {
+	LogId int64 `json:"log_id" binding:"required"`
+}
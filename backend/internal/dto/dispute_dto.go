@@ -0,0 +1,5 @@
+package dto
+
+type DisputeDto struct {
+	DisputeId int64 `json:"dispute_id"`
+}
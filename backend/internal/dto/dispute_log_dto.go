@@ -0,0 +1,7 @@
+package dto
+
+type DisputeLogDto struct {
+	LogId   int64  `json:"log_id"`
+	Reason  string `json:"reason"`
+	Exclude bool   `json:"exclude"` // true - отметка не засчитывается, пока спор не решён
+}
@@ -0,0 +1,8 @@
+package dto
+
+type EditLogDto struct {
+	LogId       int64  `json:"log_id" binding:"required"`
+	Message     string `json:"message" binding:"required,min=1"`
+	Photo       string `json:"photo,omitempty"`        // base64, новое фото; пусто - фото не меняется
+	RemovePhoto bool   `json:"remove_photo,omitempty"` // true - убрать фото, если новое не передано
+}
@@ -0,0 +1,5 @@
+package dto
+
+type FriendDto struct {
+	UserId int64 `json:"user_id"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type FriendRequestResultDto struct {
+	Status string `json:"status"` // pending - заявка отправлена, accepted - была встречная заявка, вы уже друзья
+}
@@ -1,7 +1,11 @@
 package dto
 
 type HabitDto struct {
-	Id       int    `json:"id"`
-	Name     string `json:"name"`
-	Category string `json:"category"`
+	Id          int     `json:"id"`
+	Name        string  `json:"name"`
+	Category    string  `json:"category"`
+	Unit        string  `json:"unit"`
+	DailyTarget float64 `json:"daily_target"` // 0 - привычка без числовой цели
+	Streak      int     `json:"streak"`       // Текущий стрик пользователя по привычке
+	BestStreak  int     `json:"best_streak"`  // Лучший стрик пользователя по привычке
 }
@@ -0,0 +1,17 @@
+package dto
+
+import "maxbot/internal/models"
+
+type HeadToHeadDto struct {
+	OpponentId        int64                     `json:"opponent_id"`
+	OpponentFirstName string                    `json:"opponent_first_name"`
+	OpponentPhotoUrl  string                    `json:"opponent_photo_url"`
+	Wins              int                       `json:"wins"`
+	Losses            int                       `json:"losses"`
+	Draws             int                       `json:"draws"`
+	SeriesResult      string                    `json:"series_result"` // Результат последних дуэлей подряд: win, loss или draw
+	SeriesLength      int                       `json:"series_length"` // Сколько последних дуэлей подряд с этим результатом
+	Habits            []HeadToHeadHabitDto      `json:"habits"`
+	RematchChains     []RematchChainDto         `json:"rematch_chains"`
+	Duels             []models.HeadToHeadDuelDb `json:"duels"` // Новые первыми
+}
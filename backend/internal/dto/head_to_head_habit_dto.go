@@ -0,0 +1,9 @@
+package dto
+
+type HeadToHeadHabitDto struct {
+	HabitName     string `json:"habit_name"`
+	HabitCategory string `json:"habit_category"`
+	Wins          int    `json:"wins"`
+	Losses        int    `json:"losses"`
+	Draws         int    `json:"draws"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type HideLogDto struct {
+	LogId  int64 `json:"log_id"`
+	Hidden bool  `json:"hidden"` // false - вернуть лог в ленту
+}
@@ -0,0 +1,5 @@
+package dto
+
+type JoinLobbyDto struct {
+	EntryId int64 `json:"entry_id"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type JoinTeamDto struct {
+	InvitationHash string `json:"invitation_hash"`
+}
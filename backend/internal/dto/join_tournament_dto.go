@@ -0,0 +1,5 @@
+package dto
+
+type JoinTournamentDto struct {
+	InvitationHash string `json:"invitation_hash"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "maxbot/internal/models"
+
+type LeaderboardDto struct {
+	Metric     string                      `json:"metric"`
+	Window     string                      `json:"window"`
+	Scope      string                      `json:"scope"`
+	Entries    []models.LeaderboardEntryDb `json:"entries"`
+	NextCursor string                      `json:"next_cursor"` // Пусто - это последняя страница
+}
@@ -0,0 +1,5 @@
+package dto
+
+type LeaveTeamDto struct {
+	TeamId int64 `json:"team_id"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+type LobbyPostResultDto struct {
+	Matched bool  `json:"matched"` // true - соперник найден сразу, дуэль уже началась
+	DuelId  int64 `json:"duel_id"`
+	EntryId int64 `json:"entry_id"` // Заявка в лобби, если соперник не найден
+}
@@ -1,11 +1,22 @@
 package dto
 
+import "maxbot/internal/models"
+
 type LogDto struct {
-	LogID   int64  `json:"log_id"`
-	OwnerID int64  `json:"owner_id"`
-	MaxID string `json:"max_id"`
-	DuelID  int64  `json:"duel_id"`
-	CreatedAt string `json:"created_at"`
-	Message string `json:"message"`
-	Photo   []byte `json:"photo,omitempty"`
+	LogID          int64              `json:"log_id"`
+	OwnerID        int64              `json:"owner_id"`
+	MaxID          string             `json:"max_id"`
+	OwnerFirstName string             `json:"owner_first_name"`
+	TeamId         *int64             `json:"team_id"` // Команда автора в командной дуэли
+	TeamName       *string            `json:"team_name"`
+	DuelID         int64              `json:"duel_id"`
+	CreatedAt      string             `json:"created_at"`
+	Message        string             `json:"message"`
+	Photo          []byte             `json:"photo,omitempty"`
+	Value          *float64           `json:"value"`
+	Counted        bool               `json:"counted"`
+	Disputes       []models.DisputeDb `json:"disputes"` // История споров по отметке
+	Reactions      []ReactionCountDto `json:"reactions"`
+	MyReaction     string             `json:"my_reaction"` // Реакция смотрящего, пусто - нет
+	CommentsCount  int                `json:"comments_count"`
 }
@@ -0,0 +1,6 @@
+package dto
+
+type PostToLobbyDto struct {
+	HabitId int `json:"habit_id"`
+	Days    int `json:"days"`
+}
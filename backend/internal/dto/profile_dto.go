@@ -0,0 +1,16 @@
+package dto
+
+import "maxbot/internal/models"
+
+type ProfileDto struct {
+	UserId       int64            `json:"user_id"`
+	FirstName    string           `json:"first_name"`
+	PhotoUrl     string           `json:"photo_url"`
+	IsFriend     bool             `json:"is_friend"`
+	Hidden       bool             `json:"hidden"` // true - профиль виден только друзьям, остальные поля пустые
+	Streak       int              `json:"streak"`
+	Wins         int              `json:"wins"`
+	Rating       int              `json:"rating"`
+	Achievements []AchievementDto `json:"achievements"`
+	ActiveDuels  []models.DuelDb  `json:"active_duels"`
+}
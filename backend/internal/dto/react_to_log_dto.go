@@ -0,0 +1,6 @@
+package dto
+
+type ReactToLogDto struct {
+	LogId int64  `json:"log_id"`
+	Emoji string `json:"emoji"` // 👍 🔥 💪 👏 😂 😮, пусто - убрать реакцию
+}
@@ -0,0 +1,6 @@
+package dto
+
+type ReactionCountDto struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
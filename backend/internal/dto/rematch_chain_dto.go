@@ -0,0 +1,8 @@
+package dto
+
+type RematchChainDto struct {
+	DuelIds []int64 `json:"duel_ids"` // Дуэль и её реванши по порядку
+	Wins    int     `json:"wins"`
+	Losses  int     `json:"losses"`
+	Draws   int     `json:"draws"`
+}
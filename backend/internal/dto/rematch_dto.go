@@ -0,0 +1,5 @@
+package dto
+
+type RematchDto struct {
+	DuelId int64 `json:"duel_id"` // Закончившаяся дуэль
+}
@@ -0,0 +1,5 @@
+package dto
+
+type ReportCreatedDto struct {
+	ReportId int64 `json:"report_id"`
+}
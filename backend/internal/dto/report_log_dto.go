@@ -0,0 +1,7 @@
+package dto
+
+type ReportLogDto struct {
+	LogId   int64  `json:"log_id"`
+	Reason  string `json:"reason"`  // spam, abuse, inappropriate, fake_proof или other
+	Details string `json:"details"` // Необязательный комментарий, до 500 символов
+}
@@ -0,0 +1,7 @@
+package dto
+
+type ReportUserDto struct {
+	UserId  int64  `json:"user_id"`
+	Reason  string `json:"reason"`  // spam, abuse, inappropriate, fake_proof или other
+	Details string `json:"details"` // Необязательный комментарий, до 500 символов
+}
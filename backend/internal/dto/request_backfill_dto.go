@@ -0,0 +1,9 @@
+package dto
+
+type RequestBackfillDto struct {
+	DuelID  int64    `json:"duel_id" binding:"required"`
+	Date    string   `json:"date" binding:"required"` // YYYY-MM-DD, прошедший день дуэли
+	Message string   `json:"message" binding:"required,min=1"`
+	Photo   string   `json:"photo,omitempty"` // base64, optional
+	Value   *float64 `json:"value,omitempty"` // required for habits with a daily target
+}
@@ -0,0 +1,6 @@
+package dto
+
+type ResolveBackfillDto struct {
+	BackfillId int64 `json:"backfill_id"`
+	Approved   bool  `json:"approved"` // true - отметка засчитывается, false - запрос отклонён
+}
@@ -0,0 +1,6 @@
+package dto
+
+type ResolveDisputeDto struct {
+	DisputeId int64 `json:"dispute_id"`
+	Upheld    bool  `json:"upheld"` // true - отметка не засчитывается, false - остаётся в силе
+}
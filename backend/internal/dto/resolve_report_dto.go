@@ -0,0 +1,6 @@
+package dto
+
+type ResolveReportDto struct {
+	ReportId int64  `json:"report_id"`
+	Action   string `json:"action"` // hide_log, suspend_user или dismiss
+}
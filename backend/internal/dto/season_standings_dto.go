@@ -0,0 +1,8 @@
+package dto
+
+import "maxbot/internal/models"
+
+type SeasonStandingsDto struct {
+	Season    models.SeasonDb           `json:"season"`
+	Standings []models.SeasonStandingDb `json:"standings"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type SetVisibilityDto struct {
+	Visibility string `json:"visibility"` // everyone или friends
+}
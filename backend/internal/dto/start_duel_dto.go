@@ -0,0 +1,5 @@
+package dto
+
+type StartDuelDto struct {
+	DuelId int64 `json:"duel_id"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type StartTournamentDto struct {
+	TournamentId int64 `json:"tournament_id"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type SuspendUserDto struct {
+	UserId    int64 `json:"user_id"`
+	Suspended bool  `json:"suspended"` // false - снять блокировку
+}
@@ -0,0 +1,9 @@
+package dto
+
+type TeamDto struct {
+	Id             int64           `json:"id"`
+	Name           string          `json:"name"`
+	OwnerId        int64           `json:"owner_id"`
+	InvitationLink string          `json:"invitation_link"`
+	Members        []TeamMemberDto `json:"members"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+type TeamMemberDto struct {
+	UserId    int64  `json:"user_id"`
+	FirstName string `json:"first_name"`
+	PhotoUrl  string `json:"photo_url"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "maxbot/internal/models"
+
+type TournamentDto struct {
+	Id             int64                      `json:"id"`
+	Name           string                     `json:"name"`
+	OrganizerId    int64                      `json:"organizer_id"`
+	HabitId        int                        `json:"habit_id"`
+	HabitName      string                     `json:"habit_name"`
+	Days           int                        `json:"days"`
+	ScoringMode    string                     `json:"scoring_mode"`
+	Format         string                     `json:"format"`
+	Status         string                     `json:"status"`
+	InvitationLink string                     `json:"invitation_link"`
+	WinnerId       *int64                     `json:"winner_id"`
+	Players        []TournamentPlayerDto      `json:"players"`
+	Matches        []models.TournamentMatchDb `json:"matches"`
+}
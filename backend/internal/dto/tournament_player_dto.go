@@ -0,0 +1,10 @@
+package dto
+
+type TournamentPlayerDto struct {
+	UserId     int64   `json:"user_id"`
+	FirstName  string  `json:"first_name"`
+	PhotoUrl   string  `json:"photo_url"`
+	Seed       int     `json:"seed"`
+	Points     float64 `json:"points"` // Круговой турнир: 1 за победу, 0.5 за ничью
+	Eliminated bool    `json:"eliminated"`
+}
@@ -3,11 +3,24 @@ package dto
 import "maxbot/internal/models"
 
 type UserDto struct {
-	Streak              int             `json:"streak"`  // Стрик из привычек
-	Wins                int             `json:"wins"`    // Победы
-	Winrate             float32         `json:"winrate"` // Винрейт сразу в процентах
-	FirstName           string          `json:"first_name"`
-	PhotoUrl            string          `json:"photo_url"`
-	LastTimeContributed string          `json:"last_time_contributed"`
-	DuelsInfo           []models.DuelDb `json:"duels_info"` // Дуэльки в которых участвует юзер
+	Streak              int                       `json:"streak"`             // Стрик из привычек
+	Wins                int                       `json:"wins"`               // Победы
+	Winrate             float32                   `json:"winrate"`            // Винрейт сразу в процентах
+	Season              *models.SeasonDb          `json:"season"`             // Текущий сезон, null - сезон не идёт
+	SeasonWins          int                       `json:"season_wins"`        // Победы в текущем сезоне
+	SeasonDuels         int                       `json:"season_duels"`       // Завершённые дуэли в текущем сезоне
+	SeasonCompletions   int                       `json:"season_completions"` // Засчитанные отметки в текущем сезоне
+	SeasonRank          int                       `json:"season_rank"`        // Место в сезоне, 0 - ещё не играл
+	FirstName           string                    `json:"first_name"`
+	PhotoUrl            string                    `json:"photo_url"`
+	LastTimeContributed string                    `json:"last_time_contributed"`
+	StreakFreezes       int                       `json:"streak_freezes"`   // Доступные заморозки стрика
+	FrozenDays          []string                  `json:"frozen_days"`      // Дни, пропуск которых был покрыт заморозкой
+	Visibility          string                    `json:"visibility"`       // Кто видит профиль и отметки: everyone или friends
+	Coins               int64                     `json:"coins"`            // Баланс монет без замороженных ставок
+	Rating              int                       `json:"rating"`           // Общий рейтинг Эло
+	CategoryRatings     []models.CategoryRatingDb `json:"category_ratings"` // Рейтинг по категориям привычек
+	RatingHistory       []models.RatingHistoryDb  `json:"rating_history"`   // Последние изменения рейтинга
+	Achievements        []AchievementDto          `json:"achievements"`     // Полученные бейджи
+	DuelsInfo           []models.DuelDb           `json:"duels_info"`       // Дуэльки в которых участвует юзер
 }
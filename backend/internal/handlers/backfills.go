@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestBackfill godoc
+// @Summary      Ask the opponent to count a check-in for a missed past day of the duel. The check-in counts once an opponent approves it
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param request_backfill_dto body dto.RequestBackfillDto true "Request Backfill Dto"
+// @Success      200  {object}  dto.BackfillCreatedDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/requestBackfill [post]
+func (h *HttpHandler) RequestBackfill(c *gin.Context) {
+	user := c.MustGet("currentUser").(*models.UserDb)
+	var backfillDto dto.RequestBackfillDto
+	if err := c.ShouldBindJSON(&backfillDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	message := strings.TrimSpace(backfillDto.Message)
+	if message == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while processing message",
+			Details: "message cannot be empty or whitespace only",
+		})
+		return
+	}
+	photoBytes, ok := decodePhoto(c, backfillDto.Photo)
+	if !ok {
+		return
+	}
+
+	backfillId, err := h.Service.RequestBackfill(user, backfillDto.DuelID, backfillDto.Date, message, photoBytes, backfillDto.Value)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while requesting backfill",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.BackfillCreatedDto{BackfillId: backfillId})
+}
+
+// ResolveBackfill godoc
+// @Summary      Approve or reject a backfill request of your opponent. An approved check-in counts like one made on that day and may end the duel
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param resolve_backfill_dto body dto.ResolveBackfillDto true "Resolve Backfill Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/resolveBackfill [post]
+func (h *HttpHandler) ResolveBackfill(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var resolveDto dto.ResolveBackfillDto
+	if err := c.BindJSON(&resolveDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.ResolveBackfill(userId, resolveDto.BackfillId, resolveDto.Approved); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while resolving backfill",
+			Details: err.Error(),
+		})
+		return
+	}
+	message := "backfill rejected"
+	if resolveDto.Approved {
+		message = "backfill approved"
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: message})
+}
+
+// GetBackfills godoc
+// @Summary      Get backfill requests of a duel you take part in, pending and resolved
+// @Accept       json
+// @Produce      json
+// @Param        duel_id   query      int  true  "Duel ID"
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Success      200  {object}  []models.BackfillDb
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/getBackfills [get]
+func (h *HttpHandler) GetBackfills(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	duelId, err := strconv.ParseInt(c.Query("duel_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while parsing duel_id",
+			Details: "invalid 'duel_id': must be an integer",
+		})
+		return
+	}
+	backfills, err := h.Service.GetBackfills(userId, duelId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while getting backfills",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, backfills)
+}
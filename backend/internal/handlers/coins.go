@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCoinHistory godoc
+// @Summary      Get the latest coin ledger entries of the user, the newest first
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param        limit   query      int  false  "How many entries to return, 50 by default"
+// @Success      200  {object}  []models.CoinEntryDb
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /coins/getHistory [get]
+func (h *HttpHandler) GetCoinHistory(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorDto{
+				Error:   "error while parsing limit",
+				Details: "invalid 'limit': must be an integer",
+			})
+			return
+		}
+	}
+	entries, err := h.Service.GetCoinHistory(userId, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while getting coin history",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// AuditCoins godoc
+// @Summary      Check the coin ledger: every operation balanced, every balance equal to its entries. Admins only
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Success      200  {object}  dto.CoinAuditDto
+// @Failure      403  {object} dto.ErrorDto
+// @Failure      500  {object} dto.ErrorDto
+// @Router       /coins/audit [get]
+func (h *HttpHandler) AuditCoins(c *gin.Context) {
+	audit, err := h.Service.AuditCoins()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorDto{
+			Error:   "error while auditing coins",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, audit)
+}
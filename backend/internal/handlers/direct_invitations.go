@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Rematch godoc
+// @Summary      Offer a rematch of an ended 1v1 duel: same habit and rules, sent directly to the former opponent
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param rematch_dto body dto.RematchDto true "Rematch Dto"
+// @Success      200  {object}  models.DuelDb
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/rematch [post]
+func (h *HttpHandler) Rematch(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var rematchDto dto.RematchDto
+	if err := c.BindJSON(&rematchDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	duel, err := h.Service.Rematch(userId, rematchDto.DuelId)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while creating rematch",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, duel)
+}
+
+// ChallengeUser godoc
+// @Summary      Challenge a user found by opponent_id or opponent_max_id to a 1v1 duel. The user gets a bot message and finds it in /duel/getDirectInvitations
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param challenge_dto body dto.ChallengeDto true "Challenge Dto"
+// @Success      200  {object}  models.DuelDb
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/challenge [post]
+func (h *HttpHandler) ChallengeUser(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var challengeDto dto.ChallengeDto
+	if err := c.BindJSON(&challengeDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	var schedule models.Schedule
+	if challengeDto.Schedule != nil {
+		schedule = *challengeDto.Schedule
+	}
+	var proofRules models.ProofRules
+	if challengeDto.ProofRules != nil {
+		proofRules = *challengeDto.ProofRules
+	}
+	duel, err := h.Service.ChallengeUser(userId, challengeDto.OpponentId, challengeDto.OpponentMaxId, challengeDto.HabitId, models.DuelSettings{
+		Days:        challengeDto.Days,
+		Schedule:    schedule,
+		ScoringMode: challengeDto.ScoringMode,
+		Stake:       challengeDto.Stake,
+		ProofRules:  proofRules,
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while challenging user",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, duel)
+}
+
+// GetDirectInvitations godoc
+// @Summary      Get duels the user was invited to directly and has not answered yet
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Success      200  {object}  []models.DuelDb
+// @Failure      500  {object} dto.ErrorDto
+// @Router       /duel/getDirectInvitations [get]
+func (h *HttpHandler) GetDirectInvitations(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	duels, err := h.Service.GetDirectInvitations(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorDto{
+			Error:   "error while getting invitations",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, duels)
+}
+
+// AcceptDirectInvitation godoc
+// @Summary      Accept a duel you were invited to directly. The duel starts today
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param direct_invitation_dto body dto.DirectInvitationDto true "Direct Invitation Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/acceptDirectInvitation [post]
+func (h *HttpHandler) AcceptDirectInvitation(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var invitationDto dto.DirectInvitationDto
+	if err := c.BindJSON(&invitationDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.AcceptDirectInvitation(userId, invitationDto.DuelId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while accepting invitation",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "invitation accepted"})
+}
+
+// DeclineDirectInvitation godoc
+// @Summary      Decline a duel you were invited to directly. The duel is closed as declined and stakes are returned
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param direct_invitation_dto body dto.DirectInvitationDto true "Direct Invitation Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/declineDirectInvitation [post]
+func (h *HttpHandler) DeclineDirectInvitation(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var invitationDto dto.DirectInvitationDto
+	if err := c.BindJSON(&invitationDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.DeclineDirectInvitation(userId, invitationDto.DuelId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while declining invitation",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "invitation declined"})
+}
+
+// CancelInvitation godoc
+// @Summary      Cancel a duel you created that nobody has accepted yet, by link or directly. The duel is closed as cancelled and stakes are returned
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param direct_invitation_dto body dto.DirectInvitationDto true "Direct Invitation Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/cancelInvitation [post]
+func (h *HttpHandler) CancelInvitation(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var invitationDto dto.DirectInvitationDto
+	if err := c.BindJSON(&invitationDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.CancelInvitation(userId, invitationDto.DuelId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while cancelling invitation",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "invitation cancelled"})
+}
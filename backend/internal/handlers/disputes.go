@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DisputeLog godoc
+// @Summary      Dispute an opponent's log on the day it was logged or the day after, also within a day after the duel ends. With exclude the log does not count until the dispute is resolved (active duels only)
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param dispute_log_dto body dto.DisputeLogDto true "Dispute Log Dto"
+// @Success      200  {object}  dto.DisputeDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/disputeLog [post]
+func (h *HttpHandler) DisputeLog(c *gin.Context) {
+	user := c.MustGet("currentUser").(*models.UserDb)
+	var disputeDto dto.DisputeLogDto
+	if err := c.BindJSON(&disputeDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	disputeId, err := h.Service.DisputeLog(user, disputeDto.LogId, disputeDto.Reason, disputeDto.Exclude)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while disputing log",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.DisputeDto{DisputeId: disputeId})
+}
+
+// ConcedeDispute godoc
+// @Summary      Agree with a dispute on your log: the log stops counting
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param dispute_dto body dto.DisputeDto true "Dispute Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/concedeDispute [post]
+func (h *HttpHandler) ConcedeDispute(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var disputeDto dto.DisputeDto
+	if err := c.BindJSON(&disputeDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.ConcedeDispute(userId, disputeDto.DisputeId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while conceding dispute",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "dispute conceded"})
+}
+
+// WithdrawDispute godoc
+// @Summary      Withdraw your dispute: the log counts again
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param dispute_dto body dto.DisputeDto true "Dispute Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/withdrawDispute [post]
+func (h *HttpHandler) WithdrawDispute(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var disputeDto dto.DisputeDto
+	if err := c.BindJSON(&disputeDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.WithdrawDispute(userId, disputeDto.DisputeId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while withdrawing dispute",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "dispute withdrawn"})
+}
+
+// GetOpenDisputes godoc
+// @Summary      Get disputes waiting for a resolution, the oldest first. Admins only
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Success      200  {object}  []models.DisputeDb
+// @Failure      500  {object} dto.ErrorDto
+// @Router       /admin/disputes [get]
+func (h *HttpHandler) GetOpenDisputes(c *gin.Context) {
+	disputes, err := h.Service.GetOpenDisputes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorDto{
+			Error:   "error while getting disputes",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, disputes)
+}
+
+// AdminResolveDispute godoc
+// @Summary      Resolve a dispute: upheld - the log does not count, otherwise it counts. An ended duel the log decided is reopened and decided again. Admins only
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param resolve_dispute_dto body dto.ResolveDisputeDto true "Resolve Dispute Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /admin/resolveDispute [post]
+func (h *HttpHandler) AdminResolveDispute(c *gin.Context) {
+	adminId := c.MustGet("currentUser").(*models.UserDb).ID
+	var resolveDto dto.ResolveDisputeDto
+	if err := c.BindJSON(&resolveDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.AdminResolveDispute(adminId, resolveDto.DisputeId, resolveDto.Upheld); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while resolving dispute",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "dispute resolved"})
+}
@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindFriendDto parses dto.FriendDto from the body, answering 400 on failure.
+func bindFriendDto(c *gin.Context) (int64, bool) {
+	var friendDto dto.FriendDto
+	if err := c.BindJSON(&friendDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return 0, false
+	}
+	return friendDto.UserId, true
+}
+
+// SendFriendRequest godoc
+// @Summary      Send a friend request. If the user has already sent you one, you become friends at once
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param friend_dto body dto.FriendDto true "Friend Dto"
+// @Success      200  {object}  dto.FriendRequestResultDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /friends/request [post]
+func (h *HttpHandler) SendFriendRequest(c *gin.Context) {
+	user := c.MustGet("currentUser").(*models.UserDb)
+	otherId, ok := bindFriendDto(c)
+	if !ok {
+		return
+	}
+	status, err := h.Service.SendFriendRequest(user, otherId)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while sending friend request",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.FriendRequestResultDto{Status: status})
+}
+
+// AcceptFriendRequest godoc
+// @Summary      Accept a friend request sent by the user
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param friend_dto body dto.FriendDto true "Friend Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /friends/accept [post]
+func (h *HttpHandler) AcceptFriendRequest(c *gin.Context) {
+	user := c.MustGet("currentUser").(*models.UserDb)
+	requesterId, ok := bindFriendDto(c)
+	if !ok {
+		return
+	}
+	if err := h.Service.AcceptFriendRequest(user, requesterId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while accepting friend request",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "friend request accepted"})
+}
+
+// RemoveFriend godoc
+// @Summary      Remove a friend, or cancel or decline a pending friend request
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param friend_dto body dto.FriendDto true "Friend Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /friends/remove [post]
+func (h *HttpHandler) RemoveFriend(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	otherId, ok := bindFriendDto(c)
+	if !ok {
+		return
+	}
+	if err := h.Service.RemoveFriend(userId, otherId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while removing friend",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "friend removed"})
+}
+
+// GetFriends godoc
+// @Summary      Get friends of the user with their active duels
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Success      200  {object}  []models.FriendDb
+// @Failure      500  {object} dto.ErrorDto
+// @Router       /friends/list [get]
+func (h *HttpHandler) GetFriends(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	friends, err := h.Service.GetFriends(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorDto{
+			Error:   "error while getting friends",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, friends)
+}
+
+// GetFriendRequests godoc
+// @Summary      Get pending friend requests sent to (incoming) and by the user
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Success      200  {object}  []models.FriendRequestDb
+// @Failure      500  {object} dto.ErrorDto
+// @Router       /friends/requests [get]
+func (h *HttpHandler) GetFriendRequests(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	requests, err := h.Service.GetFriendRequests(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorDto{
+			Error:   "error while getting friend requests",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetFriendSuggestions godoc
+// @Summary      Get past opponents who are not friends yet, the most frequent opponents first
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Success      200  {object}  []models.FriendSuggestionDb
+// @Failure      500  {object} dto.ErrorDto
+// @Router       /friends/suggestions [get]
+func (h *HttpHandler) GetFriendSuggestions(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	suggestions, err := h.Service.GetFriendSuggestions(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorDto{
+			Error:   "error while getting friend suggestions",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// BlockUser godoc
+// @Summary      Block a user. Ends the friendship, hides profiles and logs from each other and forbids friend requests, invitations and joining each other's duels
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param friend_dto body dto.FriendDto true "Friend Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /friends/block [post]
+func (h *HttpHandler) BlockUser(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	otherId, ok := bindFriendDto(c)
+	if !ok {
+		return
+	}
+	if err := h.Service.BlockUser(userId, otherId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while blocking user",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "user blocked"})
+}
+
+// UnblockUser godoc
+// @Summary      Unblock a user
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param friend_dto body dto.FriendDto true "Friend Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /friends/unblock [post]
+func (h *HttpHandler) UnblockUser(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	otherId, ok := bindFriendDto(c)
+	if !ok {
+		return
+	}
+	if err := h.Service.UnblockUser(userId, otherId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while unblocking user",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "user unblocked"})
+}
+
+// GetBlockedUsers godoc
+// @Summary      Get users blocked by the user
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Success      200  {object}  []models.BlockedUserDb
+// @Failure      500  {object} dto.ErrorDto
+// @Router       /friends/blocked [get]
+func (h *HttpHandler) GetBlockedUsers(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	blocked, err := h.Service.GetBlockedUsers(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorDto{
+			Error:   "error while getting blocked users",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, blocked)
+}
+
+// SetVisibility godoc
+// @Summary      Choose who sees your profile and your logs in duels you are not sharing: everyone or friends
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param set_visibility_dto body dto.SetVisibilityDto true "Set Visibility Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /user/setVisibility [post]
+func (h *HttpHandler) SetVisibility(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var visibilityDto dto.SetVisibilityDto
+	if err := c.BindJSON(&visibilityDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.SetVisibility(userId, visibilityDto.Visibility); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while setting visibility",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "visibility updated"})
+}
+
+// GetProfile godoc
+// @Summary      Get the profile of a user. A profile hidden from the viewer has only the name and the photo
+// @Accept       json
+// @Produce      json
+// @Param        user_id   query      int  true  "User ID"
+// @Param        max_id   query      string  false  "Max ID of the viewer"
+// @Success      200  {object}  dto.ProfileDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /user/getProfile [get]
+func (h *HttpHandler) GetProfile(c *gin.Context) {
+	userId, err := strconv.ParseInt(c.Query("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while parsing user_id",
+			Details: "invalid 'user_id': must be an integer",
+		})
+		return
+	}
+	var viewerId int64
+	if viewer, ok := c.Get("currentUser"); ok {
+		viewerId = viewer.(*models.UserDb).ID
+	}
+	profile, err := h.Service.GetUserProfile(viewerId, userId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while getting profile",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
@@ -26,9 +26,66 @@ type HandlerInterface interface {
 	ContributeToDuel(c *gin.Context)
 	CreateNewDuel(c *gin.Context)
 	AcceptInvitation(c *gin.Context)
+	StartDuel(c *gin.Context)
+	ForfeitDuel(c *gin.Context)
 	CreateNewHabit(c *gin.Context)
 	GetUserHabits(c *gin.Context)
 	MakeTestData(c *gin.Context)
+	CreateTeam(c *gin.Context)
+	JoinTeam(c *gin.Context)
+	LeaveTeam(c *gin.Context)
+	GetUserTeams(c *gin.Context)
+	CreateTournament(c *gin.Context)
+	JoinTournament(c *gin.Context)
+	StartTournament(c *gin.Context)
+	GetTournamentBracket(c *gin.Context)
+	PostToLobby(c *gin.Context)
+	GetLobby(c *gin.Context)
+	JoinLobbyEntry(c *gin.Context)
+	CancelLobbyEntry(c *gin.Context)
+	GetLeaderboard(c *gin.Context)
+	CreateSeason(c *gin.Context)
+	GetSeasons(c *gin.Context)
+	GetSeasonStandings(c *gin.Context)
+	GetCoinHistory(c *gin.Context)
+	AuditCoins(c *gin.Context)
+	Rematch(c *gin.Context)
+	GetDirectInvitations(c *gin.Context)
+	AcceptDirectInvitation(c *gin.Context)
+	DeclineDirectInvitation(c *gin.Context)
+	CancelInvitation(c *gin.Context)
+	GetHeadToHead(c *gin.Context)
+	ChallengeUser(c *gin.Context)
+	SendFriendRequest(c *gin.Context)
+	AcceptFriendRequest(c *gin.Context)
+	RemoveFriend(c *gin.Context)
+	GetFriends(c *gin.Context)
+	GetFriendRequests(c *gin.Context)
+	GetFriendSuggestions(c *gin.Context)
+	BlockUser(c *gin.Context)
+	UnblockUser(c *gin.Context)
+	GetBlockedUsers(c *gin.Context)
+	SetVisibility(c *gin.Context)
+	GetProfile(c *gin.Context)
+	ReportLog(c *gin.Context)
+	ReportUser(c *gin.Context)
+	GetReports(c *gin.Context)
+	ResolveReport(c *gin.Context)
+	SuspendUser(c *gin.Context)
+	HideLog(c *gin.Context)
+	DisputeLog(c *gin.Context)
+	ConcedeDispute(c *gin.Context)
+	WithdrawDispute(c *gin.Context)
+	GetOpenDisputes(c *gin.Context)
+	AdminResolveDispute(c *gin.Context)
+	ReactToLog(c *gin.Context)
+	CommentOnLog(c *gin.Context)
+	GetLogComments(c *gin.Context)
+	EditLog(c *gin.Context)
+	DeleteLog(c *gin.Context)
+	RequestBackfill(c *gin.Context)
+	ResolveBackfill(c *gin.Context)
+	GetBackfills(c *gin.Context)
 }
 
 type HttpHandler struct {
@@ -44,13 +101,70 @@ func (h *HttpHandler) New() http.Handler {
 	router.GET("/healthy", h.Healthy)
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	router.GET("/user/getUserInfo", middleware.UserExistsOrNot(*h.Service.Repository), h.GetUserInfo)
-	router.GET("/duel/getDuelLogs", h.GetDuelLogs)
-	router.POST("/duel/contribute", middleware.UserExistsOrNot(*h.Service.Repository), h.ContributeToDuel)
-	router.POST("/duel/createNew", middleware.UserExistsOrNot(*h.Service.Repository), h.CreateNewDuel)
-	router.POST("/duel/acceptInvitation", middleware.UserExistsOrNot(*h.Service.Repository), h.AcceptInvitation)
-	router.POST("/habit/createNew", middleware.UserExistsOrNot(*h.Service.Repository), h.CreateNewHabit)
-	router.GET("/habit/getUserHabits", middleware.UserExistsOrNot(*h.Service.Repository), h.GetUserHabits)
+	router.GET("/user/getUserInfo", middleware.UserExistsOrNot(h.Service.Repository), h.GetUserInfo)
+	router.GET("/user/getHeadToHead", middleware.UserExistsOrNot(h.Service.Repository), h.GetHeadToHead)
+	router.GET("/user/getProfile", middleware.OptionalUser(h.Service.Repository), h.GetProfile)
+	router.POST("/user/setVisibility", middleware.UserExistsOrNot(h.Service.Repository), h.SetVisibility)
+	router.POST("/friends/request", middleware.UserExistsOrNot(h.Service.Repository), h.SendFriendRequest)
+	router.POST("/friends/accept", middleware.UserExistsOrNot(h.Service.Repository), h.AcceptFriendRequest)
+	router.POST("/friends/remove", middleware.UserExistsOrNot(h.Service.Repository), h.RemoveFriend)
+	router.POST("/friends/block", middleware.UserExistsOrNot(h.Service.Repository), h.BlockUser)
+	router.POST("/friends/unblock", middleware.UserExistsOrNot(h.Service.Repository), h.UnblockUser)
+	router.GET("/friends/list", middleware.UserExistsOrNot(h.Service.Repository), h.GetFriends)
+	router.GET("/friends/requests", middleware.UserExistsOrNot(h.Service.Repository), h.GetFriendRequests)
+	router.GET("/friends/suggestions", middleware.UserExistsOrNot(h.Service.Repository), h.GetFriendSuggestions)
+	router.GET("/friends/blocked", middleware.UserExistsOrNot(h.Service.Repository), h.GetBlockedUsers)
+	router.GET("/duel/getDuelLogs", middleware.OptionalUser(h.Service.Repository), h.GetDuelLogs)
+	router.POST("/duel/contribute", middleware.UserExistsOrNot(h.Service.Repository), h.ContributeToDuel)
+	router.POST("/duel/createNew", middleware.UserExistsOrNot(h.Service.Repository), h.CreateNewDuel)
+	router.POST("/duel/acceptInvitation", middleware.UserExistsOrNot(h.Service.Repository), h.AcceptInvitation)
+	router.POST("/duel/start", middleware.UserExistsOrNot(h.Service.Repository), h.StartDuel)
+	router.POST("/duel/forfeit", middleware.UserExistsOrNot(h.Service.Repository), h.ForfeitDuel)
+	router.POST("/duel/rematch", middleware.UserExistsOrNot(h.Service.Repository), h.Rematch)
+	router.POST("/duel/challenge", middleware.UserExistsOrNot(h.Service.Repository), h.ChallengeUser)
+	router.GET("/duel/getDirectInvitations", middleware.UserExistsOrNot(h.Service.Repository), h.GetDirectInvitations)
+	router.POST("/duel/acceptDirectInvitation", middleware.UserExistsOrNot(h.Service.Repository), h.AcceptDirectInvitation)
+	router.POST("/duel/declineDirectInvitation", middleware.UserExistsOrNot(h.Service.Repository), h.DeclineDirectInvitation)
+	router.POST("/duel/cancelInvitation", middleware.UserExistsOrNot(h.Service.Repository), h.CancelInvitation)
+	router.POST("/duel/disputeLog", middleware.UserExistsOrNot(h.Service.Repository), h.DisputeLog)
+	router.POST("/duel/concedeDispute", middleware.UserExistsOrNot(h.Service.Repository), h.ConcedeDispute)
+	router.POST("/duel/withdrawDispute", middleware.UserExistsOrNot(h.Service.Repository), h.WithdrawDispute)
+	router.POST("/duel/reactToLog", middleware.UserExistsOrNot(h.Service.Repository), h.ReactToLog)
+	router.POST("/duel/commentOnLog", middleware.UserExistsOrNot(h.Service.Repository), h.CommentOnLog)
+	router.GET("/duel/getLogComments", middleware.OptionalUser(h.Service.Repository), h.GetLogComments)
+	router.POST("/duel/editLog", middleware.UserExistsOrNot(h.Service.Repository), h.EditLog)
+	router.POST("/duel/deleteLog", middleware.UserExistsOrNot(h.Service.Repository), h.DeleteLog)
+	router.POST("/duel/requestBackfill", middleware.UserExistsOrNot(h.Service.Repository), h.RequestBackfill)
+	router.POST("/duel/resolveBackfill", middleware.UserExistsOrNot(h.Service.Repository), h.ResolveBackfill)
+	router.GET("/duel/getBackfills", middleware.UserExistsOrNot(h.Service.Repository), h.GetBackfills)
+	router.POST("/habit/createNew", middleware.UserExistsOrNot(h.Service.Repository), h.CreateNewHabit)
+	router.GET("/habit/getUserHabits", middleware.UserExistsOrNot(h.Service.Repository), h.GetUserHabits)
+	router.POST("/team/createNew", middleware.UserExistsOrNot(h.Service.Repository), h.CreateTeam)
+	router.POST("/team/join", middleware.UserExistsOrNot(h.Service.Repository), h.JoinTeam)
+	router.POST("/team/leave", middleware.UserExistsOrNot(h.Service.Repository), h.LeaveTeam)
+	router.GET("/team/getUserTeams", middleware.UserExistsOrNot(h.Service.Repository), h.GetUserTeams)
+	router.POST("/tournament/createNew", middleware.UserExistsOrNot(h.Service.Repository), h.CreateTournament)
+	router.POST("/tournament/join", middleware.UserExistsOrNot(h.Service.Repository), h.JoinTournament)
+	router.POST("/tournament/start", middleware.UserExistsOrNot(h.Service.Repository), h.StartTournament)
+	router.GET("/tournament/getBracket", h.GetTournamentBracket)
+	router.POST("/lobby/post", middleware.UserExistsOrNot(h.Service.Repository), h.PostToLobby)
+	router.GET("/lobby/list", middleware.UserExistsOrNot(h.Service.Repository), h.GetLobby)
+	router.POST("/lobby/join", middleware.UserExistsOrNot(h.Service.Repository), h.JoinLobbyEntry)
+	router.POST("/lobby/cancel", middleware.UserExistsOrNot(h.Service.Repository), h.CancelLobbyEntry)
+	router.GET("/leaderboard/get", middleware.UserExistsOrNot(h.Service.Repository), h.GetLeaderboard)
+	router.POST("/season/createNew", middleware.UserExistsOrNot(h.Service.Repository), middleware.AdminOnly(), h.CreateSeason)
+	router.GET("/season/list", h.GetSeasons)
+	router.GET("/season/getStandings", h.GetSeasonStandings)
+	router.GET("/coins/getHistory", middleware.UserExistsOrNot(h.Service.Repository), h.GetCoinHistory)
+	router.GET("/coins/audit", middleware.UserExistsOrNot(h.Service.Repository), middleware.AdminOnly(), h.AuditCoins)
+	router.POST("/report/log", middleware.UserExistsOrNot(h.Service.Repository), h.ReportLog)
+	router.POST("/report/user", middleware.UserExistsOrNot(h.Service.Repository), h.ReportUser)
+	router.GET("/admin/reports", middleware.UserExistsOrNot(h.Service.Repository), middleware.AdminOnly(), h.GetReports)
+	router.POST("/admin/resolveReport", middleware.UserExistsOrNot(h.Service.Repository), middleware.AdminOnly(), h.ResolveReport)
+	router.POST("/admin/suspendUser", middleware.UserExistsOrNot(h.Service.Repository), middleware.AdminOnly(), h.SuspendUser)
+	router.POST("/admin/hideLog", middleware.UserExistsOrNot(h.Service.Repository), middleware.AdminOnly(), h.HideLog)
+	router.GET("/admin/disputes", middleware.UserExistsOrNot(h.Service.Repository), middleware.AdminOnly(), h.GetOpenDisputes)
+	router.POST("/admin/resolveDispute", middleware.UserExistsOrNot(h.Service.Repository), middleware.AdminOnly(), h.AdminResolveDispute)
 	router.POST("/test/makeTestData", h.MakeTestData)
 
 	return router.Handler()
@@ -62,6 +176,9 @@ func (h *HttpHandler) Healthy(c *gin.Context) {
 	})
 }
 
+// ratingHistoryLimit is how many latest rating changes the profile shows.
+const ratingHistoryLimit = 30
+
 // GetUserInfo godoc
 // @Summary      Get user information, including duels he is participating in
 // @Accept       json
@@ -84,6 +201,68 @@ func (h *HttpHandler) GetUserInfo(c *gin.Context) {
 		return
 	}
 
+	frozenDays, err := h.Service.Repository.FindFrozenDaysByUserId(user.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "Invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	categoryRatings, err := h.Service.Repository.FindCategoryRatingsByUserId(user.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "Invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+	ratingHistory, err := h.Service.Repository.FindRatingHistoryByUserId(user.ID, ratingHistoryLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "Invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	coins, err := h.Service.GetCoinBalance(user.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "Invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	visibility, err := h.Service.GetVisibility(user.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "Invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	achievements, err := h.Service.GetUserAchievements(user.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "Invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	season, seasonStanding, err := h.Service.GetUserSeasonStanding(user.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "Invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+
 	var endedDuelsCounter = 0
 	for _, duel := range duels {
 		if duel.Status == "ended" {
@@ -98,21 +277,38 @@ func (h *HttpHandler) GetUserInfo(c *gin.Context) {
 	resp := dto.UserDto{
 		Streak:              user.Streak,
 		Wins:                user.Wins,
+		Season:              season,
 		Winrate:             winrate,
 		FirstName:           user.FirstName,
 		PhotoUrl:            user.PhotoUrl,
 		LastTimeContributed: user.LastTimeContributed.String,
+		StreakFreezes:       user.StreakFreezes,
+		Visibility:          visibility,
+		Coins:               coins,
+		FrozenDays:          frozenDays,
+		Rating:              user.Rating,
+		CategoryRatings:     categoryRatings,
+		RatingHistory:       ratingHistory,
+		Achievements:        achievements,
 		DuelsInfo:           duels,
 	}
 
+	if seasonStanding != nil {
+		resp.SeasonWins = seasonStanding.Wins
+		resp.SeasonDuels = seasonStanding.DuelsPlayed
+		resp.SeasonCompletions = seasonStanding.Completions
+		resp.SeasonRank = seasonStanding.Rank
+	}
+
 	c.JSON(http.StatusOK, resp)
 }
 
 // GetDuelLogs godoc
-// @Summary      Get logs of a duel
+// @Summary      Get logs of a duel. Non-participants only see logs of users whose profile is visible to them
 // @Accept       json
 // @Produce      json
 // @Param        duel_id   query      string  true  "duel_id"
+// @Param        max_id   query      string  false  "Max ID of the viewer"
 // @Success      200  {object}  []dto.LogDto
 // @Failure      400  {object} dto.ErrorDto
 // @Router       /duel/getDuelLogs [get]
@@ -128,7 +324,12 @@ func (h *HttpHandler) GetDuelLogs(c *gin.Context) {
 		return
 	}
 
-	logs, err := h.Service.GetDuelLogs(duel_id)
+	var viewerId int64
+	if user, ok := c.Get("currentUser"); ok {
+		viewerId = user.(*models.UserDb).ID
+	}
+
+	logs, err := h.Service.GetDuelLogs(viewerId, duel_id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorDto{
 			Error: "error while getting logs",
@@ -141,7 +342,7 @@ func (h *HttpHandler) GetDuelLogs(c *gin.Context) {
 }
 
 // ContributeToDuel godoc
-// @Summary      Contribute to duel, sending your message and photo
+// @Summary      Contribute to duel, sending your message and photo. The log must meet the proof rules of the duel
 // @Accept       json
 // @Produce      json
 // @Param        max_id   query      string  true  "Max ID"
@@ -192,9 +393,10 @@ func (h *HttpHandler) ContributeToDuel(c *gin.Context) {
 		}
 	}
 
-	err := h.Service.CreateDuelLog(user, user.ID, req.DuelID, msg, photoBytes)
+	err := h.Service.CreateDuelLog(user, user.ID, req.DuelID, msg, photoBytes, req.Value)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorDto{
+		// Чаще всего это нарушение правил дуэли: расписание, требования к отметке
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
 				Error: "Failed to save log",
 				Details: err.Error(),
 			})
@@ -205,7 +407,7 @@ func (h *HttpHandler) ContributeToDuel(c *gin.Context) {
 }
 
 // CreateNewDuel godoc
-// @Summary      Create new duel
+// @Summary      Create new duel. proof_rules can require a photo, a minimum message length and a time of day for logs
 // @Accept       json
 // @Produce      json
 // @Param        max_id   query      string  true  "Max ID"
@@ -225,9 +427,24 @@ func (h *HttpHandler) CreateNewDuel(c *gin.Context) {
 		})
 		return
 	}
-	invitationLink, err := h.Service.CreateDuelAndGetHash(
-		userId, createNewDuelDto.HabitId, createNewDuelDto.Days,
-	)
+	var schedule models.Schedule
+	if createNewDuelDto.Schedule != nil {
+		schedule = *createNewDuelDto.Schedule
+	}
+	var proofRules models.ProofRules
+	if createNewDuelDto.ProofRules != nil {
+		proofRules = *createNewDuelDto.ProofRules
+	}
+	invitationLink, err := h.Service.CreateDuelAndGetHash(userId, createNewDuelDto.HabitId, models.DuelSettings{
+		Days:            createNewDuelDto.Days,
+		Schedule:        schedule,
+		ScoringMode:     createNewDuelDto.ScoringMode,
+		MaxParticipants: createNewDuelDto.MaxParticipants,
+		TeamId:          createNewDuelDto.TeamId,
+		TeamScoring:     createNewDuelDto.TeamScoring,
+		Stake:           createNewDuelDto.Stake,
+		ProofRules:      proofRules,
+	})
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
 			Error: "error while creating duel",
@@ -265,6 +482,8 @@ func (h *HttpHandler) CreateNewHabit(c *gin.Context) {
 		userId,
 		createNewHabitDto.HabitName,
 		createNewHabitDto.HabitCategory,
+		createNewHabitDto.Unit,
+		createNewHabitDto.DailyTarget,
 	)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
@@ -300,7 +519,7 @@ func (h *HttpHandler) GetUserHabits(c *gin.Context) {
 }
 
 // AcceptInvitation godoc
-// @Summary      Accept invitation to duel using invitation hash
+// @Summary      Accept invitation to duel using invitation hash. Team duels are accepted on behalf of team_id
 // @Accept       json
 // @Produce      json
 // @Param        max_id   query      string  true  "Max ID"
@@ -320,7 +539,13 @@ func (h *HttpHandler) AcceptInvitation(c *gin.Context) {
 		})
 		return
 	}
-	if err := h.Service.AcceptInvitation(userId, acceptInvitationDto.InvitationHash); err != nil {
+	var err error
+	if acceptInvitationDto.TeamId != 0 {
+		err = h.Service.AcceptTeamInvitation(userId, acceptInvitationDto.TeamId, acceptInvitationDto.InvitationHash)
+	} else {
+		err = h.Service.AcceptInvitation(userId, acceptInvitationDto.InvitationHash)
+	}
+	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
 			Error: "error while accepting invitation",
 			Details: err.Error(),
@@ -330,6 +555,68 @@ func (h *HttpHandler) AcceptInvitation(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.MessageDto{Message: "successfully accepted invitation!"})
 }
 
+// StartDuel godoc
+// @Summary      Start a group duel before all places are taken. Only the creator can do it
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param start_duel_dto body dto.StartDuelDto true "Start Duel Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/start [post]
+func (h *HttpHandler) StartDuel(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var startDuelDto dto.StartDuelDto
+	if err := c.BindJSON(&startDuelDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.StartDuel(userId, startDuelDto.DuelId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while starting duel",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "duel started!"})
+}
+
+// ForfeitDuel godoc
+// @Summary      Give up an active 1v1 duel. The opponent wins and both ratings are updated
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param forfeit_duel_dto body dto.ForfeitDuelDto true "Forfeit Duel Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/forfeit [post]
+func (h *HttpHandler) ForfeitDuel(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var forfeitDuelDto dto.ForfeitDuelDto
+	if err := c.BindJSON(&forfeitDuelDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.ForfeitDuel(userId, forfeitDuelDto.DuelId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while forfeiting duel",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "duel forfeited"})
+}
+
 // MakeTestData godoc
 // @Summary      Make test data. Creates users witd max id's {MAXID_1, MAXID_2, MAXID_3, MAXID_4}
 // @Accept       json
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetHeadToHead godoc
+// @Summary      Get the record of the user against another player: shared ended duels, totals, current series, habits and rematch chains
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param        opponent_id   query      int  true  "Opponent ID"
+// @Success      200  {object}  dto.HeadToHeadDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /user/getHeadToHead [get]
+func (h *HttpHandler) GetHeadToHead(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	opponentId, err := strconv.ParseInt(c.Query("opponent_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while parsing opponent_id",
+			Details: "invalid 'opponent_id': must be an integer",
+		})
+		return
+	}
+	headToHead, err := h.Service.GetHeadToHead(userId, opponentId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while getting head-to-head",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, headToHead)
+}
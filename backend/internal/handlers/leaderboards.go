@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLeaderboard godoc
+// @Summary      Get a page of the leaderboard. The friends scope is you and your friends
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param        metric   query      string  true  "wins, streak, rating or completions"
+// @Param        window   query      string  false  "week, month or all (default)"
+// @Param        scope   query      string  false  "global (default) or friends"
+// @Param        cursor   query      string  false  "next_cursor of the previous page"
+// @Param        limit   query      int  false  "Page size, 20 by default"
+// @Success      200  {object}  dto.LeaderboardDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /leaderboard/get [get]
+func (h *HttpHandler) GetLeaderboard(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorDto{
+				Error:   "error while parsing limit",
+				Details: "invalid 'limit': must be an integer",
+			})
+			return
+		}
+	}
+	leaderboard, err := h.Service.GetLeaderboard(
+		userId, c.Query("metric"), c.Query("window"), c.Query("scope"), c.Query("cursor"), limit,
+	)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while getting leaderboard",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, leaderboard)
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PostToLobby godoc
+// @Summary      Look for an opponent in the open lobby. If a player with the same habit category, duration and a close rating is waiting, the duel starts at once, otherwise a lobby entry is published
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param post_to_lobby_dto body dto.PostToLobbyDto true "Post To Lobby Dto"
+// @Success      200  {object}  dto.LobbyPostResultDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /lobby/post [post]
+func (h *HttpHandler) PostToLobby(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var postToLobbyDto dto.PostToLobbyDto
+	if err := c.BindJSON(&postToLobbyDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	result, err := h.Service.PostToLobby(userId, postToLobbyDto.HabitId, postToLobbyDto.Days)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while posting to lobby",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetLobby godoc
+// @Summary      Get open lobby entries, the closest ratings first
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param        habit_category   query      string  false  "Habit category"
+// @Param        days   query      int  false  "Duel duration"
+// @Success      200  {object}  []models.LobbyEntryDb
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /lobby/list [get]
+func (h *HttpHandler) GetLobby(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	days := 0
+	if daysStr := c.Query("days"); daysStr != "" {
+		var err error
+		if days, err = strconv.Atoi(daysStr); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorDto{
+				Error:   "error while parsing days",
+				Details: "invalid 'days': must be an integer",
+			})
+			return
+		}
+	}
+	entries, err := h.Service.GetLobby(userId, c.Query("habit_category"), days)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while getting lobby",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// JoinLobbyEntry godoc
+// @Summary      Take a lobby entry: the duel is joined and started
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param join_lobby_dto body dto.JoinLobbyDto true "Join Lobby Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /lobby/join [post]
+func (h *HttpHandler) JoinLobbyEntry(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var joinLobbyDto dto.JoinLobbyDto
+	if err := c.BindJSON(&joinLobbyDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.JoinLobbyEntry(userId, joinLobbyDto.EntryId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while joining lobby entry",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "duel started!"})
+}
+
+// CancelLobbyEntry godoc
+// @Summary      Remove your lobby entry and cancel its duel, returning the stake
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param cancel_lobby_dto body dto.CancelLobbyDto true "Cancel Lobby Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /lobby/cancel [post]
+func (h *HttpHandler) CancelLobbyEntry(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var cancelLobbyDto dto.CancelLobbyDto
+	if err := c.BindJSON(&cancelLobbyDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.CancelLobbyEntry(userId, cancelLobbyDto.EntryId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while cancelling lobby entry",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "lobby entry cancelled"})
+}
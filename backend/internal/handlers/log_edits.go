@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// decodePhoto decodes an optional base64 photo; on failure the request is aborted.
+func decodePhoto(c *gin.Context, photo string) ([]byte, bool) {
+	if photo == "" {
+		return nil, true
+	}
+	photoBytes, err := base64.StdEncoding.DecodeString(photo)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while processing photo",
+			Details: "invalid base64 in 'photo'",
+		})
+		return nil, false
+	}
+	if len(photoBytes) > 5*1024*1024 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while processing photo",
+			Details: "photo too large (max 5MB)",
+		})
+		return nil, false
+	}
+	return photoBytes, true
+}
+
+// EditLog godoc
+// @Summary      Change the message or the photo of your log shortly after sending it (15 minutes by default). The log must still meet the proof rules of the duel
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param edit_log_dto body dto.EditLogDto true "Edit Log Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/editLog [post]
+func (h *HttpHandler) EditLog(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var editDto dto.EditLogDto
+	if err := c.ShouldBindJSON(&editDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	message := strings.TrimSpace(editDto.Message)
+	if message == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while processing message",
+			Details: "message cannot be empty or whitespace only",
+		})
+		return
+	}
+
+	photoBytes, ok := decodePhoto(c, editDto.Photo)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.EditDuelLog(userId, editDto.LogId, message, photoBytes, editDto.RemovePhoto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while editing log",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "log updated"})
+}
+
+// DeleteLog godoc
+// @Summary      Delete your log shortly after sending it (15 minutes by default). The counted day, the check-in reward and the streak update are rolled back; a duel the log decided is decided again
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param delete_log_dto body dto.DeleteLogDto true "Delete Log Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/deleteLog [post]
+func (h *HttpHandler) DeleteLog(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var deleteDto dto.DeleteLogDto
+	if err := c.ShouldBindJSON(&deleteDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.DeleteDuelLog(userId, deleteDto.LogId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while deleting log",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "log deleted"})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReactToLog godoc
+// @Summary      React to a log you can see with one of 👍 🔥 💪 👏 😂 😮. An empty emoji removes your reaction
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param react_to_log_dto body dto.ReactToLogDto true "React To Log Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/reactToLog [post]
+func (h *HttpHandler) ReactToLog(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var reactDto dto.ReactToLogDto
+	if err := c.BindJSON(&reactDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.ReactToLog(userId, reactDto.LogId, reactDto.Emoji); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while reacting to log",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "reaction saved"})
+}
+
+// CommentOnLog godoc
+// @Summary      Comment on a log you can see or reply to a comment on it. The author of the log and of the comment get a bot message
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param comment_on_log_dto body dto.CommentOnLogDto true "Comment On Log Dto"
+// @Success      200  {object}  dto.CommentCreatedDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/commentOnLog [post]
+func (h *HttpHandler) CommentOnLog(c *gin.Context) {
+	user := c.MustGet("currentUser").(*models.UserDb)
+	var commentDto dto.CommentOnLogDto
+	if err := c.BindJSON(&commentDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	commentId, err := h.Service.CommentOnLog(user, commentDto.LogId, commentDto.ParentId, commentDto.Message)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while commenting on log",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.CommentCreatedDto{CommentId: commentId})
+}
+
+// GetLogComments godoc
+// @Summary      Get comments on a log in the order they were written. Replies have parent_id
+// @Accept       json
+// @Produce      json
+// @Param        log_id   query      int  true  "Log ID"
+// @Param        max_id   query      string  false  "Max ID of the viewer"
+// @Success      200  {object}  []models.LogCommentDb
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /duel/getLogComments [get]
+func (h *HttpHandler) GetLogComments(c *gin.Context) {
+	logId, err := strconv.ParseInt(c.Query("log_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while parsing log_id",
+			Details: "invalid 'log_id': must be an integer",
+		})
+		return
+	}
+	var viewerId int64
+	if viewer, ok := c.Get("currentUser"); ok {
+		viewerId = viewer.(*models.UserDb).ID
+	}
+	comments, err := h.Service.GetLogComments(viewerId, logId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while getting comments",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
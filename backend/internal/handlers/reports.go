@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportLog godoc
+// @Summary      Report a log to the admins. Reason is one of: spam, abuse, inappropriate, fake_proof, other
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param report_log_dto body dto.ReportLogDto true "Report Log Dto"
+// @Success      200  {object}  dto.ReportCreatedDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /report/log [post]
+func (h *HttpHandler) ReportLog(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var reportDto dto.ReportLogDto
+	if err := c.BindJSON(&reportDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	reportId, err := h.Service.ReportLog(userId, reportDto.LogId, reportDto.Reason, reportDto.Details)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while reporting log",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ReportCreatedDto{ReportId: reportId})
+}
+
+// ReportUser godoc
+// @Summary      Report a user to the admins. Reason is one of: spam, abuse, inappropriate, fake_proof, other. To stop seeing the user use /friends/block
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param report_user_dto body dto.ReportUserDto true "Report User Dto"
+// @Success      200  {object}  dto.ReportCreatedDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /report/user [post]
+func (h *HttpHandler) ReportUser(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var reportDto dto.ReportUserDto
+	if err := c.BindJSON(&reportDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	reportId, err := h.Service.ReportUser(userId, reportDto.UserId, reportDto.Reason, reportDto.Details)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while reporting user",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ReportCreatedDto{ReportId: reportId})
+}
+
+// GetReports godoc
+// @Summary      Get the review queue of reports, the oldest first. Admins only
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param        status   query      string  false  "open (default) or resolved"
+// @Success      200  {object}  []models.ReportDb
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /admin/reports [get]
+func (h *HttpHandler) GetReports(c *gin.Context) {
+	reports, err := h.Service.GetReports(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while getting reports",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// ResolveReport godoc
+// @Summary      Resolve a report: hide_log hides the reported log, suspend_user suspends the reported user, dismiss does nothing. Admins only
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param resolve_report_dto body dto.ResolveReportDto true "Resolve Report Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /admin/resolveReport [post]
+func (h *HttpHandler) ResolveReport(c *gin.Context) {
+	adminId := c.MustGet("currentUser").(*models.UserDb).ID
+	var resolveDto dto.ResolveReportDto
+	if err := c.BindJSON(&resolveDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.ResolveReport(adminId, resolveDto.ReportId, resolveDto.Action); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while resolving report",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "report resolved"})
+}
+
+// SuspendUser godoc
+// @Summary      Suspend an account or lift the suspension. A suspended user gets 403 on every authenticated request. Admins only
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param suspend_user_dto body dto.SuspendUserDto true "Suspend User Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /admin/suspendUser [post]
+func (h *HttpHandler) SuspendUser(c *gin.Context) {
+	adminId := c.MustGet("currentUser").(*models.UserDb).ID
+	var suspendDto dto.SuspendUserDto
+	if err := c.BindJSON(&suspendDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.SetUserSuspended(adminId, suspendDto.UserId, suspendDto.Suspended); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while suspending user",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "user updated"})
+}
+
+// HideLog godoc
+// @Summary      Hide a log from every timeline or show it again. A hidden log still counts in the duel. Admins only
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param hide_log_dto body dto.HideLogDto true "Hide Log Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /admin/hideLog [post]
+func (h *HttpHandler) HideLog(c *gin.Context) {
+	var hideDto dto.HideLogDto
+	if err := c.BindJSON(&hideDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.SetLogHidden(hideDto.LogId, hideDto.Hidden); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while hiding log",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "log updated"})
+}
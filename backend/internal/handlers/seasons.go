@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateSeason godoc
+// @Summary      Create a season. Admins only (ADMIN_MAX_IDS), seasons must not overlap
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param create_season_dto body dto.CreateSeasonDto true "Create Season Dto"
+// @Success      200  {object}  models.SeasonDb
+// @Failure      400  {object} dto.ErrorDto
+// @Failure      403  {object} dto.ErrorDto
+// @Router       /season/createNew [post]
+func (h *HttpHandler) CreateSeason(c *gin.Context) {
+	var createSeasonDto dto.CreateSeasonDto
+	if err := c.BindJSON(&createSeasonDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	season, err := h.Service.CreateSeason(createSeasonDto.Name, createSeasonDto.StartDate, createSeasonDto.EndDate)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while creating season",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, season)
+}
+
+// GetSeasons godoc
+// @Summary      Get all seasons, the latest first
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  []models.SeasonDb
+// @Failure      500  {object} dto.ErrorDto
+// @Router       /season/list [get]
+func (h *HttpHandler) GetSeasons(c *gin.Context) {
+	seasons, err := h.Service.GetSeasons()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorDto{
+			Error:   "error while getting seasons",
+			Details: err.Error(),
+		})
+		return
+	}
+	if seasons == nil {
+		seasons = []models.SeasonDb{}
+	}
+	c.JSON(http.StatusOK, seasons)
+}
+
+// GetSeasonStandings godoc
+// @Summary      Get season standings: live for a running season, archived with rewards for a closed one
+// @Accept       json
+// @Produce      json
+// @Param        season_id   query      int  false  "Season ID, the current season by default"
+// @Param        limit   query      int  false  "How many places to return, 50 by default"
+// @Success      200  {object}  dto.SeasonStandingsDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /season/getStandings [get]
+func (h *HttpHandler) GetSeasonStandings(c *gin.Context) {
+	var seasonId int64
+	var limit int
+	var err error
+	if seasonIdStr := c.Query("season_id"); seasonIdStr != "" {
+		if seasonId, err = strconv.ParseInt(seasonIdStr, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorDto{
+				Error:   "error while parsing id",
+				Details: "invalid 'season_id': must be an integer",
+			})
+			return
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorDto{
+				Error:   "error while parsing limit",
+				Details: "invalid 'limit': must be an integer",
+			})
+			return
+		}
+	}
+	standings, err := h.Service.GetSeasonStandings(seasonId, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while getting season standings",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, standings)
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateTeam godoc
+// @Summary      Create new team. The creator becomes its owner and first member
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param create_team_dto body dto.CreateTeamDto true "Create Team Dto"
+// @Success      200  {object}  dto.TeamDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /team/createNew [post]
+func (h *HttpHandler) CreateTeam(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var createTeamDto dto.CreateTeamDto
+	if err := c.BindJSON(&createTeamDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	team, err := h.Service.CreateTeam(userId, createTeamDto.Name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while creating team",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
+
+// JoinTeam godoc
+// @Summary      Join a team using the hash from its invitation link
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param join_team_dto body dto.JoinTeamDto true "Join Team Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /team/join [post]
+func (h *HttpHandler) JoinTeam(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var joinTeamDto dto.JoinTeamDto
+	if err := c.BindJSON(&joinTeamDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.JoinTeam(userId, joinTeamDto.InvitationHash); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while joining team",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "successfully joined team!"})
+}
+
+// LeaveTeam godoc
+// @Summary      Leave a team. Check-ins in duels the team already plays still count
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param leave_team_dto body dto.LeaveTeamDto true "Leave Team Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /team/leave [post]
+func (h *HttpHandler) LeaveTeam(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var leaveTeamDto dto.LeaveTeamDto
+	if err := c.BindJSON(&leaveTeamDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.LeaveTeam(userId, leaveTeamDto.TeamId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while leaving team",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "successfully left team!"})
+}
+
+// GetUserTeams godoc
+// @Summary      Get teams the user is a member of
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Success      200  {object}  []dto.TeamDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /team/getUserTeams [get]
+func (h *HttpHandler) GetUserTeams(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	teams, err := h.Service.GetUserTeams(userId)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while getting user teams",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, teams)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateTournament godoc
+// @Summary      Create a tournament for a habit. Players register with the invitation link
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param create_tournament_dto body dto.CreateTournamentDto true "Create Tournament Dto"
+// @Success      200  {object}  dto.TournamentDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /tournament/createNew [post]
+func (h *HttpHandler) CreateTournament(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var createTournamentDto dto.CreateTournamentDto
+	if err := c.BindJSON(&createTournamentDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	tournament, err := h.Service.CreateTournament(userId, createTournamentDto)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while creating tournament",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, tournament)
+}
+
+// JoinTournament godoc
+// @Summary      Register for a tournament using the hash from its invitation link
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param join_tournament_dto body dto.JoinTournamentDto true "Join Tournament Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /tournament/join [post]
+func (h *HttpHandler) JoinTournament(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var joinTournamentDto dto.JoinTournamentDto
+	if err := c.BindJSON(&joinTournamentDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.JoinTournament(userId, joinTournamentDto.InvitationHash); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while joining tournament",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "successfully joined tournament!"})
+}
+
+// StartTournament godoc
+// @Summary      Close the registration, build the bracket and start the first duels. Only the organizer can do it
+// @Accept       json
+// @Produce      json
+// @Param        max_id   query      string  true  "Max ID"
+// @Param        first_name   query      string  true  "First Name"
+// @Param        photo_url   query      string  true  "Photo URL"
+// @Param start_tournament_dto body dto.StartTournamentDto true "Start Tournament Dto"
+// @Success      200  {object}  dto.MessageDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /tournament/start [post]
+func (h *HttpHandler) StartTournament(c *gin.Context) {
+	userId := c.MustGet("currentUser").(*models.UserDb).ID
+	var startTournamentDto dto.StartTournamentDto
+	if err := c.BindJSON(&startTournamentDto); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "failed to parse data",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err := h.Service.StartTournament(userId, startTournamentDto.TournamentId); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while starting tournament",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageDto{Message: "tournament started!"})
+}
+
+// GetTournamentBracket godoc
+// @Summary      Get tournament state: players, standings and the bracket with duels of every match
+// @Accept       json
+// @Produce      json
+// @Param        tournament_id   query      string  true  "tournament_id"
+// @Success      200  {object}  dto.TournamentDto
+// @Failure      400  {object} dto.ErrorDto
+// @Router       /tournament/getBracket [get]
+func (h *HttpHandler) GetTournamentBracket(c *gin.Context) {
+	tournamentId, err := strconv.ParseInt(c.Query("tournament_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while parsing id",
+			Details: "invalid 'tournament_id': must be an integer",
+		})
+		return
+	}
+	tournament, err := h.Service.GetTournament(tournamentId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorDto{
+			Error:   "error while getting tournament",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, tournament)
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"maxbot/internal/models"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminOnly lets through users whose MAX ID is listed in the comma-separated
+// ADMIN_MAX_IDS environment variable. Must run after UserExistsOrNot.
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := c.MustGet("currentUser").(*models.UserDb)
+		for _, adminID := range strings.Split(os.Getenv("ADMIN_MAX_IDS"), ",") {
+			if strings.TrimSpace(adminID) != "" && strings.TrimSpace(adminID) == user.MaxID {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "admin rights required",
+		})
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func UserExistsOrNot(repo repository.Repository) gin.HandlerFunc {
+func UserExistsOrNot(repo repository.RepositoryInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		maxID := c.Query("max_id")
 		if maxID == "" {
@@ -26,6 +26,12 @@ func UserExistsOrNot(repo repository.Repository) gin.HandlerFunc {
 			return
 		}
 		if user != nil {
+			if user.Suspended {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": "account is suspended",
+				})
+				return
+			}
 			c.Set("currentUser", user)
 			c.Next()
 			return
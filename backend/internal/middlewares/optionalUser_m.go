@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"maxbot/internal/repository"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OptionalUser sets currentUser when max_id belongs to an existing user.
+// Unlike UserExistsOrNot it lets anonymous requests through and never creates users.
+func OptionalUser(repo repository.RepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		maxID := c.Query("max_id")
+		if maxID == "" {
+			c.Next()
+			return
+		}
+
+		user, err := repo.FindUserByMaxId(maxID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error":   "internal error",
+				"details": err.Error(),
+			})
+			return
+		}
+		if user != nil {
+			c.Set("currentUser", user)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+// AchievementStatsDb is what the achievement rules are evaluated against.
+type AchievementStatsDb struct {
+	CheckIns       int // Засчитанные отметки
+	Streak         int
+	Wins           int
+	DuelsPlayed    int // Завершённые дуэли
+	PhotoDuels     int // Дуэли, где была хотя бы одна отметка с фото
+	ComebackWins   int // Победы после отставания
+	TournamentWins int
+}
+
+type UserAchievementDb struct {
+	Code      string
+	AwardedAt string
+}
@@ -0,0 +1,27 @@
+package models
+
+import "database/sql"
+
+const (
+	BackfillStatusPending  = "pending"
+	BackfillStatusApproved = "approved"
+	BackfillStatusRejected = "rejected"
+)
+
+// BackfillDb - запрос засчитать отметку за пропущенный прошедший день дуэли.
+// Отметка появляется, только когда запрос одобрит соперник.
+type BackfillDb struct {
+	Id             int64          `json:"id"`
+	DuelId         int64          `json:"duel_id"`
+	OwnerId        int64          `json:"owner_id"`
+	OwnerFirstName string         `json:"owner_first_name"`
+	Day            string         `json:"day"` // День, за который просят засчитать отметку
+	Message        string         `json:"message"`
+	Photo          []byte         `json:"photo,omitempty"`
+	Value          *float64       `json:"value"`
+	Status         string         `json:"status"`
+	ResolvedBy     sql.NullInt64  `json:"resolved_by"`
+	LogId          sql.NullInt64  `json:"log_id"` // Отметка, созданная после одобрения
+	CreatedAt      string         `json:"created_at"`
+	ResolvedAt     sql.NullString `json:"resolved_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "database/sql"
+
+// Монеты хранятся в журнале с двойной записью: каждая операция - это проводки
+// между счетами, сумма проводок операции всегда равна нулю.
+const (
+	CoinAccountUser   = "user"
+	CoinAccountEscrow = "escrow" // Ставки дуэли, пока она не закончилась
+	CoinAccountSystem = "system" // Источник наград, единственный счёт, который может уйти в минус
+)
+
+const CoinRewardsAccount = "rewards"
+
+const (
+	CoinReasonCheckIn  = "check_in"
+	CoinReasonWin      = "win"
+	CoinReasonStake    = "stake"
+	CoinReasonPayout   = "payout"   // Победитель забирает ставки
+	CoinReasonRefund   = "refund"   // Ничья - ставки возвращаются
+	CoinReasonReversal = "reversal" // Отмена операции после удаления отметки
+)
+
+const (
+	CoinsPerCheckIn = 5
+	CoinsPerWin     = 50
+)
+
+type CoinEntryDb struct {
+	TransactionId int64         `json:"transaction_id"`
+	Reason        string        `json:"reason"`
+	DuelId        sql.NullInt64 `json:"duel_id"`
+	Amount        int64         `json:"amount"` // Положительная - зачисление, отрицательная - списание
+	CreatedAt     string        `json:"created_at"`
+}
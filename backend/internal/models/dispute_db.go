@@ -0,0 +1,32 @@
+package models
+
+import "database/sql"
+
+const (
+	DisputeStatusOpen     = "open"
+	DisputeStatusUpheld   = "upheld"   // Отметка не засчитана
+	DisputeStatusRejected = "rejected" // Отметка остаётся в силе
+)
+
+// Кто закрыл спор
+const (
+	DisputeResolutionConceded  = "conceded"  // Автор отметки согласился
+	DisputeResolutionWithdrawn = "withdrawn" // Соперник отозвал спор
+	DisputeResolutionAdmin     = "admin"
+)
+
+type DisputeDb struct {
+	Id                int64          `json:"id"`
+	LogId             int64          `json:"log_id"`
+	DuelId            int64          `json:"duel_id"`
+	OwnerId           int64          `json:"owner_id"` // Автор оспоренной отметки
+	DisputerId        int64          `json:"disputer_id"`
+	DisputerFirstName string         `json:"disputer_first_name"`
+	Reason            string         `json:"reason"`
+	Excluded          bool           `json:"excluded"` // Отметка не засчитывается, пока спор открыт
+	Status            string         `json:"status"`
+	Resolution        sql.NullString `json:"resolution"`
+	ResolvedBy        sql.NullInt64  `json:"resolved_by"`
+	CreatedAt         string         `json:"created_at"`
+	ResolvedAt        sql.NullString `json:"resolved_at"`
+}
@@ -3,21 +3,42 @@ package models
 import "database/sql"
 
 type DuelDb struct {
-	Id              int            `json:"id"`
-	Duration        int            `json:"duration_in_days"`
-	HabitId         int            `json:"habit_id"`
-	HabitName       string         `json:"habit_name"`
-	HabitCategory   string         `json:"habit_category"`
-	User1_id        int64          `json:"user1_id"`
-	User2_id        sql.NullInt64  `json:"user2_id"`
-	User1_completed int64          `json:"user1_completed"`
-	User2_completed int64          `json:"user2_completed"`
-	User1_firstName string         `json:"user1_first_name"`
-	User2_firstName sql.NullString `json:"user2_first_name"`
-	User1_photoUrl  sql.NullString `json:"user1_photo_url"`
-	User2_photoUrl  sql.NullString `json:"user2_photo_url"`
-	StartDate       string         `json:"start_date"`
-	EndDate         sql.NullString `json:"end_date"`
-	WinnerId        sql.NullInt64  `json:"winner_id"`
-	Status          string         `json:"status"`
+	Id               int             `json:"id"`
+	Duration         int             `json:"duration_in_days"`
+	Target           int             `json:"target"` // Сколько отметок по расписанию нужно для победы
+	Schedule         Schedule        `json:"schedule"`
+	HabitId          int             `json:"habit_id"`
+	HabitName        string          `json:"habit_name"`
+	HabitCategory    string          `json:"habit_category"`
+	HabitUnit        string          `json:"habit_unit"`
+	HabitDailyTarget float64         `json:"habit_daily_target"` // 0 - привычка без числовой цели
+	ScoringMode      string          `json:"scoring_mode"`
+	MaxParticipants  int             `json:"max_participants"`
+	DuelType         string          `json:"duel_type"`
+	TeamScoring      string          `json:"team_scoring"`
+	Stake            int64           `json:"stake"`           // Ставка каждого участника в монетах, 0 - без ставки
+	RematchOf        sql.NullInt64   `json:"rematch_of"`      // Дуэль, реваншем которой является эта
+	InvitedUserId    sql.NullInt64   `json:"invited_user_id"` // Кому отправлено приглашение, если оно не по ссылке
+	ProofRules       ProofRules      `json:"proof_rules"`     // Требования к отметкам
+	User1_id         int64           `json:"user1_id"`
+	User2_id         sql.NullInt64   `json:"user2_id"`
+	User1_completed  int64           `json:"user1_completed"`
+	User2_completed  int64           `json:"user2_completed"`
+	User1_volume     float64         `json:"user1_volume"`
+	User2_volume     float64         `json:"user2_volume"`
+	User1_streak     int             `json:"user1_streak"`
+	User2_streak     int             `json:"user2_streak"`
+	User1_bestStreak int             `json:"user1_best_streak"`
+	User2_bestStreak int             `json:"user2_best_streak"`
+	User1_firstName  string          `json:"user1_first_name"`
+	User2_firstName  sql.NullString  `json:"user2_first_name"`
+	User1_photoUrl   sql.NullString  `json:"user1_photo_url"`
+	User2_photoUrl   sql.NullString  `json:"user2_photo_url"`
+	StartDate        string          `json:"start_date"`
+	EndDate          sql.NullString  `json:"end_date"`
+	WinnerId         sql.NullInt64   `json:"winner_id"`
+	WinnerTeamId     sql.NullInt64   `json:"winner_team_id"`
+	Status           string          `json:"status"`
+	Participants     []ParticipantDb `json:"participants"`
+	Teams            []DuelTeamDb    `json:"teams,omitempty"` // Только для командных дуэлей
 }
@@ -0,0 +1,11 @@
+package models
+
+import "database/sql"
+
+// DuelOutcome - итог дуэли, готовый к сохранению.
+// WinnerId - участник, а в командных дуэлях команда; пустой, если единственного победителя нет.
+type DuelOutcome struct {
+	WinnerId sql.NullInt64
+	EndDate  string
+	Places   map[int64]int // Место каждого участника или команды
+}
@@ -0,0 +1,15 @@
+package models
+
+// DuelSettings are the rules chosen by the creator of a duel.
+type DuelSettings struct {
+	Days            int
+	Schedule        Schedule
+	ScoringMode     string
+	MaxParticipants int
+	TeamId          int64 // 0 - individual duel
+	TeamScoring     string
+	Stake           int64 // Монеты, которые каждый участник замораживает до конца дуэли
+	RematchOf       int64 // 0 - не реванш
+	InvitedUserId   int64 // 0 - принять может любой, у кого есть ссылка
+	ProofRules      ProofRules
+}
@@ -0,0 +1,43 @@
+package models
+
+const (
+	FriendshipPending  = "pending"
+	FriendshipAccepted = "accepted"
+)
+
+// Кто видит профиль и логи пользователя. Соперники по дуэли видят логи этой дуэли всегда.
+const (
+	VisibilityEveryone = "everyone"
+	VisibilityFriends  = "friends"
+)
+
+type FriendDb struct {
+	UserId    int64    `json:"user_id"`
+	FirstName string   `json:"first_name"`
+	PhotoUrl  string   `json:"photo_url"`
+	Since     string   `json:"since"`
+	Duels     []DuelDb `json:"active_duels"` // Активные дуэли друга
+}
+
+type FriendRequestDb struct {
+	UserId    int64  `json:"user_id"`
+	FirstName string `json:"first_name"`
+	PhotoUrl  string `json:"photo_url"`
+	Incoming  bool   `json:"incoming"` // true - заявка пришла пользователю, false - отправлена им
+	CreatedAt string `json:"created_at"`
+}
+
+// FriendSuggestionDb is a past opponent who is not a friend yet.
+type FriendSuggestionDb struct {
+	UserId      int64  `json:"user_id"`
+	FirstName   string `json:"first_name"`
+	PhotoUrl    string `json:"photo_url"`
+	SharedDuels int    `json:"shared_duels"`
+}
+
+type BlockedUserDb struct {
+	UserId    int64  `json:"user_id"`
+	FirstName string `json:"first_name"`
+	PhotoUrl  string `json:"photo_url"`
+	CreatedAt string `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "database/sql"
+
+const (
+	HeadToHeadWin  = "win"
+	HeadToHeadLoss = "loss"
+	HeadToHeadDraw = "draw"
+)
+
+// HeadToHeadDuelDb is an ended duel of two players on opposite sides, the
+// result is from the point of view of the first player.
+type HeadToHeadDuelDb struct {
+	DuelId            int64         `json:"duel_id"`
+	HabitName         string        `json:"habit_name"`
+	HabitCategory     string        `json:"habit_category"`
+	StartDate         string        `json:"start_date"`
+	EndDate           string        `json:"end_date"`
+	Result            string        `json:"result"`
+	Completed         int64         `json:"completed"`
+	OpponentCompleted int64         `json:"opponent_completed"`
+	RematchOf         sql.NullInt64 `json:"rematch_of"`
+}
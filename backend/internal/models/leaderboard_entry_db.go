@@ -0,0 +1,9 @@
+package models
+
+type LeaderboardEntryDb struct {
+	Rank      int    `json:"rank"` // Равные значения делят место
+	UserId    int64  `json:"user_id"`
+	FirstName string `json:"first_name"`
+	PhotoUrl  string `json:"photo_url"`
+	Score     int64  `json:"score"`
+}
@@ -0,0 +1,14 @@
+package models
+
+type LobbyEntryDb struct {
+	Id            int64  `json:"id"`
+	UserId        int64  `json:"user_id"`
+	FirstName     string `json:"first_name"`
+	PhotoUrl      string `json:"photo_url"`
+	DuelId        int64  `json:"duel_id"` // Дуэль в статусе invited, которая начнётся при подборе соперника
+	HabitName     string `json:"habit_name"`
+	HabitCategory string `json:"habit_category"`
+	Days          int    `json:"days"`
+	Rating        int    `json:"rating"` // Рейтинг автора на момент публикации
+	CreatedAt     string `json:"created_at"`
+}
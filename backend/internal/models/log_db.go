@@ -1,10 +1,24 @@
 package models
 
+import "database/sql"
+
 type LogDB struct {
-	ID        int64   `db:"id" json:"id"`
-	OwnerID   int64   `db:"owner_id" json:"owner_id"`
-	DuelID    int64   `db:"duel_id" json:"duel_id"`
-	CreatedAt string  `db:"created_at" json:"created_at"`
-	Message   string  `db:"message" json:"message"`
-	Photo     *[]byte `db:"photo" json:"photo"`
+	ID        int64    `db:"id" json:"id"`
+	OwnerID   int64    `db:"owner_id" json:"owner_id"`
+	DuelID    int64    `db:"duel_id" json:"duel_id"`
+	CreatedAt string   `db:"created_at" json:"created_at"`
+	Message   string   `db:"message" json:"message"`
+	Photo     *[]byte  `db:"photo" json:"photo"`
+	Value     *float64 `db:"value" json:"value"`
+	Counted   bool     `db:"counted" json:"counted"` // Лог, с которым день засчитан в дуэли
+	// Время отправки: отметку можно изменить или удалить только в течение короткого окна
+	LoggedAt sql.NullTime `db:"logged_at" json:"-"`
+	// Стрик владельца до этой отметки, если она его обновила; нужен для отката при удалении
+	StreakBefore *StreakSnapshot `db:"-" json:"-"`
+}
+
+type StreakSnapshot struct {
+	Streak              int
+	StreakFreezes       int
+	LastTimeContributed sql.NullString
 }
@@ -0,0 +1,24 @@
+package models
+
+import "database/sql"
+
+// LogReactions is the set of emoji allowed as reactions to logs.
+var LogReactions = []string{"👍", "🔥", "💪", "👏", "😂", "😮"}
+
+type LogReactionDb struct {
+	LogId int64  `json:"log_id"`
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+	Mine  bool   `json:"mine"` // Среди поставивших есть смотрящий
+}
+
+type LogCommentDb struct {
+	Id        int64         `json:"id"`
+	LogId     int64         `json:"log_id"`
+	ParentId  sql.NullInt64 `json:"parent_id"` // Комментарий, на который это ответ
+	UserId    int64         `json:"user_id"`
+	FirstName string        `json:"first_name"`
+	PhotoUrl  string        `json:"photo_url"`
+	Message   string        `json:"message"`
+	CreatedAt string        `json:"created_at"`
+}
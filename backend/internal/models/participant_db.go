@@ -0,0 +1,15 @@
+package models
+
+import "database/sql"
+
+type ParticipantDb struct {
+	UserId     int64          `json:"user_id"`
+	TeamId     sql.NullInt64  `json:"team_id"` // Команда участника в командной дуэли
+	FirstName  string         `json:"first_name"`
+	PhotoUrl   sql.NullString `json:"photo_url"`
+	Completed  int64          `json:"completed"`
+	Volume     float64        `json:"volume"`
+	Streak     int            `json:"streak"`
+	BestStreak int            `json:"best_streak"`
+	Place      sql.NullInt64  `json:"place"` // Место после окончания дуэли
+}
@@ -0,0 +1,11 @@
+package models
+
+// ProofRules are the requirements a log must meet to be accepted in a duel.
+// Zero values mean no requirement.
+type ProofRules struct {
+	PhotoRequired    bool   `json:"photo_required"`
+	MinMessageLength int    `json:"min_message_length"` // Минимальная длина сообщения в символах
+	NotBefore        string `json:"not_before"`         // ЧЧ:ММ - отметки раньше не принимаются
+	NotAfter         string `json:"not_after"`          // ЧЧ:ММ - отметки с этого времени не принимаются, например 07:00 для "подъём до 7"
+	Timezone         string `json:"timezone"`           // Часовой пояс для окна времени, например Europe/Moscow. Пусто - время сервера
+}
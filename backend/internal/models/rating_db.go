@@ -0,0 +1,22 @@
+package models
+
+const (
+	RatingResultWin     = "win"
+	RatingResultDraw    = "draw"
+	RatingResultLoss    = "loss"
+	RatingResultForfeit = "forfeit" // Игрок сдался
+)
+
+type CategoryRatingDb struct {
+	Category string `json:"category"`
+	Rating   int    `json:"rating"`
+}
+
+type RatingHistoryDb struct {
+	DuelId    int64  `json:"duel_id"`
+	Category  string `json:"category"` // Пусто - общий рейтинг
+	OldRating int    `json:"old_rating"`
+	NewRating int    `json:"new_rating"`
+	Result    string `json:"result"`
+	CreatedAt string `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "database/sql"
+
+// Причины жалоб
+const (
+	ReportReasonSpam          = "spam"
+	ReportReasonAbuse         = "abuse"
+	ReportReasonInappropriate = "inappropriate"
+	ReportReasonFakeProof     = "fake_proof"
+	ReportReasonOther         = "other"
+)
+
+const (
+	ReportStatusOpen     = "open"
+	ReportStatusResolved = "resolved"
+)
+
+// Решения администратора по жалобе
+const (
+	ReportActionHideLog     = "hide_log"
+	ReportActionSuspendUser = "suspend_user"
+	ReportActionDismiss     = "dismiss"
+)
+
+type ReportDb struct {
+	Id                int64          `json:"id"`
+	ReporterId        int64          `json:"reporter_id"`
+	ReporterFirstName string         `json:"reporter_first_name"`
+	UserId            int64          `json:"user_id"` // На кого пожаловались: автор лога или сам пользователь
+	UserFirstName     string         `json:"user_first_name"`
+	UserSuspended     bool           `json:"user_suspended"`
+	LogId             sql.NullInt64  `json:"log_id"` // Пусто - жалоба на пользователя
+	LogMessage        sql.NullString `json:"log_message"`
+	LogHidden         bool           `json:"log_hidden"`
+	Reason            string         `json:"reason"`
+	Details           string         `json:"details"`
+	Status            string         `json:"status"`
+	Action            sql.NullString `json:"action"`
+	ResolvedBy        sql.NullInt64  `json:"resolved_by"`
+	CreatedAt         string         `json:"created_at"`
+}
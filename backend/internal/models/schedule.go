@@ -0,0 +1,13 @@
+package models
+
+const (
+	ScheduleDaily    = "daily"    // Отметка каждый день
+	ScheduleWeekdays = "weekdays" // Отметка только в выбранные дни недели
+	ScheduleWeekly   = "weekly"   // N отметок за ISO-неделю в любые дни
+)
+
+type Schedule struct {
+	Type         string `json:"type"`
+	Weekdays     []int  `json:"weekdays,omitempty"`       // ISO дни недели: 1 - понедельник, 7 - воскресенье
+	TimesPerWeek int    `json:"times_per_week,omitempty"` // Для weekly
+}
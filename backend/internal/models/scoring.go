@@ -0,0 +1,8 @@
+package models
+
+const (
+	ScoringFirstToTarget   = "first_to_target"   // Побеждает первый, кто набрал нужное число отметок
+	ScoringHighestInPeriod = "highest_in_period" // Побеждает тот, у кого больше отметок к концу дуэли
+	ScoringVolume          = "volume"            // Побеждает тот, у кого больше суммарный объём к концу дуэли
+	ScoringLastOneStanding = "last_one_standing" // Проигрывает первый, кто пропустил день по расписанию
+)
@@ -0,0 +1,25 @@
+package models
+
+const (
+	SeasonActive = "active"
+	SeasonClosed = "closed" // Итоги сохранены в архив
+)
+
+type SeasonDb struct {
+	Id        int64  `json:"id"`
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"` // Последний день сезона включительно
+	Status    string `json:"status"`
+}
+
+type SeasonStandingDb struct {
+	Rank        int    `json:"rank"`
+	UserId      int64  `json:"user_id"`
+	FirstName   string `json:"first_name"`
+	PhotoUrl    string `json:"photo_url"`
+	Wins        int    `json:"wins"`
+	DuelsPlayed int    `json:"duels_played"`
+	Completions int    `json:"completions"`
+	Reward      string `json:"reward,omitempty"` // Награда по итогам закрытого сезона
+}
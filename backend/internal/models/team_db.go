@@ -0,0 +1,29 @@
+package models
+
+import "database/sql"
+
+const (
+	DuelTypeIndividual = "individual" // Каждый участник играет сам за себя
+	DuelTypeTeam       = "team"       // Команда против команды
+)
+
+const (
+	TeamScoringCombined = "combined" // Счёт команды - сумма отметок участников
+	TeamScoringAverage  = "average"  // Счёт команды - среднее число отметок участников
+)
+
+type TeamDb struct {
+	Id             int64  `json:"id"`
+	Name           string `json:"name"`
+	OwnerId        int64  `json:"owner_id"`
+	InvitationHash string `json:"-"`
+}
+
+type DuelTeamDb struct {
+	TeamId  int64         `json:"team_id"`
+	Name    string        `json:"name"`
+	Members int           `json:"members"`
+	Score   float64       `json:"score"`  // Сумма или среднее число отметок, в зависимости от team_scoring
+	Volume  float64       `json:"volume"` // Сумма или средний объём
+	Place   sql.NullInt64 `json:"place"`
+}
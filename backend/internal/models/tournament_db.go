@@ -0,0 +1,46 @@
+package models
+
+import "database/sql"
+
+const (
+	TournamentSingleElimination = "single_elimination" // Проигравший выбывает, победитель проходит в следующий раунд
+	TournamentRoundRobin        = "round_robin"        // Каждый играет с каждым, побеждает набравший больше очков
+)
+
+const (
+	TournamentRegistration = "registration"
+	TournamentActive       = "active"
+	TournamentEnded        = "ended"
+)
+
+type TournamentDb struct {
+	Id             int64         `json:"id"`
+	Name           string        `json:"name"`
+	OrganizerId    int64         `json:"organizer_id"`
+	HabitId        int           `json:"habit_id"`
+	HabitName      string        `json:"habit_name"`
+	Days           int           `json:"days"`
+	ScoringMode    string        `json:"scoring_mode"`
+	Format         string        `json:"format"`
+	Status         string        `json:"status"`
+	InvitationHash string        `json:"-"`
+	WinnerId       sql.NullInt64 `json:"winner_id"`
+}
+
+type TournamentPlayerDb struct {
+	UserId    int64          `json:"user_id"`
+	FirstName string         `json:"first_name"`
+	PhotoUrl  sql.NullString `json:"photo_url"`
+	Seed      int            `json:"seed"` // Порядок регистрации, меньший посев выигрывает при ничьей
+}
+
+type TournamentMatchDb struct {
+	Id        int64         `json:"id"`
+	Round     int           `json:"round"`
+	Position  int           `json:"position"`
+	Player1Id sql.NullInt64 `json:"player1_id"` // Пусто, пока игрок не прошёл из предыдущего раунда
+	Player2Id sql.NullInt64 `json:"player2_id"`
+	DuelId    sql.NullInt64 `json:"duel_id"`
+	WinnerId  sql.NullInt64 `json:"winner_id"`
+	Finished  bool          `json:"finished"`
+}
@@ -10,6 +10,9 @@ type UserDb struct {
 	Streak              int            `db:"streak" json:"streak"`
 	Wins                int            `db:"wins" json:"wins"`
 	LastTimeContributed sql.NullString `db:"last_time_contributed" json:"last_time_contributed"`
+	StreakFreezes       int            `db:"streak_freezes" json:"streak_freezes"`
+	Rating              int            `db:"rating" json:"rating"`
+	Suspended           bool           `db:"suspended" json:"suspended"` // Заблокирован администратором
 }
 
 type UserResponse struct {
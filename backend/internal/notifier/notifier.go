@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Notifier delivers a text message to a user of the messenger.
+type Notifier interface {
+	Notify(maxID string, text string) error
+}
+
+// Log only writes notifications to the log, it is used when no bot token is configured.
+type Log struct{}
+
+var _ Notifier = Log{}
+
+func (Log) Notify(maxID string, text string) error {
+	slog.Info("notification", "max_id", maxID, "text", text)
+	return nil
+}
+
+const maxBotApiUrl = "https://platform-api.max.ru"
+
+// MaxBot sends messages on behalf of the bot through the MAX Bot API.
+type MaxBot struct {
+	token  string
+	client *http.Client
+}
+
+var _ Notifier = &MaxBot{}
+
+func NewMaxBot(token string) *MaxBot {
+	return &MaxBot{token: token, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (b *MaxBot) Notify(maxID string, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, maxBotApiUrl+"/messages?user_id="+url.QueryEscape(maxID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", b.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bot api responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Package rating implements the Elo skill rating of players.
+package rating
+
+import "math"
+
+const (
+	Initial = 1000 // Рейтинг нового игрока
+	KFactor = 32   // Максимальное изменение рейтинга за одну дуэль
+)
+
+// Expected is the expected score of a player rated a against a player rated b.
+func Expected(a int, b int) float64 {
+	return 1 / (1 + math.Pow(10, float64(b-a)/400))
+}
+
+// Player is a rated duel participant. Players with the same non-zero Side
+// (team) do not play against each other. A smaller Place is a better result.
+type Player struct {
+	ID     int64
+	Side   int64
+	Rating int
+	Place  int
+}
+
+// Update returns the new ratings after a duel. Every pair of players from
+// different sides counts as a game: the better place wins, equal places draw.
+// The change is averaged over the opponents, so a group duel moves a rating
+// no more than a 1v1 duel does.
+func Update(players []Player) map[int64]int {
+	ratings := map[int64]int{}
+	for _, player := range players {
+		var sum float64
+		opponents := 0
+		for _, opponent := range players {
+			if opponent.ID == player.ID || (player.Side != 0 && opponent.Side == player.Side) {
+				continue
+			}
+			actual := 0.5
+			if player.Place < opponent.Place {
+				actual = 1
+			} else if player.Place > opponent.Place {
+				actual = 0
+			}
+			sum += actual - Expected(player.Rating, opponent.Rating)
+			opponents++
+		}
+		ratings[player.ID] = player.Rating
+		if opponents > 0 {
+			ratings[player.ID] += int(math.Round(KFactor * sum / float64(opponents)))
+		}
+	}
+	return ratings
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"maxbot/internal/models"
+)
+
+// FindAchievementStats collects the numbers the achievement rules look at.
+// A comeback win is a won 1v1 duel in which the opponent was at least
+// comebackDeficit counted days ahead at the end of some day.
+func (r *Repository) FindAchievementStats(user_id int64, comebackDeficit int) (*models.AchievementStatsDb, error) {
+	stats := models.AchievementStatsDb{}
+	err := r.Db.QueryRow(
+		`SELECT
+			(SELECT COUNT(*) FROM logs WHERE owner_id = $1 AND counted),
+			u.streak,
+			u.wins,
+			(SELECT COUNT(*) FROM duel_participants p
+				JOIN duels d ON p.duel_id = d.id
+				WHERE p.user_id = $1 AND d.status_id = (SELECT id FROM duel_status WHERE value = 'ended')),
+			(SELECT COUNT(DISTINCT duel_id) FROM logs WHERE owner_id = $1 AND photo IS NOT NULL),
+			(SELECT COUNT(*) FROM duels d
+				WHERE d.winner_id = $1 AND d.duel_type = 'individual'
+				AND (SELECT COUNT(*) FROM duel_participants WHERE duel_id = d.id) = 2
+				AND EXISTS (
+					SELECT 1 FROM logs l WHERE l.duel_id = d.id AND l.counted AND l.owner_id <> $1
+					AND (SELECT COUNT(*) FROM logs o
+						WHERE o.duel_id = d.id AND o.counted AND o.owner_id = l.owner_id AND o.created_at <= l.created_at)
+					- (SELECT COUNT(*) FROM logs m
+						WHERE m.duel_id = d.id AND m.counted AND m.owner_id = $1 AND m.created_at <= l.created_at)
+					>= $2
+				)),
+			(SELECT COUNT(*) FROM tournaments WHERE winner_id = $1)
+		FROM users u WHERE u.id = $1`, user_id, comebackDeficit,
+	).Scan(&stats.CheckIns, &stats.Streak, &stats.Wins, &stats.DuelsPlayed, &stats.PhotoDuels,
+		&stats.ComebackWins, &stats.TournamentWins)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// AwardAchievements stores the badges; a badge already awarded keeps its first date.
+func (r *Repository) AwardAchievements(user_id int64, codes []string) error {
+	for _, code := range codes {
+		_, err := r.Db.Exec(
+			`INSERT INTO user_achievements (user_id, code, awarded_at) VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, code) DO NOTHING`,
+			user_id, code, r.Clock.Today(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) FindAchievementsByUserId(user_id int64) ([]models.UserAchievementDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT code, TO_CHAR(awarded_at, 'YYYY-MM-DD') FROM user_achievements
+		WHERE user_id = $1 ORDER BY awarded_at, id`, user_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var achievements []models.UserAchievementDb = []models.UserAchievementDb{}
+	for rows.Next() {
+		achievement := models.UserAchievementDb{}
+		if err := rows.Scan(&achievement.Code, &achievement.AwardedAt); err != nil {
+			return nil, err
+		}
+		achievements = append(achievements, achievement)
+	}
+	return achievements, nil
+}
+
+func (r *Repository) FindAllUserIds() ([]int64, error) {
+	rows, err := r.Db.Query(`SELECT id FROM users ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
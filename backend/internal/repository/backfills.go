@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const backfillSelectQuery = `
+	SELECT b.id, b.duel_id, b.owner_id, users.first_name, TO_CHAR(b.day, 'YYYY-MM-DD'), b.message, b.photo, b.value,
+	b.status, b.resolved_by, b.log_id, TO_CHAR(b.created_at, 'YYYY-MM-DD'), TO_CHAR(b.resolved_at, 'YYYY-MM-DD')
+	FROM log_backfills b
+	JOIN users ON b.owner_id = users.id
+`
+
+func scanBackfill(row rowScanner) (models.BackfillDb, error) {
+	var backfill models.BackfillDb
+	err := row.Scan(&backfill.Id, &backfill.DuelId, &backfill.OwnerId, &backfill.OwnerFirstName, &backfill.Day,
+		&backfill.Message, &backfill.Photo, &backfill.Value, &backfill.Status, &backfill.ResolvedBy,
+		&backfill.LogId, &backfill.CreatedAt, &backfill.ResolvedAt)
+	return backfill, err
+}
+
+func (r *Repository) CreateBackfill(backfill *models.BackfillDb) (int64, error) {
+	var photo *[]byte
+	if len(backfill.Photo) > 0 {
+		photo = &backfill.Photo
+	}
+	var id int64
+	err := r.Db.QueryRow(
+		`INSERT INTO log_backfills (duel_id, owner_id, day, message, photo, value, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (duel_id, owner_id, day) WHERE status = 'pending' DO NOTHING
+		RETURNING id`,
+		backfill.DuelId, backfill.OwnerId, backfill.Day, backfill.Message, photo, backfill.Value, r.Clock.Today(),
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("a check-in for this day is already waiting for approval")
+	}
+	return id, err
+}
+
+func (r *Repository) FindBackfillById(backfill_id int64) (*models.BackfillDb, error) {
+	backfill, err := scanBackfill(r.Db.QueryRow(backfillSelectQuery+`WHERE b.id = $1`, backfill_id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("backfill request does not exist")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &backfill, nil
+}
+
+func (r *Repository) FindBackfillsByDuelId(duel_id int64) ([]models.BackfillDb, error) {
+	rows, err := r.Db.Query(backfillSelectQuery+`WHERE b.duel_id = $1 ORDER BY b.id`, duel_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var backfills []models.BackfillDb = []models.BackfillDb{}
+	for rows.Next() {
+		backfill, err := scanBackfill(rows)
+		if err != nil {
+			return nil, err
+		}
+		backfills = append(backfills, backfill)
+	}
+	return backfills, nil
+}
+
+// lockPendingBackfill locks the request and checks that it is still waiting for approval.
+func lockPendingBackfill(tx *sqlx.Tx, backfill_id int64) error {
+	var status string
+	err := tx.QueryRow(`SELECT status FROM log_backfills WHERE id = $1 FOR UPDATE`, backfill_id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return errors.New("backfill request does not exist")
+	}
+	if err != nil {
+		return err
+	}
+	if status != models.BackfillStatusPending {
+		return errors.New("backfill request has already been resolved")
+	}
+	return nil
+}
+
+func (r *Repository) RejectBackfill(backfill_id int64, resolved_by int64) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockPendingBackfill(tx, backfill_id); err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`UPDATE log_backfills SET status = $1, resolved_by = $2, resolved_at = $3 WHERE id = $4`,
+		models.BackfillStatusRejected, resolved_by, r.Clock.Today(), backfill_id,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// backfillStreak adds a past day to the owner's streak; the backfilled log is
+// already stored. A day covered by a freeze gets the freeze back; otherwise the
+// streak is recounted as the run of counted and frozen days ending on the last
+// day the user contributed.
+func backfillStreak(tx *sqlx.Tx, owner_id int64, day string) error {
+	var lastContributed sql.NullString
+	err := tx.QueryRow(
+		`SELECT TO_CHAR(last_time_contributed, 'YYYY-MM-DD') FROM users WHERE id = $1 FOR UPDATE`, owner_id,
+	).Scan(&lastContributed)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`DELETE FROM streak_freezes WHERE user_id = $1 AND frozen_date = $2`, owner_id, day)
+	if err != nil {
+		return err
+	}
+	if refunded, err := res.RowsAffected(); err != nil {
+		return err
+	} else if refunded > 0 {
+		_, err = tx.Exec(`UPDATE users SET streak_freezes = streak_freezes + $2 WHERE id = $1`, owner_id, refunded)
+		return err
+	}
+
+	// День уже засчитан в другой дуэли - стрик не меняется
+	var countedLogs int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM logs WHERE owner_id = $1 AND created_at = $2 AND counted`, owner_id, day,
+	).Scan(&countedLogs)
+	if err != nil || countedLogs > 1 {
+		return err
+	}
+
+	lastDay := day
+	if lastContributed.Valid && lastContributed.String > day {
+		lastDay = lastContributed.String
+	}
+	return recountStreak(tx, owner_id, lastDay)
+}
+
+// backfillStreakSnapshot returns the streak of the user before a log is
+// counted on the day, or nil if the day is already counted in another duel.
+func backfillStreakSnapshot(tx *sqlx.Tx, owner_id int64, day string) (*models.StreakSnapshot, error) {
+	var dayCounted bool
+	err := tx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM logs WHERE owner_id = $1 AND created_at = $2 AND counted)`, owner_id, day,
+	).Scan(&dayCounted)
+	if err != nil || dayCounted {
+		return nil, err
+	}
+	return scanStreakSnapshot(tx.QueryRowx(
+		`SELECT streak, streak_freezes, TO_CHAR(last_time_contributed, 'YYYY-MM-DD')
+		FROM users WHERE id = $1 FOR UPDATE`, owner_id,
+	))
+}
+
+// recountStreak sets the streak to the run of counted and frozen days ending on lastDay.
+func recountStreak(tx *sqlx.Tx, owner_id int64, lastDay string) error {
+	// Дни идут от последнего к первому: n-й день серии отстоит от последнего на n-1 день
+	_, err := tx.Exec(
+		`WITH days AS (
+			SELECT created_at AS day FROM logs WHERE owner_id = $1 AND counted AND created_at <= $2
+			UNION
+			SELECT frozen_date FROM streak_freezes WHERE user_id = $1 AND frozen_date <= $2
+		), numbered AS (
+			SELECT $2::date - day AS back, ROW_NUMBER() OVER (ORDER BY day DESC) AS n FROM days
+		)
+		UPDATE users SET
+			streak = (SELECT COUNT(*) FROM numbered WHERE back = n - 1),
+			last_time_contributed = $2
+		WHERE id = $1`,
+		owner_id, lastDay,
+	)
+	return err
+}
+
+// ApproveBackfill turns the request into a log on the requested day and
+// counts it the way a check-in on that day would have been counted: the duel
+// counter, the owner's streak and the check-in reward. dailyTarget is the
+// daily target of numeric habits. Reports whether the day was counted.
+func (r *Repository) ApproveBackfill(backfill_id int64, resolved_by int64, dailyTarget float64) (bool, error) {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if err := lockPendingBackfill(tx, backfill_id); err != nil {
+		return false, err
+	}
+	backfill, err := scanBackfill(tx.QueryRow(backfillSelectQuery+`WHERE b.id = $1`, backfill_id))
+	if err != nil {
+		return false, err
+	}
+
+	var status int
+	err = tx.QueryRow(`SELECT status_id FROM duels WHERE id = $1 FOR UPDATE`, backfill.DuelId).Scan(&status)
+	if err != nil {
+		return false, err
+	}
+	if status != duelStatusActive {
+		return false, errors.New("duel is not active")
+	}
+
+	var dayCounted bool
+	var dayTotal float64
+	err = tx.QueryRow(
+		`SELECT COALESCE(BOOL_OR(counted), false), COALESCE(SUM(value) FILTER (WHERE `+undisputedLog+`), 0)
+		FROM logs WHERE owner_id = $1 AND duel_id = $2 AND created_at = $3`,
+		backfill.OwnerId, backfill.DuelId, backfill.Day,
+	).Scan(&dayCounted, &dayTotal)
+	if err != nil {
+		return false, err
+	}
+	counted := !dayCounted
+	if dailyTarget > 0 {
+		counted = !dayCounted && backfill.Value != nil && dayTotal+*backfill.Value >= dailyTarget
+	} else if dayCounted {
+		return false, errors.New("this day is already counted")
+	}
+
+	var photo *[]byte
+	if len(backfill.Photo) > 0 {
+		photo = &backfill.Photo
+	}
+	// Like a check-in, the first log counted on the day keeps the streak it
+	// changes, so an upheld dispute can roll the streak back
+	var snapshot *models.StreakSnapshot
+	if counted {
+		snapshot, err = backfillStreakSnapshot(tx, backfill.OwnerId, backfill.Day)
+		if err != nil {
+			return false, err
+		}
+	}
+	// Без logged_at отметку нельзя изменить или удалить: её содержимое одобрил соперник
+	var logId int64
+	err = tx.QueryRow(
+		`INSERT INTO logs (owner_id, duel_id, message, photo, created_at, value, counted)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		backfill.OwnerId, backfill.DuelId, backfill.Message, photo, backfill.Day, backfill.Value, counted,
+	).Scan(&logId)
+	if err != nil {
+		return false, err
+	}
+	if err := saveStreakSnapshot(tx, logId, snapshot); err != nil {
+		return false, err
+	}
+	_, err = tx.Exec(
+		`UPDATE log_backfills SET status = $1, resolved_by = $2, resolved_at = $3, log_id = $4 WHERE id = $5`,
+		models.BackfillStatusApproved, resolved_by, r.Clock.Today(), logId, backfill_id,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if counted {
+		_, err = tx.Exec(
+			`UPDATE duel_participants SET completed = completed + 1 WHERE duel_id = $1 AND user_id = $2`,
+			backfill.DuelId, backfill.OwnerId,
+		)
+		if err != nil {
+			return false, err
+		}
+		if err := backfillStreak(tx, backfill.OwnerId, backfill.Day); err != nil {
+			return false, err
+		}
+		if err := r.rewardCoins(tx, backfill.OwnerId, models.CoinsPerCheckIn, models.CoinReasonCheckIn, backfill.DuelId); err != nil {
+			return false, err
+		}
+	}
+	return counted, tx.Commit()
+}
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// coinAccount returns the id of the account, creating it on first use, and locks it.
+func coinAccount(tx *sqlx.Tx, kind string, column string, owner any) (int64, error) {
+	_, err := tx.Exec(
+		`INSERT INTO coin_accounts (kind, `+column+`) VALUES ($1, $2) ON CONFLICT (`+column+`) DO NOTHING`,
+		kind, owner,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	err = tx.QueryRow(`SELECT id FROM coin_accounts WHERE `+column+` = $1 FOR UPDATE`, owner).Scan(&id)
+	return id, err
+}
+
+func userCoinAccount(tx *sqlx.Tx, user_id int64) (int64, error) {
+	return coinAccount(tx, models.CoinAccountUser, "user_id", user_id)
+}
+
+func escrowCoinAccount(tx *sqlx.Tx, duel_id int64) (int64, error) {
+	return coinAccount(tx, models.CoinAccountEscrow, "duel_id", duel_id)
+}
+
+func systemCoinAccount(tx *sqlx.Tx, name string) (int64, error) {
+	return coinAccount(tx, models.CoinAccountSystem, "name", name)
+}
+
+// transferCoins records one operation moving amount from one account to another.
+// Only system accounts may go below zero.
+func (r *Repository) transferCoins(tx *sqlx.Tx, reason string, duel_id sql.NullInt64, from int64, to int64, amount int64) error {
+	if amount <= 0 {
+		return nil
+	}
+	var kind string
+	var balance int64
+	err := tx.QueryRow(`SELECT kind, balance FROM coin_accounts WHERE id = $1 FOR UPDATE`, from).Scan(&kind, &balance)
+	if err != nil {
+		return err
+	}
+	if kind != models.CoinAccountSystem && balance < amount {
+		return errors.New("not enough coins")
+	}
+	return r.postCoins(tx, reason, duel_id, from, to, amount)
+}
+
+// postCoins records the operation without checking the balance. Reversals use
+// it: coins already spent cannot block a rollback, the account goes below zero.
+func (r *Repository) postCoins(tx *sqlx.Tx, reason string, duel_id sql.NullInt64, from int64, to int64, amount int64) error {
+	if amount <= 0 {
+		return nil
+	}
+	var transactionId int64
+	err := tx.QueryRow(
+		`INSERT INTO coin_transactions (reason, duel_id, created_at) VALUES ($1, $2, $3) RETURNING id`,
+		reason, duel_id, r.Clock.Today(),
+	).Scan(&transactionId)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`INSERT INTO coin_entries (transaction_id, account_id, amount) VALUES ($1, $2, $3), ($1, $4, $5)`,
+		transactionId, from, -amount, to, amount,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`UPDATE coin_accounts SET balance = balance + CASE WHEN id = $1 THEN -$3::bigint ELSE $3::bigint END
+		WHERE id IN ($1, $2)`,
+		from, to, amount,
+	)
+	return err
+}
+
+// rewardCoins credits the user from the rewards account.
+func (r *Repository) rewardCoins(tx *sqlx.Tx, user_id int64, amount int64, reason string, duel_id int64) error {
+	rewards, err := systemCoinAccount(tx, models.CoinRewardsAccount)
+	if err != nil {
+		return err
+	}
+	account, err := userCoinAccount(tx, user_id)
+	if err != nil {
+		return err
+	}
+	return r.transferCoins(tx, reason, sql.NullInt64{Int64: duel_id, Valid: true}, rewards, account, amount)
+}
+
+func (r *Repository) RewardCoins(user_id int64, amount int64, reason string, duel_id int64) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.rewardCoins(tx, user_id, amount, reason, duel_id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// escrowStake moves the duel stake from the user to the escrow account of the duel.
+func (r *Repository) escrowStake(tx *sqlx.Tx, duel_id int64, user_id int64) error {
+	var stake int64
+	if err := tx.QueryRow(`SELECT stake FROM duels WHERE id = $1`, duel_id).Scan(&stake); err != nil {
+		return err
+	}
+	if stake == 0 {
+		return nil
+	}
+	account, err := userCoinAccount(tx, user_id)
+	if err != nil {
+		return err
+	}
+	escrow, err := escrowCoinAccount(tx, duel_id)
+	if err != nil {
+		return err
+	}
+	return r.transferCoins(tx, models.CoinReasonStake, sql.NullInt64{Int64: duel_id, Valid: true}, account, escrow, stake)
+}
+
+// settleDuelCoins rewards the winners of an ended duel and empties its escrow:
+// a single winner takes all the stakes, otherwise every stake goes back.
+func (r *Repository) settleDuelCoins(tx *sqlx.Tx, duel_id int64, winners []int64) error {
+	for _, winner := range winners {
+		if err := r.rewardCoins(tx, winner, models.CoinsPerWin, models.CoinReasonWin, duel_id); err != nil {
+			return err
+		}
+	}
+	if len(winners) != 1 {
+		return r.refundStakes(tx, duel_id)
+	}
+
+	escrow, pot, err := findEscrow(tx, duel_id)
+	if err != nil || pot == 0 {
+		return err
+	}
+	account, err := userCoinAccount(tx, winners[0])
+	if err != nil {
+		return err
+	}
+	return r.transferCoins(tx, models.CoinReasonPayout, sql.NullInt64{Int64: duel_id, Valid: true}, escrow, account, pot)
+}
+
+// findEscrow locks the escrow account of the duel; pot is 0 when nobody has staked.
+func findEscrow(tx *sqlx.Tx, duel_id int64) (int64, int64, error) {
+	var escrow, pot int64
+	err := tx.QueryRow(
+		`SELECT id, balance FROM coin_accounts WHERE duel_id = $1 FOR UPDATE`, duel_id,
+	).Scan(&escrow, &pot)
+	if err == sql.ErrNoRows {
+		return 0, 0, nil
+	}
+	return escrow, pot, err
+}
+
+// refundStakes returns every stake of the duel to the account it came from.
+func (r *Repository) refundStakes(tx *sqlx.Tx, duel_id int64) error {
+	escrow, pot, err := findEscrow(tx, duel_id)
+	if err != nil || pot == 0 {
+		return err
+	}
+
+	rows, err := tx.Query(
+		`SELECT e.account_id, -SUM(e.amount) FROM coin_entries e
+		JOIN coin_transactions t ON e.transaction_id = t.id
+		WHERE t.duel_id = $1 AND t.reason = $2 AND e.account_id <> $3
+		GROUP BY e.account_id ORDER BY e.account_id`,
+		duel_id, models.CoinReasonStake, escrow,
+	)
+	if err != nil {
+		return err
+	}
+	stakes := map[int64]int64{}
+	var accounts []int64
+	for rows.Next() {
+		var account, amount int64
+		if err := rows.Scan(&account, &amount); err != nil {
+			rows.Close()
+			return err
+		}
+		accounts = append(accounts, account)
+		stakes[account] = amount
+	}
+	rows.Close()
+
+	duel := sql.NullInt64{Int64: duel_id, Valid: true}
+	for _, account := range accounts {
+		if err := r.transferCoins(tx, models.CoinReasonRefund, duel, escrow, account, stakes[account]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reverseDuelSettlement undoes the coins moved when the duel ended: the win
+// rewards, the payout and the refunds go back to where they came from.
+func (r *Repository) reverseDuelSettlement(tx *sqlx.Tx, duel_id int64) error {
+	type settlement struct {
+		transactionId int64
+		from          int64
+		to            int64
+		amount        int64
+	}
+	rows, err := tx.Query(
+		`SELECT t.id, debit.account_id, credit.account_id, credit.amount FROM coin_transactions t
+		JOIN coin_entries debit ON debit.transaction_id = t.id AND debit.amount < 0
+		JOIN coin_entries credit ON credit.transaction_id = t.id AND credit.amount > 0
+		WHERE t.duel_id = $1 AND t.reason IN ($2, $3, $4) AND NOT t.reversed
+		ORDER BY t.id`,
+		duel_id, models.CoinReasonWin, models.CoinReasonPayout, models.CoinReasonRefund,
+	)
+	if err != nil {
+		return err
+	}
+	var settlements []settlement
+	for rows.Next() {
+		var item settlement
+		if err := rows.Scan(&item.transactionId, &item.from, &item.to, &item.amount); err != nil {
+			rows.Close()
+			return err
+		}
+		settlements = append(settlements, item)
+	}
+	rows.Close()
+
+	duel := sql.NullInt64{Int64: duel_id, Valid: true}
+	for _, item := range settlements {
+		if err := r.postCoins(tx, models.CoinReasonReversal, duel, item.to, item.from, item.amount); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE coin_transactions SET reversed = true WHERE id = $1`, item.transactionId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) FindCoinBalance(user_id int64) (int64, error) {
+	var balance int64
+	err := r.Db.QueryRow(
+		`SELECT COALESCE((SELECT balance FROM coin_accounts WHERE user_id = $1), 0)`, user_id,
+	).Scan(&balance)
+	return balance, err
+}
+
+// FindCoinHistory returns the latest ledger entries of the user's account.
+func (r *Repository) FindCoinHistory(user_id int64, limit int) ([]models.CoinEntryDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT t.id, t.reason, t.duel_id, e.amount, TO_CHAR(t.created_at, 'YYYY-MM-DD')
+		FROM coin_entries e
+		JOIN coin_transactions t ON e.transaction_id = t.id
+		JOIN coin_accounts a ON e.account_id = a.id
+		WHERE a.user_id = $1
+		ORDER BY t.id DESC LIMIT $2`, user_id, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.CoinEntryDb = []models.CoinEntryDb{}
+	for rows.Next() {
+		entry := models.CoinEntryDb{}
+		if err := rows.Scan(&entry.TransactionId, &entry.Reason, &entry.DuelId, &entry.Amount, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// AuditCoinLedger checks that every operation is balanced and every stored
+// balance equals the sum of the account's entries.
+func (r *Repository) AuditCoinLedger() (*dto.CoinAuditDto, error) {
+	audit := dto.CoinAuditDto{MismatchedAccounts: []int64{}, UnbalancedTransactions: []int64{}}
+	err := r.Db.QueryRow(
+		`SELECT (SELECT COUNT(*) FROM coin_accounts), (SELECT COUNT(*) FROM coin_transactions)`,
+	).Scan(&audit.Accounts, &audit.Transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Db.Select(&audit.MismatchedAccounts,
+		`SELECT a.id FROM coin_accounts a
+		LEFT JOIN coin_entries e ON e.account_id = a.id
+		GROUP BY a.id, a.balance
+		HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+		ORDER BY a.id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = r.Db.Select(&audit.UnbalancedTransactions,
+		`SELECT t.id FROM coin_transactions t
+		LEFT JOIN coin_entries e ON e.transaction_id = t.id
+		GROUP BY t.id
+		HAVING COALESCE(SUM(e.amount), 0) <> 0 OR COUNT(e.id) < 2
+		ORDER BY t.id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	audit.Ok = len(audit.MismatchedAccounts) == 0 && len(audit.UnbalancedTransactions) == 0
+	return &audit, nil
+}
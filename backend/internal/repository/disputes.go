@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const disputeSelectQuery = `
+	SELECT d.id, d.log_id, d.duel_id, logs.owner_id, d.disputer_id, users.first_name, d.reason, d.excluded,
+	d.status, d.resolution, d.resolved_by, TO_CHAR(d.created_at, 'YYYY-MM-DD'), TO_CHAR(d.resolved_at, 'YYYY-MM-DD')
+	FROM log_disputes d
+	JOIN logs ON d.log_id = logs.id
+	JOIN users ON d.disputer_id = users.id
+`
+
+// undisputedLog leaves out the logs excluded by an open dispute or struck off
+// by an upheld one: their values no longer count.
+const undisputedLog = `NOT EXISTS (SELECT 1 FROM log_disputes ld WHERE ld.log_id = logs.id
+	AND (ld.status = 'upheld' OR (ld.status = 'open' AND ld.excluded)))`
+
+func scanDispute(row rowScanner) (models.DisputeDb, error) {
+	var dispute models.DisputeDb
+	err := row.Scan(&dispute.Id, &dispute.LogId, &dispute.DuelId, &dispute.OwnerId, &dispute.DisputerId,
+		&dispute.DisputerFirstName, &dispute.Reason, &dispute.Excluded, &dispute.Status, &dispute.Resolution,
+		&dispute.ResolvedBy, &dispute.CreatedAt, &dispute.ResolvedAt)
+	return dispute, err
+}
+
+func (r *Repository) FindLogById(log_id int64) (*models.LogDB, error) {
+	var log models.LogDB
+	var message sql.NullString
+	err := r.Db.QueryRow(
+		`SELECT id, owner_id, duel_id, TO_CHAR(created_at, 'YYYY-MM-DD'), message, photo, value, counted, logged_at
+		FROM logs WHERE id = $1 AND NOT hidden`, log_id,
+	).Scan(&log.ID, &log.OwnerID, &log.DuelID, &log.CreatedAt, &message, &log.Photo, &log.Value, &log.Counted, &log.LoggedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("log does not exist")
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Message = message.String
+	return &log, nil
+}
+
+// uncountLog stops counting the log's day for its owner in the duel and rolls
+// back the check-in reward and the streak update, the way deleting it would.
+func (r *Repository) uncountLog(tx *sqlx.Tx, log_id int64, owner_id int64, duel_id int64) error {
+	snapshot, err := findStreakSnapshot(tx, log_id)
+	if err != nil {
+		return err
+	}
+	var day string
+	err = tx.QueryRow(
+		`UPDATE logs SET counted = false WHERE id = $1 RETURNING TO_CHAR(created_at, 'YYYY-MM-DD')`, log_id,
+	).Scan(&day)
+	if err != nil {
+		return err
+	}
+	if err := saveStreakSnapshot(tx, log_id, nil); err != nil {
+		return err
+	}
+	return r.uncountDay(tx, owner_id, duel_id, day, snapshot)
+}
+
+// recountLog counts the log's day again unless the owner has checked in once more
+// that day in the meantime: the counter, the streak and the check-in reward come
+// back. Reports whether the day was counted.
+func (r *Repository) recountLog(tx *sqlx.Tx, log_id int64, owner_id int64, duel_id int64) (bool, error) {
+	var dayCounted bool
+	err := tx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM logs other JOIN logs l ON l.id = $1
+		WHERE other.owner_id = $2 AND other.duel_id = $3 AND other.created_at = l.created_at AND other.counted)`,
+		log_id, owner_id, duel_id,
+	).Scan(&dayCounted)
+	if err != nil || dayCounted {
+		return false, err
+	}
+	var day string
+	err = tx.QueryRow(
+		`UPDATE logs SET counted = true WHERE id = $1 RETURNING TO_CHAR(created_at, 'YYYY-MM-DD')`, log_id,
+	).Scan(&day)
+	if err != nil {
+		return false, err
+	}
+	_, err = tx.Exec(
+		`UPDATE duel_participants SET completed = completed + 1 WHERE duel_id = $1 AND user_id = $2`,
+		duel_id, owner_id,
+	)
+	if err != nil {
+		return false, err
+	}
+	if err := backfillStreak(tx, owner_id, day); err != nil {
+		return false, err
+	}
+	if err := r.rewardCoins(tx, owner_id, models.CoinsPerCheckIn, models.CoinReasonCheckIn, duel_id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateDispute opens a dispute on the log. With dispute.Excluded a counted log
+// of an active duel stops counting until the dispute is resolved. A log of an
+// ended duel can only be disputed while the result may still change.
+func (r *Repository) CreateDispute(dispute *models.DisputeDb) (int64, error) {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var counted bool
+	err = tx.QueryRow(`SELECT counted FROM logs WHERE id = $1 FOR UPDATE`, dispute.LogId).Scan(&counted)
+	if err != nil {
+		return 0, err
+	}
+	state, err := lockDuelState(tx, dispute.DuelId)
+	if err != nil {
+		return 0, err
+	}
+	if state.status == duelStatusEnded {
+		if err := state.finalResult(); err != nil {
+			return 0, err
+		}
+	}
+	excluded := dispute.Excluded && counted && state.status == duelStatusActive
+
+	var id int64
+	err = tx.QueryRow(
+		`INSERT INTO log_disputes (log_id, duel_id, disputer_id, reason, excluded, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (log_id) WHERE status = 'open' DO NOTHING
+		RETURNING id`,
+		dispute.LogId, dispute.DuelId, dispute.DisputerId, dispute.Reason, excluded, r.Clock.Today(),
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("this log is already disputed")
+	}
+	if err != nil {
+		return 0, err
+	}
+	if excluded {
+		if err := r.uncountLog(tx, dispute.LogId, dispute.OwnerId, dispute.DuelId); err != nil {
+			return 0, err
+		}
+	}
+	return id, tx.Commit()
+}
+
+func (r *Repository) FindDisputeById(dispute_id int64) (*models.DisputeDb, error) {
+	dispute, err := scanDispute(r.Db.QueryRow(disputeSelectQuery+`WHERE d.id = $1`, dispute_id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("dispute does not exist")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+// ResolveDispute closes an open dispute. An upheld dispute stops counting the
+// log and a rejected one counts an excluded log again. Counters of an active
+// duel change in place; an ended duel whose result depended on the log is
+// reopened and decided again by decide, unless its result is final. Reports
+// whether the log was counted again in an active duel and whether an ended
+// duel was reopened.
+func (r *Repository) ResolveDispute(dispute_id int64, status string, resolution string, resolved_by int64, decide DuelDecider) (bool, bool, error) {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return false, false, err
+	}
+	defer tx.Rollback()
+
+	var logId, duelId, ownerId int64
+	var excluded, counted bool
+	var value sql.NullFloat64
+	var currentStatus string
+	err = tx.QueryRow(
+		`SELECT d.log_id, d.duel_id, logs.owner_id, d.excluded, logs.counted, logs.value, d.status
+		FROM log_disputes d JOIN logs ON d.log_id = logs.id
+		WHERE d.id = $1 FOR UPDATE OF d, logs`, dispute_id,
+	).Scan(&logId, &duelId, &ownerId, &excluded, &counted, &value, &currentStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, false, errors.New("dispute does not exist")
+		}
+		return false, false, err
+	}
+	if currentStatus != models.DisputeStatusOpen {
+		return false, false, errors.New("dispute has already been resolved")
+	}
+
+	_, err = tx.Exec(
+		`UPDATE log_disputes SET status = $1, resolution = $2, resolved_by = $3, resolved_at = $4 WHERE id = $5`,
+		status, resolution, resolved_by, r.Clock.Today(), dispute_id,
+	)
+	if err != nil {
+		return false, false, err
+	}
+
+	state, err := lockDuelState(tx, duelId)
+	if err != nil {
+		return false, false, err
+	}
+	ended := state.status == duelStatusEnded
+	if state.status != duelStatusActive && (!ended || state.finalResult() != nil) {
+		return false, false, tx.Commit()
+	}
+
+	changed := false
+	switch {
+	case status == models.DisputeStatusUpheld && counted:
+		err = r.uncountLog(tx, logId, ownerId, duelId)
+		changed = true
+	case status == models.DisputeStatusRejected && excluded && !counted:
+		changed, err = r.recountLog(tx, logId, ownerId, duelId)
+	}
+	if err != nil {
+		return false, false, err
+	}
+	if !ended {
+		return changed, false, tx.Commit()
+	}
+
+	// Объём меняется, если значение отметки перестало или снова стало учитываться
+	volumeChanged := state.scoringMode == models.ScoringVolume && value.Valid &&
+		(status == models.DisputeStatusUpheld) != excluded
+	if !changed && !volumeChanged {
+		return false, false, tx.Commit()
+	}
+	if err := r.reopenDuel(tx, duelId); err != nil {
+		return false, false, err
+	}
+	if err := r.decideAgain(tx, duelId, decide); err != nil {
+		return false, false, err
+	}
+	return false, true, tx.Commit()
+}
+
+func (r *Repository) FindDisputesByDuelId(duel_id int64) ([]models.DisputeDb, error) {
+	return r.findDisputes(`WHERE d.duel_id = $1 ORDER BY d.id`, duel_id)
+}
+
+// FindOpenDisputes returns the disputes waiting for a resolution, the oldest first.
+func (r *Repository) FindOpenDisputes(limit int) ([]models.DisputeDb, error) {
+	return r.findDisputes(`WHERE d.status = 'open' ORDER BY d.id LIMIT $1`, limit)
+}
+
+func (r *Repository) findDisputes(filter string, args ...any) ([]models.DisputeDb, error) {
+	rows, err := r.Db.Query(disputeSelectQuery+filter, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var disputes []models.DisputeDb = []models.DisputeDb{}
+	for rows.Next() {
+		dispute, err := scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+		disputes = append(disputes, dispute)
+	}
+	return disputes, nil
+}
@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/models"
+)
+
+// SendFriendRequest asks the other user for friendship. If they have already
+// asked the user, the friendship is accepted at once. Returns the new status.
+func (r *Repository) SendFriendRequest(user_id int64, other_id int64) (string, error) {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var blocked bool
+	err = tx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM user_blocks
+		WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1))`,
+		user_id, other_id,
+	).Scan(&blocked)
+	if err != nil {
+		return "", err
+	}
+	if blocked {
+		return "", errors.New("you cannot send a friend request to this user")
+	}
+
+	var requesterId int64
+	var status string
+	err = tx.QueryRow(
+		`SELECT requester_id, status FROM friendships
+		WHERE LEAST(requester_id, addressee_id) = LEAST($1::integer, $2::integer)
+		AND GREATEST(requester_id, addressee_id) = GREATEST($1::integer, $2::integer)
+		FOR UPDATE`, user_id, other_id,
+	).Scan(&requesterId, &status)
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = tx.Exec(
+			`INSERT INTO friendships (requester_id, addressee_id, status, created_at) VALUES ($1, $2, $3, $4)`,
+			user_id, other_id, models.FriendshipPending, r.Clock.Today(),
+		)
+		if err != nil {
+			return "", err
+		}
+		return models.FriendshipPending, tx.Commit()
+	case err != nil:
+		return "", err
+	case status == models.FriendshipAccepted:
+		return "", errors.New("you are already friends")
+	case requesterId == user_id:
+		return "", errors.New("friend request has already been sent")
+	}
+
+	// Встречная заявка - сразу дружим
+	if err := acceptFriendship(tx, other_id, user_id, r.Clock.Today()); err != nil {
+		return "", err
+	}
+	return models.FriendshipAccepted, tx.Commit()
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func acceptFriendship(db execer, requester_id int64, addressee_id int64, today string) error {
+	res, err := db.Exec(
+		`UPDATE friendships SET status = $1, accepted_at = $2
+		WHERE requester_id = $3 AND addressee_id = $4 AND status = $5`,
+		models.FriendshipAccepted, today, requester_id, addressee_id, models.FriendshipPending,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("there is no friend request from this user")
+	}
+	return nil
+}
+
+func (r *Repository) AcceptFriendRequest(user_id int64, requester_id int64) error {
+	return acceptFriendship(r.Db, requester_id, user_id, r.Clock.Today())
+}
+
+// RemoveFriend ends the friendship, or cancels or declines a pending request.
+func (r *Repository) RemoveFriend(user_id int64, other_id int64) error {
+	res, err := r.Db.Exec(
+		`DELETE FROM friendships
+		WHERE (requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1)`,
+		user_id, other_id,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("there is no friendship or friend request with this user")
+	}
+	return nil
+}
+
+func (r *Repository) AreFriends(user_id int64, other_id int64) (bool, error) {
+	var friends bool
+	err := r.Db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM friendships WHERE status = $3
+		AND ((requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1)))`,
+		user_id, other_id, models.FriendshipAccepted,
+	).Scan(&friends)
+	return friends, err
+}
+
+func (r *Repository) FindFriends(user_id int64) ([]models.FriendDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT u.id, u.first_name, COALESCE(u.photo_url, ''), TO_CHAR(f.accepted_at, 'YYYY-MM-DD')
+		FROM friendships f
+		JOIN users u ON u.id = CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END
+		WHERE (f.requester_id = $1 OR f.addressee_id = $1) AND f.status = $2
+		ORDER BY u.first_name, u.id`, user_id, models.FriendshipAccepted,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var friends []models.FriendDb = []models.FriendDb{}
+	for rows.Next() {
+		friend := models.FriendDb{}
+		if err := rows.Scan(&friend.UserId, &friend.FirstName, &friend.PhotoUrl, &friend.Since); err != nil {
+			return nil, err
+		}
+		friends = append(friends, friend)
+	}
+	return friends, nil
+}
+
+// FindFriendRequests returns the pending requests sent to and by the user.
+func (r *Repository) FindFriendRequests(user_id int64) ([]models.FriendRequestDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT u.id, u.first_name, COALESCE(u.photo_url, ''), f.addressee_id = $1, TO_CHAR(f.created_at, 'YYYY-MM-DD')
+		FROM friendships f
+		JOIN users u ON u.id = CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END
+		WHERE (f.requester_id = $1 OR f.addressee_id = $1) AND f.status = $2
+		ORDER BY f.id DESC`, user_id, models.FriendshipPending,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []models.FriendRequestDb = []models.FriendRequestDb{}
+	for rows.Next() {
+		request := models.FriendRequestDb{}
+		err := rows.Scan(&request.UserId, &request.FirstName, &request.PhotoUrl, &request.Incoming, &request.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+// FindFriendSuggestions returns past opponents the user has no friendship,
+// request or block with, the most frequent opponents first.
+func (r *Repository) FindFriendSuggestions(user_id int64, limit int) ([]models.FriendSuggestionDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT u.id, u.first_name, COALESCE(u.photo_url, ''), COUNT(*) AS shared
+		FROM duel_participants me
+		JOIN duel_participants other ON other.duel_id = me.duel_id AND other.user_id <> me.user_id
+		JOIN users u ON u.id = other.user_id
+		WHERE me.user_id = $1
+		AND NOT EXISTS (SELECT 1 FROM friendships f
+			WHERE (f.requester_id = $1 AND f.addressee_id = u.id) OR (f.requester_id = u.id AND f.addressee_id = $1))
+		AND NOT EXISTS (SELECT 1 FROM user_blocks b
+			WHERE (b.blocker_id = $1 AND b.blocked_id = u.id) OR (b.blocker_id = u.id AND b.blocked_id = $1))
+		GROUP BY u.id, u.first_name, u.photo_url
+		ORDER BY shared DESC, u.id
+		LIMIT $2`, user_id, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []models.FriendSuggestionDb = []models.FriendSuggestionDb{}
+	for rows.Next() {
+		suggestion := models.FriendSuggestionDb{}
+		err := rows.Scan(&suggestion.UserId, &suggestion.FirstName, &suggestion.PhotoUrl, &suggestion.SharedDuels)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
+
+// BlockUser blocks the other user and ends any friendship or request between them.
+func (r *Repository) BlockUser(user_id int64, other_id int64) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`INSERT INTO user_blocks (blocker_id, blocked_id, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (blocker_id, blocked_id) DO NOTHING`,
+		user_id, other_id, r.Clock.Today(),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`DELETE FROM friendships
+		WHERE (requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1)`,
+		user_id, other_id,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) UnblockUser(user_id int64, other_id int64) error {
+	res, err := r.Db.Exec(`DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`, user_id, other_id)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("this user is not blocked")
+	}
+	return nil
+}
+
+func (r *Repository) FindBlockedUsers(user_id int64) ([]models.BlockedUserDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT u.id, u.first_name, COALESCE(u.photo_url, ''), TO_CHAR(b.created_at, 'YYYY-MM-DD')
+		FROM user_blocks b
+		JOIN users u ON u.id = b.blocked_id
+		WHERE b.blocker_id = $1
+		ORDER BY b.id DESC`, user_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocked []models.BlockedUserDb = []models.BlockedUserDb{}
+	for rows.Next() {
+		user := models.BlockedUserDb{}
+		if err := rows.Scan(&user.UserId, &user.FirstName, &user.PhotoUrl, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		blocked = append(blocked, user)
+	}
+	return blocked, nil
+}
+
+// IsBlocked reports whether either of the users has blocked the other.
+func (r *Repository) IsBlocked(user_id int64, other_id int64) (bool, error) {
+	var blocked bool
+	err := r.Db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM user_blocks
+		WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1))`,
+		user_id, other_id,
+	).Scan(&blocked)
+	return blocked, err
+}
+
+func (r *Repository) SetVisibility(user_id int64, visibility string) error {
+	_, err := r.Db.Exec(`UPDATE users SET visibility = $1 WHERE id = $2`, visibility, user_id)
+	return err
+}
+
+func (r *Repository) FindVisibility(user_id int64) (string, error) {
+	var visibility string
+	err := r.Db.QueryRow(`SELECT visibility FROM users WHERE id = $1`, user_id).Scan(&visibility)
+	if err == sql.ErrNoRows {
+		return "", errors.New("user does not exist")
+	}
+	return visibility, err
+}
+
+func (r *Repository) FindActiveDuelsByUserId(user_id int64) ([]models.DuelDb, error) {
+	return r.findDuels(
+		`WHERE duel_status.value = 'active'
+		AND duels.id IN (SELECT duel_id FROM duel_participants WHERE user_id = $1)
+		ORDER BY duels.id`, user_id,
+	)
+}
@@ -0,0 +1,44 @@
+package repository
+
+import "maxbot/internal/models"
+
+// FindHeadToHeadDuels returns the ended duels where both players took part on
+// opposite sides, oldest first. The result is decided by winner_id (or the
+// winning team), and by the places when somebody else won a group duel.
+func (r *Repository) FindHeadToHeadDuels(user_id int64, opponent_id int64) ([]models.HeadToHeadDuelDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT d.id, h.name, hc.name, TO_CHAR(d.start_date, 'YYYY-MM-DD'), TO_CHAR(d.end_date, 'YYYY-MM-DD'),
+		CASE
+			WHEN d.winner_id = me.user_id OR d.winner_team_id = me.team_id THEN 'win'
+			WHEN d.winner_id = opp.user_id OR d.winner_team_id = opp.team_id THEN 'loss'
+			WHEN me.place < opp.place THEN 'win'
+			WHEN me.place > opp.place THEN 'loss'
+			ELSE 'draw'
+		END,
+		me.completed, opp.completed, d.rematch_of
+		FROM duel_participants me
+		JOIN duel_participants opp ON opp.duel_id = me.duel_id AND opp.user_id = $2
+		JOIN duels d ON d.id = me.duel_id
+		JOIN habits h ON d.habit_id = h.id
+		JOIN habit_categories hc ON h.habit_category_id = hc.id
+		WHERE me.user_id = $1 AND d.status_id = (SELECT id FROM duel_status WHERE value = 'ended')
+		AND (me.team_id IS NULL OR opp.team_id IS NULL OR me.team_id <> opp.team_id)
+		ORDER BY d.end_date, d.id`, user_id, opponent_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var duels []models.HeadToHeadDuelDb = []models.HeadToHeadDuelDb{}
+	for rows.Next() {
+		duel := models.HeadToHeadDuelDb{}
+		err := rows.Scan(&duel.DuelId, &duel.HabitName, &duel.HabitCategory, &duel.StartDate, &duel.EndDate,
+			&duel.Result, &duel.Completed, &duel.OpponentCompleted, &duel.RematchOf)
+		if err != nil {
+			return nil, err
+		}
+		duels = append(duels, duel)
+	}
+	return duels, nil
+}
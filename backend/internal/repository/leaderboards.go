@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"fmt"
+	"maxbot/internal/models"
+)
+
+// RefreshLeaderboardStats recalculates the per-user aggregates the
+// leaderboards are served from. Windows end today by the repository clock.
+func (r *Repository) RefreshLeaderboardStats() error {
+	_, err := r.Db.Exec(
+		`INSERT INTO leaderboard_stats (user_id, wins_week, wins_month, wins_all,
+			completions_week, completions_month, completions_all, streak, rating, refreshed_at)
+		SELECT u.id,
+			(SELECT COUNT(*) FROM duel_participants p JOIN duels d ON p.duel_id = d.id
+				WHERE p.user_id = u.id AND (d.winner_id = u.id OR d.winner_team_id = p.team_id)
+				AND d.end_date > $1::date - 7),
+			(SELECT COUNT(*) FROM duel_participants p JOIN duels d ON p.duel_id = d.id
+				WHERE p.user_id = u.id AND (d.winner_id = u.id OR d.winner_team_id = p.team_id)
+				AND d.end_date > $1::date - 30),
+			u.wins,
+			(SELECT COUNT(*) FROM logs l WHERE l.owner_id = u.id AND l.counted AND l.created_at > $1::date - 7),
+			(SELECT COUNT(*) FROM logs l WHERE l.owner_id = u.id AND l.counted AND l.created_at > $1::date - 30),
+			(SELECT COUNT(*) FROM logs l WHERE l.owner_id = u.id AND l.counted),
+			-- The streak is current only if the user contributed today or yesterday
+			CASE WHEN u.last_time_contributed >= $1::date - 1 THEN u.streak ELSE 0 END,
+			u.rating,
+			NOW()
+		FROM users u
+		ON CONFLICT (user_id) DO UPDATE SET
+			wins_week = EXCLUDED.wins_week,
+			wins_month = EXCLUDED.wins_month,
+			wins_all = EXCLUDED.wins_all,
+			completions_week = EXCLUDED.completions_week,
+			completions_month = EXCLUDED.completions_month,
+			completions_all = EXCLUDED.completions_all,
+			streak = EXCLUDED.streak,
+			rating = EXCLUDED.rating,
+			refreshed_at = EXCLUDED.refreshed_at`,
+		r.Clock.Today(),
+	)
+	return err
+}
+
+// friendIdsQuery selects the viewer ($1) and their accepted friends.
+const friendIdsQuery = `
+	SELECT $1::integer
+	UNION
+	SELECT CASE WHEN requester_id = $1 THEN addressee_id ELSE requester_id END FROM friendships
+	WHERE (requester_id = $1 OR addressee_id = $1) AND status = 'accepted'
+`
+
+// FindLeaderboard returns a page of the leaderboard ordered by the column.
+// column must be one of the leaderboard_stats score columns. viewerID limits
+// the board to the viewer's friends, 0 means global. The page starts after the
+// (afterScore, afterUserID) cursor when hasCursor is set.
+func (r *Repository) FindLeaderboard(column string, viewerID int64, hasCursor bool, afterScore int64, afterUserID int64, limit int) ([]models.LeaderboardEntryDb, error) {
+	rows, err := r.Db.Query(fmt.Sprintf(
+		`SELECT rank, user_id, first_name, photo_url, score FROM (
+			SELECT RANK() OVER (ORDER BY s.%[1]s DESC) AS rank, s.user_id, u.first_name,
+			COALESCE(u.photo_url, '') AS photo_url, s.%[1]s AS score
+			FROM leaderboard_stats s
+			JOIN users u ON s.user_id = u.id
+			WHERE $1::integer = 0 OR s.user_id IN (`+friendIdsQuery+`)
+		) ranked
+		WHERE NOT $2 OR score < $3 OR (score = $3 AND user_id > $4)
+		ORDER BY score DESC, user_id
+		LIMIT $5`, column),
+		viewerID, hasCursor, afterScore, afterUserID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.LeaderboardEntryDb = []models.LeaderboardEntryDb{}
+	for rows.Next() {
+		entry := models.LeaderboardEntryDb{}
+		err := rows.Scan(&entry.Rank, &entry.UserId, &entry.FirstName, &entry.PhotoUrl, &entry.Score)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/models"
+)
+
+const lobbySelectQuery = `
+	SELECT lobby_entries.id, lobby_entries.user_id, users.first_name, COALESCE(users.photo_url, ''),
+	lobby_entries.duel_id, habits.name, lobby_entries.habit_category, lobby_entries.days,
+	lobby_entries.rating, TO_CHAR(lobby_entries.created_at, 'YYYY-MM-DD HH24:MI')
+	FROM lobby_entries
+	JOIN users ON lobby_entries.user_id = users.id
+	JOIN duels ON lobby_entries.duel_id = duels.id
+	JOIN habits ON duels.habit_id = habits.id
+`
+
+func scanLobbyEntry(row rowScanner) (models.LobbyEntryDb, error) {
+	var entry models.LobbyEntryDb
+	err := row.Scan(&entry.Id, &entry.UserId, &entry.FirstName, &entry.PhotoUrl, &entry.DuelId,
+		&entry.HabitName, &entry.HabitCategory, &entry.Days, &entry.Rating, &entry.CreatedAt)
+	return entry, err
+}
+
+func (r *Repository) CreateLobbyEntry(entry *models.LobbyEntryDb) (int64, error) {
+	var id int64
+	err := r.Db.QueryRow(
+		`INSERT INTO lobby_entries (user_id, duel_id, habit_category, days, rating)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		entry.UserId, entry.DuelId, entry.HabitCategory, entry.Days, entry.Rating,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *Repository) FindLobbyEntryById(entry_id int64) (*models.LobbyEntryDb, error) {
+	entry, err := scanLobbyEntry(r.Db.QueryRow(lobbySelectQuery+`WHERE lobby_entries.id = $1`, entry_id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("lobby entry does not exist or has already been taken")
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// FindLobbyEntries returns open entries, empty category or zero days match any.
+// Entries of users blocked by or blocking the viewer are left out.
+func (r *Repository) FindLobbyEntries(viewer_id int64, category string, days int) ([]models.LobbyEntryDb, error) {
+	rows, err := r.Db.Query(
+		lobbySelectQuery+`WHERE ($1 = '' OR lobby_entries.habit_category = $1) AND ($2 = 0 OR lobby_entries.days = $2)
+		AND NOT EXISTS (SELECT 1 FROM user_blocks b
+			WHERE (b.blocker_id = $3 AND b.blocked_id = lobby_entries.user_id)
+			OR (b.blocker_id = lobby_entries.user_id AND b.blocked_id = $3))
+		ORDER BY lobby_entries.created_at`,
+		category, days, viewer_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.LobbyEntryDb = []models.LobbyEntryDb{}
+	for rows.Next() {
+		entry, err := scanLobbyEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// JoinLobbyEntry pairs the user with the entry's author: the entry is taken and
+// the duel is joined and activated in one transaction.
+func (r *Repository) JoinLobbyEntry(user_id int64, entry_id int64, target int) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var invitationHash string
+	err = tx.QueryRow(
+		`SELECT invitations.generatedHash FROM lobby_entries
+		JOIN invitations ON invitations.duel_id = lobby_entries.duel_id
+		WHERE lobby_entries.id = $1
+		FOR UPDATE OF lobby_entries`, entry_id,
+	).Scan(&invitationHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("lobby entry does not exist or has already been taken")
+		}
+		return err
+	}
+
+	// activateDuel removes the lobby entry
+	if _, err := r.joinDuelByInvitationHash(tx, user_id, invitationHash, target); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CancelLobbyEntry removes the entry and cancels its duel that nobody joined,
+// returning the escrowed stake.
+func (r *Repository) CancelLobbyEntry(user_id int64, entry_id int64) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var duelId int64
+	err = tx.QueryRow(
+		`DELETE FROM lobby_entries WHERE id = $1 AND user_id = $2 RETURNING duel_id`, entry_id, user_id,
+	).Scan(&duelId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("lobby entry does not exist")
+		}
+		return err
+	}
+	if err := r.cancelInvitedDuel(tx, duelId, user_id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
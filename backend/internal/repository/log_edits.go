@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// UpdateLog replaces the message and the photo of the log.
+func (r *Repository) UpdateLog(log_id int64, message string, photo *[]byte) error {
+	_, err := r.Db.Exec(`UPDATE logs SET message = $1, photo = $2 WHERE id = $3`, message, photo, log_id)
+	return err
+}
+
+func scanStreakSnapshot(row *sqlx.Row) (*models.StreakSnapshot, error) {
+	var streak, freezes sql.NullInt64
+	var contributed sql.NullString
+	if err := row.Scan(&streak, &freezes, &contributed); err != nil {
+		return nil, err
+	}
+	if !streak.Valid {
+		return nil, nil
+	}
+	return &models.StreakSnapshot{
+		Streak:              int(streak.Int64),
+		StreakFreezes:       int(freezes.Int64),
+		LastTimeContributed: contributed,
+	}, nil
+}
+
+func findStreakSnapshot(tx *sqlx.Tx, log_id int64) (*models.StreakSnapshot, error) {
+	return scanStreakSnapshot(tx.QueryRowx(
+		`SELECT streak_before, streak_freezes_before, TO_CHAR(last_contributed_before, 'YYYY-MM-DD')
+		FROM logs WHERE id = $1`, log_id,
+	))
+}
+
+func saveStreakSnapshot(tx *sqlx.Tx, log_id int64, snapshot *models.StreakSnapshot) error {
+	var streak, freezes sql.NullInt64
+	var contributed sql.NullString
+	if snapshot != nil {
+		streak = sql.NullInt64{Int64: int64(snapshot.Streak), Valid: true}
+		freezes = sql.NullInt64{Int64: int64(snapshot.StreakFreezes), Valid: true}
+		contributed = snapshot.LastTimeContributed
+	}
+	_, err := tx.Exec(
+		`UPDATE logs SET streak_before = $1, streak_freezes_before = $2, last_contributed_before = $3 WHERE id = $4`,
+		streak, freezes, contributed, log_id,
+	)
+	return err
+}
+
+// restoreStreak undoes the streak update made by the first counted check-in of
+// the day. If the user still has a counted check-in that day in another duel,
+// the streak stays and that check-in takes over the snapshot. A streak that has
+// moved on to a later day is recounted without the day.
+func restoreStreak(tx *sqlx.Tx, owner_id int64, day string, snapshot *models.StreakSnapshot) error {
+	if snapshot == nil {
+		return nil
+	}
+	var otherLog sql.NullInt64
+	err := tx.QueryRow(
+		`SELECT MIN(id) FROM logs WHERE owner_id = $1 AND created_at = $2 AND counted`, owner_id, day,
+	).Scan(&otherLog)
+	if err != nil {
+		return err
+	}
+	if otherLog.Valid {
+		return saveStreakSnapshot(tx, otherLog.Int64, snapshot)
+	}
+
+	var lastContributed sql.NullString
+	err = tx.QueryRow(
+		`SELECT TO_CHAR(last_time_contributed, 'YYYY-MM-DD') FROM users WHERE id = $1 FOR UPDATE`, owner_id,
+	).Scan(&lastContributed)
+	if err != nil {
+		return err
+	}
+	if lastContributed.String > day {
+		return recountStreak(tx, owner_id, lastContributed.String)
+	}
+
+	res, err := tx.Exec(
+		`UPDATE users SET streak = $2, streak_freezes = $3, last_time_contributed = $4
+		WHERE id = $1 AND last_time_contributed = $5`,
+		owner_id, snapshot.Streak, snapshot.StreakFreezes, snapshot.LastTimeContributed, day,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return err
+	}
+	// Заморозки, потраченные в этот день, возвращаются вместе со стриком
+	_, err = tx.Exec(`DELETE FROM streak_freezes WHERE user_id = $1 AND used_at = $2`, owner_id, day)
+	return err
+}
+
+// uncountDay rolls back what a counted day gave the owner: the duel counter,
+// the check-in reward and the streak update saved in snapshot.
+func (r *Repository) uncountDay(tx *sqlx.Tx, owner_id int64, duel_id int64, day string, snapshot *models.StreakSnapshot) error {
+	_, err := tx.Exec(
+		`UPDATE duel_participants SET completed = completed - 1 WHERE duel_id = $1 AND user_id = $2 AND completed > 0`,
+		duel_id, owner_id,
+	)
+	if err != nil {
+		return err
+	}
+	account, err := userCoinAccount(tx, owner_id)
+	if err != nil {
+		return err
+	}
+	rewards, err := systemCoinAccount(tx, models.CoinRewardsAccount)
+	if err != nil {
+		return err
+	}
+	duel := sql.NullInt64{Int64: duel_id, Valid: true}
+	if err := r.postCoins(tx, models.CoinReasonReversal, duel, account, rewards, models.CoinsPerCheckIn); err != nil {
+		return err
+	}
+	return restoreStreak(tx, owner_id, day, snapshot)
+}
+
+// duelState is what decides whether changed logs may still change the result of the duel.
+type duelState struct {
+	status          int
+	scoringMode     string
+	forfeited       bool
+	tournamentMatch bool
+}
+
+// lockDuelState locks the duel until the end of the transaction.
+func lockDuelState(tx *sqlx.Tx, duel_id int64) (duelState, error) {
+	var state duelState
+	err := tx.QueryRow(
+		`SELECT status_id, forfeited_by IS NOT NULL, scoring_mode,
+			EXISTS (SELECT 1 FROM tournament_matches WHERE duel_id = duels.id)
+		FROM duels WHERE id = $1 FOR UPDATE`, duel_id,
+	).Scan(&state.status, &state.forfeited, &state.scoringMode, &state.tournamentMatch)
+	return state, err
+}
+
+// finalResult explains why the result of the ended duel can no longer change, nil if it can.
+func (state duelState) finalResult() error {
+	switch {
+	case state.forfeited:
+		return errors.New("the duel was forfeited, its result can no longer change")
+	case state.tournamentMatch:
+		return errors.New("the duel decided a tournament match, its result can no longer change")
+	}
+	return nil
+}
+
+// reopenDuel undoes the result of an ended duel: the wins, the rating changes
+// and the coin settlement. The duel becomes active so that it can be decided again.
+func (r *Repository) reopenDuel(tx *sqlx.Tx, duel_id int64) error {
+	_, err := tx.Exec(
+		`UPDATE users SET wins = wins - 1 WHERE wins > 0 AND id IN (
+			SELECT p.user_id FROM duel_participants p JOIN duels d ON p.duel_id = d.id
+			WHERE p.duel_id = $1 AND (p.user_id = d.winner_id OR p.team_id = d.winner_team_id)
+		)`, duel_id,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE users SET rating = users.rating - (h.new_rating - h.old_rating)
+		FROM rating_history h WHERE h.duel_id = $1 AND h.category = '' AND h.user_id = users.id`, duel_id,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`UPDATE user_category_ratings c SET rating = c.rating - (h.new_rating - h.old_rating)
+		FROM rating_history h
+		WHERE h.duel_id = $1 AND h.category <> '' AND c.user_id = h.user_id AND c.category = h.category`, duel_id,
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM rating_history WHERE duel_id = $1`, duel_id); err != nil {
+		return err
+	}
+
+	if err := r.reverseDuelSettlement(tx, duel_id); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE duels SET status_id = (SELECT id FROM duel_status WHERE value = 'active'), winner_id = NULL, winner_team_id = NULL, end_date = NULL WHERE id = $1`,
+		duel_id,
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE duel_participants SET place = NULL WHERE duel_id = $1`, duel_id); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE duel_teams SET place = NULL WHERE duel_id = $1`, duel_id)
+	return err
+}
+
+// DuelDecider decides a reopened duel from its current state; countedDays reads
+// the days counted to a player. A nil outcome means the duel goes on.
+type DuelDecider func(duel *models.DuelDb, countedDays func(userID int64, duelID int64) ([]string, error)) (*models.DuelOutcome, error)
+
+// decideAgain decides a reopened duel inside the transaction that reopened it,
+// so the duel is never seen without its new result.
+func (r *Repository) decideAgain(tx *sqlx.Tx, duel_id int64, decide DuelDecider) error {
+	duel, err := r.getDuelById(tx, duel_id)
+	if err != nil {
+		return err
+	}
+	outcome, err := decide(duel, func(userID int64, duelID int64) ([]string, error) {
+		return findCountedDays(tx, userID, duelID)
+	})
+	if err != nil || outcome == nil {
+		return err
+	}
+
+	if duel.DuelType == models.DuelTypeTeam {
+		return r.endTeamDuel(tx, duel.Id, outcome.WinnerId, outcome.EndDate, outcome.Places)
+	}
+	return r.endDuel(tx, duel.Id, outcome.WinnerId, outcome.EndDate, outcome.Places, sql.NullInt64{})
+}
+
+// DeleteLog removes the log with its reactions, comments, disputes and reports
+// and rolls back what the log changed: the duel counter, the check-in reward
+// and the owner's streak. dailyTarget is the daily target of numeric habits:
+// if the other logs of the day still reach it, the day stays counted.
+// An ended duel whose result depended on the log is reopened with its result
+// undone and decided again by decide. Reports whether the duel was reopened.
+func (r *Repository) DeleteLog(log_id int64, dailyTarget float64, decide DuelDecider) (bool, error) {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var ownerId, duelId int64
+	var day string
+	var value sql.NullFloat64
+	var counted bool
+	err = tx.QueryRow(
+		`SELECT owner_id, duel_id, TO_CHAR(created_at, 'YYYY-MM-DD'), value, counted
+		FROM logs WHERE id = $1 FOR UPDATE`, log_id,
+	).Scan(&ownerId, &duelId, &day, &value, &counted)
+	if err == sql.ErrNoRows {
+		return false, errors.New("log does not exist")
+	}
+	if err != nil {
+		return false, err
+	}
+
+	state, err := lockDuelState(tx, duelId)
+	if err != nil {
+		return false, err
+	}
+
+	snapshot, err := findStreakSnapshot(tx, log_id)
+	if err != nil {
+		return false, err
+	}
+
+	// Всё, что привязано к отметке, удаляется вместе с ней
+	for _, table := range []string{"log_reactions", "log_comments", "log_disputes", "reports"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE log_id = $1`, log_id); err != nil {
+			return false, err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM logs WHERE id = $1`, log_id); err != nil {
+		return false, err
+	}
+
+	// dayLost - день перестал засчитываться владельцу в этой дуэли
+	dayLost := counted
+	if dailyTarget > 0 {
+		var total float64
+		var countedLog, lastLog sql.NullInt64
+		err = tx.QueryRow(
+			`SELECT COALESCE(SUM(value) FILTER (WHERE `+undisputedLog+`), 0), MAX(id) FILTER (WHERE counted), MAX(id)
+			FROM logs WHERE owner_id = $1 AND duel_id = $2 AND created_at = $3`,
+			ownerId, duelId, day,
+		).Scan(&total, &countedLog, &lastLog)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case total >= dailyTarget && counted:
+			// Остальных отметок хватает до цели - день засчитывается с последней из них
+			if _, err := tx.Exec(`UPDATE logs SET counted = true WHERE id = $1`, lastLog.Int64); err != nil {
+				return false, err
+			}
+			if err := saveStreakSnapshot(tx, lastLog.Int64, snapshot); err != nil {
+				return false, err
+			}
+			dayLost = false
+		case total < dailyTarget && countedLog.Valid:
+			// Без удалённого объёма день больше не набирает цель
+			if snapshot, err = findStreakSnapshot(tx, countedLog.Int64); err != nil {
+				return false, err
+			}
+			if _, err := tx.Exec(`UPDATE logs SET counted = false WHERE id = $1`, countedLog.Int64); err != nil {
+				return false, err
+			}
+			if err := saveStreakSnapshot(tx, countedLog.Int64, nil); err != nil {
+				return false, err
+			}
+			dayLost = true
+		}
+	}
+
+	if dayLost {
+		if err := r.uncountDay(tx, ownerId, duelId, day, snapshot); err != nil {
+			return false, err
+		}
+	}
+
+	// Итог закончившейся дуэли зависит от отметки, если изменился счёт или объём
+	reopen := state.status == duelStatusEnded && (dayLost || (state.scoringMode == models.ScoringVolume && value.Valid))
+	if reopen {
+		if err := state.finalResult(); err != nil {
+			return false, err
+		}
+		if err := r.reopenDuel(tx, duelId); err != nil {
+			return false, err
+		}
+		if err := r.decideAgain(tx, duelId, decide); err != nil {
+			return false, err
+		}
+	}
+	return reopen, tx.Commit()
+}
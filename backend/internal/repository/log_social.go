@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/models"
+)
+
+// SetReaction sets the user's reaction to the log, an empty emoji removes it.
+func (r *Repository) SetReaction(log_id int64, user_id int64, emoji string) error {
+	if emoji == "" {
+		_, err := r.Db.Exec(`DELETE FROM log_reactions WHERE log_id = $1 AND user_id = $2`, log_id, user_id)
+		return err
+	}
+	_, err := r.Db.Exec(
+		`INSERT INTO log_reactions (log_id, user_id, emoji) VALUES ($1, $2, $3)
+		ON CONFLICT (log_id, user_id) DO UPDATE SET emoji = EXCLUDED.emoji`,
+		log_id, user_id, emoji,
+	)
+	return err
+}
+
+// FindDuelReactions returns the reaction counts of every log of the duel.
+func (r *Repository) FindDuelReactions(duel_id int64, viewer_id int64) ([]models.LogReactionDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT log_reactions.log_id, log_reactions.emoji, COUNT(*), BOOL_OR(log_reactions.user_id = $2)
+		FROM log_reactions
+		JOIN logs ON log_reactions.log_id = logs.id
+		WHERE logs.duel_id = $1
+		GROUP BY log_reactions.log_id, log_reactions.emoji
+		ORDER BY log_reactions.log_id, COUNT(*) DESC, log_reactions.emoji`,
+		duel_id, viewer_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reactions []models.LogReactionDb = []models.LogReactionDb{}
+	for rows.Next() {
+		reaction := models.LogReactionDb{}
+		if err := rows.Scan(&reaction.LogId, &reaction.Emoji, &reaction.Count, &reaction.Mine); err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, reaction)
+	}
+	return reactions, nil
+}
+
+// CountDuelComments returns the number of comments on every commented log of the duel.
+func (r *Repository) CountDuelComments(duel_id int64) (map[int64]int, error) {
+	rows, err := r.Db.Query(
+		`SELECT log_comments.log_id, COUNT(*) FROM log_comments
+		JOIN logs ON log_comments.log_id = logs.id
+		WHERE logs.duel_id = $1
+		GROUP BY log_comments.log_id`, duel_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[int64]int{}
+	for rows.Next() {
+		var logId int64
+		var count int
+		if err := rows.Scan(&logId, &count); err != nil {
+			return nil, err
+		}
+		counts[logId] = count
+	}
+	return counts, nil
+}
+
+func (r *Repository) CreateComment(comment *models.LogCommentDb) (int64, error) {
+	var id int64
+	err := r.Db.QueryRow(
+		`INSERT INTO log_comments (log_id, parent_id, user_id, message, created_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		comment.LogId, comment.ParentId, comment.UserId, comment.Message, r.Clock.Now(),
+	).Scan(&id)
+	return id, err
+}
+
+const commentSelectQuery = `
+	SELECT log_comments.id, log_comments.log_id, log_comments.parent_id, log_comments.user_id,
+	users.first_name, COALESCE(users.photo_url, ''), log_comments.message,
+	TO_CHAR(log_comments.created_at, 'YYYY-MM-DD HH24:MI')
+	FROM log_comments
+	JOIN users ON log_comments.user_id = users.id
+`
+
+func scanComment(row rowScanner) (models.LogCommentDb, error) {
+	var comment models.LogCommentDb
+	err := row.Scan(&comment.Id, &comment.LogId, &comment.ParentId, &comment.UserId,
+		&comment.FirstName, &comment.PhotoUrl, &comment.Message, &comment.CreatedAt)
+	return comment, err
+}
+
+func (r *Repository) FindCommentById(comment_id int64) (*models.LogCommentDb, error) {
+	comment, err := scanComment(r.Db.QueryRow(commentSelectQuery+`WHERE log_comments.id = $1`, comment_id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("comment does not exist")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// FindLogComments returns the comments on the log in the order they were written.
+func (r *Repository) FindLogComments(log_id int64) ([]models.LogCommentDb, error) {
+	rows, err := r.Db.Query(commentSelectQuery+`WHERE log_comments.log_id = $1 ORDER BY log_comments.id`, log_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []models.LogCommentDb = []models.LogCommentDb{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import "maxbot/internal/models"
+
+func (r *Repository) FindDuelParticipants(duel_id int) ([]models.ParticipantDb, error) {
+	return findDuelParticipants(r.Db, duel_id)
+}
+
+func findDuelParticipants(q queryer, duel_id int) ([]models.ParticipantDb, error) {
+	rows, err := q.Query(
+		`SELECT p.user_id, p.team_id, u.first_name, u.photo_url, p.completed, p.place,
+		(SELECT COALESCE(SUM(value), 0) FROM logs WHERE logs.duel_id = p.duel_id AND logs.owner_id = p.user_id
+			AND `+undisputedLog+`)
+		FROM duel_participants p
+		JOIN users u ON p.user_id = u.id
+		WHERE p.duel_id = $1
+		ORDER BY p.id`, duel_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var participants []models.ParticipantDb = []models.ParticipantDb{}
+	for rows.Next() {
+		participant := models.ParticipantDb{}
+		err := rows.Scan(&participant.UserId, &participant.TeamId, &participant.FirstName, &participant.PhotoUrl,
+			&participant.Completed, &participant.Place, &participant.Volume)
+		if err != nil {
+			return nil, err
+		}
+		participants = append(participants, participant)
+	}
+	return participants, nil
+}
+
+// fillParticipants loads the participants with their streaks and copies the
+// numbers of the first two players into the 1v1 fields of the duel.
+func (r *Repository) fillParticipants(q queryer, duel *models.DuelDb) error {
+	participants, err := findDuelParticipants(q, duel.Id)
+	if err != nil {
+		return err
+	}
+
+	for i := range participants {
+		participant := &participants[i]
+		participant.Streak, participant.BestStreak, err = r.findDuelStreak(q, participant.UserId, duel)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case participant.UserId == duel.User1_id:
+			duel.User1_completed = participant.Completed
+			duel.User1_volume = participant.Volume
+			duel.User1_streak = participant.Streak
+			duel.User1_bestStreak = participant.BestStreak
+		case duel.User2_id.Valid && participant.UserId == duel.User2_id.Int64:
+			duel.User2_completed = participant.Completed
+			duel.User2_volume = participant.Volume
+			duel.User2_streak = participant.Streak
+			duel.User2_bestStreak = participant.BestStreak
+		}
+	}
+	duel.Participants = participants
+
+	if duel.DuelType == models.DuelTypeTeam {
+		duel.Teams, err = findDuelTeams(q, duel.Id, duel.TeamScoring)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"database/sql"
+	"maxbot/internal/models"
+	"maxbot/internal/rating"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ratedParticipant struct {
+	player         rating.Player
+	categoryRating int
+}
+
+// applyRatings updates the overall and the habit category ratings of the
+// participants of an ended duel. Must be called after the places are stored.
+func (r *Repository) applyRatings(tx *sqlx.Tx, duelID int, forfeitedBy sql.NullInt64) error {
+	var category string
+	err := tx.QueryRow(
+		`SELECT habit_categories.name FROM duels
+		JOIN habits ON duels.habit_id = habits.id
+		JOIN habit_categories ON habits.habit_category_id = habit_categories.id
+		WHERE duels.id = $1`, duelID,
+	).Scan(&category)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(
+		`SELECT p.user_id, COALESCE(p.team_id, 0), COALESCE(p.place, 1), u.rating, COALESCE(ucr.rating, $3)
+		FROM duel_participants p
+		JOIN users u ON p.user_id = u.id
+		LEFT JOIN user_category_ratings ucr ON ucr.user_id = p.user_id AND ucr.category = $2
+		WHERE p.duel_id = $1
+		ORDER BY p.user_id
+		FOR UPDATE OF u`, duelID, category, rating.Initial,
+	)
+	if err != nil {
+		return err
+	}
+	var participants []ratedParticipant
+	for rows.Next() {
+		var participant ratedParticipant
+		err := rows.Scan(&participant.player.ID, &participant.player.Side, &participant.player.Place,
+			&participant.player.Rating, &participant.categoryRating)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		participants = append(participants, participant)
+	}
+	rows.Close()
+
+	overall := make([]rating.Player, len(participants))
+	byCategory := make([]rating.Player, len(participants))
+	bestPlace, allEqual := 0, true
+	for i, participant := range participants {
+		overall[i] = participant.player
+		byCategory[i] = participant.player
+		byCategory[i].Rating = participant.categoryRating
+		if i == 0 || participant.player.Place < bestPlace {
+			bestPlace = participant.player.Place
+		}
+		if participant.player.Place != participants[0].player.Place {
+			allEqual = false
+		}
+	}
+	newOverall := rating.Update(overall)
+	newByCategory := rating.Update(byCategory)
+
+	today := r.Clock.Today()
+	for _, participant := range participants {
+		id := participant.player.ID
+		result := models.RatingResultLoss
+		switch {
+		case forfeitedBy.Valid && forfeitedBy.Int64 == id:
+			result = models.RatingResultForfeit
+		case allEqual:
+			result = models.RatingResultDraw
+		case participant.player.Place == bestPlace:
+			result = models.RatingResultWin
+		}
+
+		if _, err := tx.Exec(`UPDATE users SET rating = $1 WHERE id = $2`, newOverall[id], id); err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`INSERT INTO user_category_ratings (user_id, category, rating) VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, category) DO UPDATE SET rating = EXCLUDED.rating`,
+			id, category, newByCategory[id],
+		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`INSERT INTO rating_history (user_id, duel_id, category, old_rating, new_rating, result, created_at)
+			VALUES ($1, $2, '', $3, $4, $6, $7), ($1, $2, $5, $8, $9, $6, $7)`,
+			id, duelID, participant.player.Rating, newOverall[id], category, result, today,
+			participant.categoryRating, newByCategory[id],
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) FindCategoryRatingsByUserId(user_id int64) ([]models.CategoryRatingDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT category, rating FROM user_category_ratings WHERE user_id = $1 ORDER BY rating DESC`, user_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ratings []models.CategoryRatingDb = []models.CategoryRatingDb{}
+	for rows.Next() {
+		categoryRating := models.CategoryRatingDb{}
+		if err := rows.Scan(&categoryRating.Category, &categoryRating.Rating); err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, categoryRating)
+	}
+	return ratings, nil
+}
+
+// FindRatingHistoryByUserId returns the latest rating changes, newest first.
+func (r *Repository) FindRatingHistoryByUserId(user_id int64, limit int) ([]models.RatingHistoryDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT duel_id, category, old_rating, new_rating, result, TO_CHAR(created_at, 'YYYY-MM-DD')
+		FROM rating_history WHERE user_id = $1
+		ORDER BY id DESC LIMIT $2`, user_id, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.RatingHistoryDb = []models.RatingHistoryDb{}
+	for rows.Next() {
+		change := models.RatingHistoryDb{}
+		err := rows.Scan(&change.DuelId, &change.Category, &change.OldRating, &change.NewRating,
+			&change.Result, &change.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+	return history, nil
+}
+
+// FindUserSkillRating returns the rating in the habit category, or the overall
+// rating when the category is empty or the user has not played in it yet.
+func (r *Repository) FindUserSkillRating(user_id int64, category string) (int, error) {
+	var skill int
+	err := r.Db.QueryRow(
+		`SELECT COALESCE(
+			(SELECT rating FROM user_category_ratings WHERE user_id = $1 AND category = $2),
+			(SELECT rating FROM users WHERE id = $1)
+		)`, user_id, category,
+	).Scan(&skill)
+	return skill, err
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/models"
+)
+
+// FindLogOwner returns the author of the log.
+func (r *Repository) FindLogOwner(log_id int64) (int64, error) {
+	var ownerId int64
+	err := r.Db.QueryRow(`SELECT owner_id FROM logs WHERE id = $1 AND NOT hidden`, log_id).Scan(&ownerId)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("log does not exist")
+	}
+	return ownerId, err
+}
+
+func (r *Repository) CreateReport(report *models.ReportDb) (int64, error) {
+	var id int64
+	err := r.Db.QueryRow(
+		`INSERT INTO reports (reporter_id, user_id, log_id, reason, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (reporter_id, user_id, COALESCE(log_id, 0)) WHERE status = 'open' DO NOTHING
+		RETURNING id`,
+		report.ReporterId, report.UserId, report.LogId, report.Reason, report.Details, r.Clock.Today(),
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("you have already reported this, the report is waiting for review")
+	}
+	return id, err
+}
+
+// FindReports returns reports with the given status, the oldest first.
+func (r *Repository) FindReports(status string, limit int) ([]models.ReportDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT reports.id, reports.reporter_id, reporter.first_name, reports.user_id, reported.first_name,
+		reported.suspended, reports.log_id, logs.message, COALESCE(logs.hidden, false), reports.reason,
+		reports.details, reports.status, reports.action, reports.resolved_by, TO_CHAR(reports.created_at, 'YYYY-MM-DD')
+		FROM reports
+		JOIN users reporter ON reports.reporter_id = reporter.id
+		JOIN users reported ON reports.user_id = reported.id
+		LEFT JOIN logs ON reports.log_id = logs.id
+		WHERE reports.status = $1
+		ORDER BY reports.id
+		LIMIT $2`, status, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []models.ReportDb = []models.ReportDb{}
+	for rows.Next() {
+		report := models.ReportDb{}
+		err := rows.Scan(&report.Id, &report.ReporterId, &report.ReporterFirstName, &report.UserId, &report.UserFirstName,
+			&report.UserSuspended, &report.LogId, &report.LogMessage, &report.LogHidden, &report.Reason,
+			&report.Details, &report.Status, &report.Action, &report.ResolvedBy, &report.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// ResolveReport applies the admin's action. Hiding a log or suspending a user
+// also resolves the other open reports about the same log or user.
+func (r *Repository) ResolveReport(report_id int64, admin_id int64, action string) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var userId int64
+	var logId sql.NullInt64
+	var status string
+	err = tx.QueryRow(
+		`SELECT user_id, log_id, status FROM reports WHERE id = $1 FOR UPDATE`, report_id,
+	).Scan(&userId, &logId, &status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("report does not exist")
+		}
+		return err
+	}
+	if status != models.ReportStatusOpen {
+		return errors.New("report has already been resolved")
+	}
+
+	resolve := `UPDATE reports SET status = $1, action = $2, resolved_by = $3 WHERE id = $4`
+	var target any = report_id
+	switch action {
+	case models.ReportActionHideLog:
+		if !logId.Valid {
+			return errors.New("report is not about a log")
+		}
+		if _, err := tx.Exec(`UPDATE logs SET hidden = true WHERE id = $1`, logId.Int64); err != nil {
+			return err
+		}
+		resolve = `UPDATE reports SET status = $1, action = $2, resolved_by = $3 WHERE log_id = $4 AND status = 'open'`
+		target = logId.Int64
+	case models.ReportActionSuspendUser:
+		if _, err := tx.Exec(`UPDATE users SET suspended = true WHERE id = $1`, userId); err != nil {
+			return err
+		}
+		resolve = `UPDATE reports SET status = $1, action = $2, resolved_by = $3 WHERE user_id = $4 AND status = 'open'`
+		target = userId
+	case models.ReportActionDismiss:
+	default:
+		return errors.New("action should be one of: hide_log, suspend_user, dismiss")
+	}
+
+	if _, err := tx.Exec(resolve, models.ReportStatusResolved, action, admin_id, target); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetUserSuspended suspends the account or lifts the suspension.
+func (r *Repository) SetUserSuspended(user_id int64, suspended bool) error {
+	res, err := r.Db.Exec(`UPDATE users SET suspended = $1 WHERE id = $2`, suspended, user_id)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("user does not exist")
+	}
+	return nil
+}
+
+// SetLogHidden hides the log from every timeline or shows it again.
+func (r *Repository) SetLogHidden(log_id int64, hidden bool) error {
+	res, err := r.Db.Exec(`UPDATE logs SET hidden = $1 WHERE id = $2`, hidden, log_id)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("log does not exist")
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 	"math/rand"
+	"maxbot/internal/calendar"
+	"maxbot/internal/clock"
 	"maxbot/internal/dto"
 	"maxbot/internal/models"
 	"os"
@@ -77,35 +79,514 @@ CREATE TABLE IF NOT EXISTS invitations(
 	FOREIGN KEY (duel_id) REFERENCES duels(id)
 );
 
+ALTER TABLE users ADD COLUMN IF NOT EXISTS streak_freezes INTEGER DEFAULT 0;
+CREATE TABLE IF NOT EXISTS streak_freezes(
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	frozen_date DATE NOT NULL,
+	used_at DATE NOT NULL
+);
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS target INTEGER;
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS schedule_type VARCHAR(16) NOT NULL DEFAULT 'daily';
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS schedule_weekdays INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS schedule_times_per_week INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE habits ADD COLUMN IF NOT EXISTS unit VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE habits ADD COLUMN IF NOT EXISTS daily_target NUMERIC;
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS value NUMERIC;
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS counted BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS scoring_mode VARCHAR(32) NOT NULL DEFAULT 'first_to_target';
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS max_participants INTEGER NOT NULL DEFAULT 2;
+CREATE TABLE IF NOT EXISTS duel_participants(
+	id SERIAL PRIMARY KEY,
+	duel_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (duel_id) REFERENCES duels(id),
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	completed INTEGER NOT NULL DEFAULT 0,
+	place INTEGER,
+	UNIQUE (duel_id, user_id)
+);
+
+-- 1v1 duels created before participants existed
+INSERT INTO duel_participants (duel_id, user_id, completed, place)
+SELECT id, user1_id, user1_completed,
+	CASE WHEN status_id <> (SELECT id FROM duel_status WHERE value = 'ended') THEN NULL WHEN winner_id IS NULL OR winner_id = user1_id THEN 1 ELSE 2 END
+FROM duels ORDER BY id
+ON CONFLICT (duel_id, user_id) DO NOTHING;
+INSERT INTO duel_participants (duel_id, user_id, completed, place)
+SELECT id, user2_id, user2_completed,
+	CASE WHEN status_id <> (SELECT id FROM duel_status WHERE value = 'ended') THEN NULL WHEN winner_id IS NULL OR winner_id = user2_id THEN 1 ELSE 2 END
+FROM duels WHERE user2_id IS NOT NULL ORDER BY id
+ON CONFLICT (duel_id, user_id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS teams(
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(64) NOT NULL,
+	owner_id INTEGER NOT NULL,
+	FOREIGN KEY (owner_id) REFERENCES users(id),
+	invitation_hash VARCHAR(64) UNIQUE NOT NULL
+);
+CREATE TABLE IF NOT EXISTS team_members(
+	id SERIAL PRIMARY KEY,
+	team_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (team_id) REFERENCES teams(id),
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	UNIQUE (team_id, user_id)
+);
+CREATE TABLE IF NOT EXISTS duel_teams(
+	id SERIAL PRIMARY KEY,
+	duel_id INTEGER NOT NULL,
+	team_id INTEGER NOT NULL,
+	FOREIGN KEY (duel_id) REFERENCES duels(id),
+	FOREIGN KEY (team_id) REFERENCES teams(id),
+	place INTEGER,
+	UNIQUE (duel_id, team_id)
+);
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS duel_type VARCHAR(16) NOT NULL DEFAULT 'individual';
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS team_scoring VARCHAR(16) NOT NULL DEFAULT 'combined';
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS winner_team_id INTEGER REFERENCES teams(id);
+ALTER TABLE duel_participants ADD COLUMN IF NOT EXISTS team_id INTEGER REFERENCES teams(id);
+CREATE TABLE IF NOT EXISTS lobby_entries(
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	duel_id INTEGER UNIQUE NOT NULL,
+	FOREIGN KEY (duel_id) REFERENCES duels(id),
+	habit_category VARCHAR(255) NOT NULL,
+	days INTEGER NOT NULL,
+	rating INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS lobby_entries_category_days_idx ON lobby_entries (habit_category, days);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS rating INTEGER NOT NULL DEFAULT 1000;
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS forfeited_by INTEGER REFERENCES users(id);
+CREATE TABLE IF NOT EXISTS user_category_ratings(
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	category VARCHAR(255) NOT NULL,
+	rating INTEGER NOT NULL,
+	UNIQUE (user_id, category)
+);
+CREATE TABLE IF NOT EXISTS rating_history(
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	duel_id INTEGER NOT NULL,
+	FOREIGN KEY (duel_id) REFERENCES duels(id),
+	category VARCHAR(255) NOT NULL DEFAULT '',
+	old_rating INTEGER NOT NULL,
+	new_rating INTEGER NOT NULL,
+	result VARCHAR(16) NOT NULL,
+	created_at DATE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS rating_history_user_idx ON rating_history (user_id, id);
+CREATE TABLE IF NOT EXISTS leaderboard_stats(
+	user_id INTEGER PRIMARY KEY,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	wins_week INTEGER NOT NULL DEFAULT 0,
+	wins_month INTEGER NOT NULL DEFAULT 0,
+	wins_all INTEGER NOT NULL DEFAULT 0,
+	completions_week INTEGER NOT NULL DEFAULT 0,
+	completions_month INTEGER NOT NULL DEFAULT 0,
+	completions_all INTEGER NOT NULL DEFAULT 0,
+	streak INTEGER NOT NULL DEFAULT 0,
+	rating INTEGER NOT NULL DEFAULT 1000,
+	refreshed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS logs_owner_created_idx ON logs (owner_id, created_at);
+CREATE INDEX IF NOT EXISTS duel_participants_user_idx ON duel_participants (user_id);
+CREATE TABLE IF NOT EXISTS seasons(
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(64) NOT NULL,
+	start_date DATE NOT NULL,
+	end_date DATE NOT NULL,
+	status VARCHAR(16) NOT NULL
+);
+CREATE TABLE IF NOT EXISTS season_standings(
+	id SERIAL PRIMARY KEY,
+	season_id INTEGER NOT NULL,
+	FOREIGN KEY (season_id) REFERENCES seasons(id),
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	rank INTEGER NOT NULL,
+	wins INTEGER NOT NULL,
+	duels_played INTEGER NOT NULL,
+	completions INTEGER NOT NULL,
+	reward VARCHAR(32) NOT NULL DEFAULT '',
+	UNIQUE (season_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS duels_end_date_idx ON duels (end_date);
+CREATE TABLE IF NOT EXISTS user_achievements(
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	code VARCHAR(32) NOT NULL,
+	awarded_at DATE NOT NULL,
+	UNIQUE (user_id, code)
+);
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS stake INTEGER NOT NULL DEFAULT 0;
+CREATE TABLE IF NOT EXISTS coin_accounts(
+	id SERIAL PRIMARY KEY,
+	kind VARCHAR(16) NOT NULL,
+	user_id INTEGER UNIQUE REFERENCES users(id),
+	duel_id INTEGER UNIQUE REFERENCES duels(id),
+	name VARCHAR(32) UNIQUE,
+	balance BIGINT NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS coin_transactions(
+	id SERIAL PRIMARY KEY,
+	reason VARCHAR(16) NOT NULL,
+	duel_id INTEGER REFERENCES duels(id),
+	created_at DATE NOT NULL
+);
+CREATE TABLE IF NOT EXISTS coin_entries(
+	id SERIAL PRIMARY KEY,
+	transaction_id INTEGER NOT NULL,
+	FOREIGN KEY (transaction_id) REFERENCES coin_transactions(id),
+	account_id INTEGER NOT NULL,
+	FOREIGN KEY (account_id) REFERENCES coin_accounts(id),
+	amount BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS coin_entries_account_idx ON coin_entries (account_id, transaction_id);
+CREATE INDEX IF NOT EXISTS coin_entries_transaction_idx ON coin_entries (transaction_id);
+CREATE INDEX IF NOT EXISTS coin_transactions_duel_idx ON coin_transactions (duel_id);
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS rematch_of INTEGER REFERENCES duels(id);
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS invited_user_id INTEGER REFERENCES users(id);
+CREATE INDEX IF NOT EXISTS duel_participants_user_duel_idx ON duel_participants (user_id, duel_id);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS visibility VARCHAR(16) NOT NULL DEFAULT 'everyone';
+CREATE TABLE IF NOT EXISTS friendships(
+	id SERIAL PRIMARY KEY,
+	requester_id INTEGER NOT NULL,
+	FOREIGN KEY (requester_id) REFERENCES users(id),
+	addressee_id INTEGER NOT NULL,
+	FOREIGN KEY (addressee_id) REFERENCES users(id),
+	status VARCHAR(16) NOT NULL,
+	created_at DATE NOT NULL,
+	accepted_at DATE
+);
+-- One friendship or request per pair of users, whoever sent it
+CREATE UNIQUE INDEX IF NOT EXISTS friendships_pair_idx
+	ON friendships (LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id));
+CREATE INDEX IF NOT EXISTS friendships_addressee_idx ON friendships (addressee_id);
+CREATE TABLE IF NOT EXISTS user_blocks(
+	id SERIAL PRIMARY KEY,
+	blocker_id INTEGER NOT NULL,
+	FOREIGN KEY (blocker_id) REFERENCES users(id),
+	blocked_id INTEGER NOT NULL,
+	FOREIGN KEY (blocked_id) REFERENCES users(id),
+	created_at DATE NOT NULL,
+	UNIQUE (blocker_id, blocked_id)
+);
+CREATE INDEX IF NOT EXISTS user_blocks_blocked_idx ON user_blocks (blocked_id);
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended BOOLEAN NOT NULL DEFAULT false;
+CREATE TABLE IF NOT EXISTS reports(
+	id SERIAL PRIMARY KEY,
+	reporter_id INTEGER NOT NULL,
+	FOREIGN KEY (reporter_id) REFERENCES users(id),
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	log_id INTEGER,
+	FOREIGN KEY (log_id) REFERENCES logs(id),
+	reason VARCHAR(32) NOT NULL,
+	details TEXT NOT NULL DEFAULT '',
+	status VARCHAR(16) NOT NULL DEFAULT 'open',
+	action VARCHAR(16),
+	resolved_by INTEGER,
+	FOREIGN KEY (resolved_by) REFERENCES users(id),
+	created_at DATE NOT NULL
+);
+-- One open report per reporter and target
+CREATE UNIQUE INDEX IF NOT EXISTS reports_open_idx
+	ON reports (reporter_id, user_id, COALESCE(log_id, 0)) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS reports_status_idx ON reports (status, id);
+CREATE TABLE IF NOT EXISTS log_disputes(
+	id SERIAL PRIMARY KEY,
+	log_id INTEGER NOT NULL,
+	FOREIGN KEY (log_id) REFERENCES logs(id),
+	duel_id INTEGER NOT NULL,
+	FOREIGN KEY (duel_id) REFERENCES duels(id),
+	disputer_id INTEGER NOT NULL,
+	FOREIGN KEY (disputer_id) REFERENCES users(id),
+	reason TEXT NOT NULL,
+	excluded BOOLEAN NOT NULL DEFAULT false,
+	status VARCHAR(16) NOT NULL DEFAULT 'open',
+	resolution VARCHAR(16),
+	resolved_by INTEGER,
+	FOREIGN KEY (resolved_by) REFERENCES users(id),
+	created_at DATE NOT NULL,
+	resolved_at DATE
+);
+-- One open dispute per log
+CREATE UNIQUE INDEX IF NOT EXISTS log_disputes_open_idx ON log_disputes (log_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS log_disputes_duel_idx ON log_disputes (duel_id);
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS proof_photo_required BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS proof_min_message_length INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS proof_not_before VARCHAR(5) NOT NULL DEFAULT '';
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS proof_not_after VARCHAR(5) NOT NULL DEFAULT '';
+ALTER TABLE duels ADD COLUMN IF NOT EXISTS proof_timezone VARCHAR(64) NOT NULL DEFAULT '';
+CREATE TABLE IF NOT EXISTS log_reactions(
+	log_id INTEGER NOT NULL,
+	FOREIGN KEY (log_id) REFERENCES logs(id),
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	emoji VARCHAR(16) NOT NULL,
+	PRIMARY KEY (log_id, user_id)
+);
+CREATE TABLE IF NOT EXISTS log_comments(
+	id SERIAL PRIMARY KEY,
+	log_id INTEGER NOT NULL,
+	FOREIGN KEY (log_id) REFERENCES logs(id),
+	parent_id INTEGER,
+	FOREIGN KEY (parent_id) REFERENCES log_comments(id),
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	message TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS log_comments_log_idx ON log_comments (log_id, id);
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS logged_at TIMESTAMPTZ;
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS streak_before INTEGER;
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS streak_freezes_before INTEGER;
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS last_contributed_before DATE;
+ALTER TABLE coin_transactions ADD COLUMN IF NOT EXISTS reversed BOOLEAN NOT NULL DEFAULT false;
+CREATE TABLE IF NOT EXISTS log_backfills(
+	id SERIAL PRIMARY KEY,
+	duel_id INTEGER NOT NULL,
+	FOREIGN KEY (duel_id) REFERENCES duels(id),
+	owner_id INTEGER NOT NULL,
+	FOREIGN KEY (owner_id) REFERENCES users(id),
+	day DATE NOT NULL,
+	message TEXT NOT NULL,
+	photo BYTEA,
+	value NUMERIC,
+	status VARCHAR(16) NOT NULL DEFAULT 'pending',
+	resolved_by INTEGER,
+	FOREIGN KEY (resolved_by) REFERENCES users(id),
+	log_id INTEGER,
+	FOREIGN KEY (log_id) REFERENCES logs(id),
+	created_at DATE NOT NULL,
+	resolved_at DATE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS log_backfills_pending_idx ON log_backfills (duel_id, owner_id, day) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS log_backfills_duel_idx ON log_backfills (duel_id, id);
+CREATE TABLE IF NOT EXISTS tournaments(
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(64) NOT NULL,
+	organizer_id INTEGER NOT NULL,
+	FOREIGN KEY (organizer_id) REFERENCES users(id),
+	habit_id INTEGER NOT NULL,
+	FOREIGN KEY (habit_id) REFERENCES habits(id),
+	days INTEGER NOT NULL,
+	scoring_mode VARCHAR(32) NOT NULL,
+	format VARCHAR(32) NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	invitation_hash VARCHAR(64) UNIQUE NOT NULL,
+	winner_id INTEGER REFERENCES users(id)
+);
+CREATE TABLE IF NOT EXISTS tournament_players(
+	id SERIAL PRIMARY KEY,
+	tournament_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (tournament_id) REFERENCES tournaments(id),
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	seed INTEGER NOT NULL,
+	UNIQUE (tournament_id, user_id)
+);
+CREATE TABLE IF NOT EXISTS tournament_matches(
+	id SERIAL PRIMARY KEY,
+	tournament_id INTEGER NOT NULL,
+	FOREIGN KEY (tournament_id) REFERENCES tournaments(id),
+	round INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	player1_id INTEGER REFERENCES users(id),
+	player2_id INTEGER REFERENCES users(id),
+	duel_id INTEGER UNIQUE REFERENCES duels(id),
+	winner_id INTEGER REFERENCES users(id),
+	finished BOOLEAN NOT NULL DEFAULT FALSE,
+	UNIQUE (tournament_id, round, position)
+);
+
 INSERT INTO duel_status (value) SELECT 'invited' WHERE NOT EXISTS (SELECT 1 FROM duel_status WHERE value = 'invited');
 INSERT INTO duel_status (value) SELECT 'active' WHERE NOT EXISTS (SELECT 1 FROM duel_status WHERE value = 'active');
 INSERT INTO duel_status (value) SELECT 'ended' WHERE NOT EXISTS (SELECT 1 FROM duel_status WHERE value = 'ended');
+INSERT INTO duel_status (value) SELECT 'declined' WHERE NOT EXISTS (SELECT 1 FROM duel_status WHERE value = 'declined');
+INSERT INTO duel_status (value) SELECT 'cancelled' WHERE NOT EXISTS (SELECT 1 FROM duel_status WHERE value = 'cancelled');
 `
 
+// Duel statuses are seeded in this order, so their ids are fixed. Index
+// predicates cannot look a status up by value and use these ids; New checks
+// that the seeded rows match them.
+const (
+	duelStatusInvited   = 1
+	duelStatusActive    = 2
+	duelStatusEnded     = 3
+	duelStatusDeclined  = 4
+	duelStatusCancelled = 5
+)
+
+var duelStatusValues = map[int]string{
+	duelStatusInvited:   "invited",
+	duelStatusActive:    "active",
+	duelStatusEnded:     "ended",
+	duelStatusDeclined:  "declined",
+	duelStatusCancelled: "cancelled",
+}
+
+var duelStatusIndexes = fmt.Sprintf(`
+CREATE INDEX IF NOT EXISTS duels_invited_user_idx ON duels (invited_user_id) WHERE status_id = %d;
+-- A duel has at most one rematch that was not declined or cancelled
+DROP INDEX IF EXISTS duels_rematch_of_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS duels_rematch_of_open_idx ON duels (rematch_of) WHERE status_id NOT IN (%d, %d);
+`, duelStatusInvited, duelStatusDeclined, duelStatusCancelled)
+
+// mustCheckDuelStatuses panics if a seeded duel status does not have the id of its constant.
+func mustCheckDuelStatuses(db *sqlx.DB) {
+	for id, value := range duelStatusValues {
+		var seededId int
+		if err := db.QueryRow(`SELECT id FROM duel_status WHERE value = $1`, value).Scan(&seededId); err != nil {
+			panic(fmt.Sprintf("duel status %q is not seeded: %v", value, err))
+		}
+		if seededId != id {
+			panic(fmt.Sprintf("duel status %q has id %d, expected %d", value, seededId, id))
+		}
+	}
+}
+
 type RepositoryInterface interface {
 	CreateUser(maxID string, firstName string, photoUrl string) (*models.UserDb, error)
 	FindUserByMaxId(maxID string) (*models.UserDb, error)
 	FindUserById(id int64) (*models.UserDb, error)
-	CreateHabit(user_id int64, habit_name string, habit_category string) error
+	CreateHabit(user_id int64, habit_name string, habit_category string, unit string, dailyTarget float64) error
 	FindHabitsByUserId(user_id int64) ([]dto.HabitDto, error)
-	CreateDuel(user_id int64, habit_id int, random_hash string, days int) error
-	ActivateDuelFromInvitationHash(user_id int64, invitationHash string) error
+	FindHabitById(habit_id int) (*dto.HabitDto, error)
+	CreateDuel(user_id int64, habit_id int, random_hash string, settings models.DuelSettings) error
+	ActivateDuelFromInvitationHash(user_id int64, invitationHash string, target int) (bool, error)
+	StartDuel(duel_id int64, target int) error
+	FindDuelParticipants(duel_id int) ([]models.ParticipantDb, error)
 	GetDuelById(duel_id int64) (*models.DuelDb, error)
+	FindDuelByInvitationHash(invitationHash string) (*models.DuelDb, error)
+	FindActiveDuels() ([]models.DuelDb, error)
 	FindDuelLogsByUser(user_id int64) ([]dto.LogDto, error)
 	FindDuelLogsByDuelId(duel_id int64) ([]dto.LogDto, error)
 	CreateDuelLog(log *models.LogDB) error
+	UpdateLog(log_id int64, message string, photo *[]byte) error
+	DeleteLog(log_id int64, dailyTarget float64, decide DuelDecider) (bool, error)
 	FindDuelsByUserId(user_id int64) ([]models.DuelDb, error)
-	IncrementDuelCounter(duel *models.DuelDb, user_id int64) (bool, error)
+	IncrementDuelCounter(duel *models.DuelDb, user_id int64) (int64, error)
 	IncrementUserStreakAndUpdateLastTimeContributed(user *models.UserDb) error
 	ResetUserStreakToOneAndUpdateLastTimeContributed(user *models.UserDb) error
-	IncrementWinCounter(user *models.UserDb) error
+	UseStreakFreezesAndIncrementUserStreak(user *models.UserDb, missedDays []string) error
+	AwardStreakFreeze(user *models.UserDb) error
+	FindFrozenDaysByUserId(userID int64) ([]string, error)
+	EndDuel(duelID int, winnerID sql.NullInt64, endDate string, places map[int64]int, forfeitedBy sql.NullInt64) error
+	CountUserDuelLogsBetween(userID int64, duelID int, from string, to string) (int, error)
+	FindCountedDays(userID int64, duelID int64) ([]string, error)
 	HasUserContributedToDuelToday(userID int64, duelID int64, date string) (bool, error)
+	SumUserDuelValueOnDate(userID int64, duelID int64, date string) (float64, error)
+	FindHabitStreak(userID int64, habitID int) (int, int, error)
+	FindDuelStreak(userID int64, duelID int) (int, int, error)
+	CreateTeam(owner_id int64, name string, invitationHash string) (int64, error)
+	FindTeamById(team_id int64) (*models.TeamDb, error)
+	JoinTeamByInvitationHash(user_id int64, invitationHash string) error
+	LeaveTeam(user_id int64, team_id int64) error
+	IsTeamMember(user_id int64, team_id int64) (bool, error)
+	FindTeamMembers(team_id int64) ([]dto.TeamMemberDto, error)
+	FindTeamsByUserId(user_id int64) ([]models.TeamDb, error)
+	ActivateTeamDuelFromInvitationHash(team_id int64, invitationHash string, target int) error
+	FindDuelTeams(duel_id int, teamScoring string) ([]models.DuelTeamDb, error)
+	EndTeamDuel(duelID int, winnerTeamID sql.NullInt64, endDate string, places map[int64]int) error
+	CreateTournament(tournament *models.TournamentDb) (int64, error)
+	FindTournamentById(tournament_id int64) (*models.TournamentDb, error)
+	JoinTournamentByInvitationHash(user_id int64, invitationHash string, maxPlayers int) error
+	FindTournamentPlayers(tournament_id int64) ([]models.TournamentPlayerDb, error)
+	StartTournament(tournament_id int64, matches []models.TournamentMatchDb) error
+	FindTournamentMatches(tournament_id int64) ([]models.TournamentMatchDb, error)
+	FindTournamentIdByDuelId(duel_id int) (int64, error)
+	StartTournamentMatchDuel(match_id int64, player1_id int64, player2_id int64, habit_id int, settings models.DuelSettings, target int) error
+	CompleteTournamentMatch(match_id int64, winnerID sql.NullInt64) error
+	FindActiveTournamentIds() ([]int64, error)
+	EndTournament(tournament_id int64, winnerID sql.NullInt64) error
+	FindUserSkillRating(user_id int64, category string) (int, error)
+	FindCategoryRatingsByUserId(user_id int64) ([]models.CategoryRatingDb, error)
+	FindRatingHistoryByUserId(user_id int64, limit int) ([]models.RatingHistoryDb, error)
+	RefreshLeaderboardStats() error
+	CreateSeason(name string, startDate string, endDate string) (int64, error)
+	FindSeasons() ([]models.SeasonDb, error)
+	FindSeasonById(season_id int64) (*models.SeasonDb, error)
+	FindCurrentSeason() (*models.SeasonDb, error)
+	FindSeasonsToClose() ([]models.SeasonDb, error)
+	FindLiveSeasonStandings(season *models.SeasonDb, limit int) ([]models.SeasonStandingDb, error)
+	FindUserSeasonStanding(season *models.SeasonDb, user_id int64) (*models.SeasonStandingDb, error)
+	FindArchivedSeasonStandings(season_id int64, limit int) ([]models.SeasonStandingDb, error)
+	CloseSeason(season_id int64, standings []models.SeasonStandingDb) error
+	FindAchievementStats(user_id int64, comebackDeficit int) (*models.AchievementStatsDb, error)
+	AwardAchievements(user_id int64, codes []string) error
+	FindAchievementsByUserId(user_id int64) ([]models.UserAchievementDb, error)
+	FindAllUserIds() ([]int64, error)
+	RewardCoins(user_id int64, amount int64, reason string, duel_id int64) error
+	FindCoinBalance(user_id int64) (int64, error)
+	FindCoinHistory(user_id int64, limit int) ([]models.CoinEntryDb, error)
+	AuditCoinLedger() (*dto.CoinAuditDto, error)
+	FindDirectInvitations(user_id int64) ([]models.DuelDb, error)
+	FindInvitationHashByDuelId(duel_id int64) (string, error)
+	HasRematch(duel_id int64) (bool, error)
+	FindHeadToHeadDuels(user_id int64, opponent_id int64) ([]models.HeadToHeadDuelDb, error)
+	SendFriendRequest(user_id int64, other_id int64) (string, error)
+	AcceptFriendRequest(user_id int64, requester_id int64) error
+	RemoveFriend(user_id int64, other_id int64) error
+	AreFriends(user_id int64, other_id int64) (bool, error)
+	FindFriends(user_id int64) ([]models.FriendDb, error)
+	FindFriendRequests(user_id int64) ([]models.FriendRequestDb, error)
+	FindFriendSuggestions(user_id int64, limit int) ([]models.FriendSuggestionDb, error)
+	BlockUser(user_id int64, other_id int64) error
+	UnblockUser(user_id int64, other_id int64) error
+	FindBlockedUsers(user_id int64) ([]models.BlockedUserDb, error)
+	IsBlocked(user_id int64, other_id int64) (bool, error)
+	SetVisibility(user_id int64, visibility string) error
+	FindVisibility(user_id int64) (string, error)
+	FindActiveDuelsByUserId(user_id int64) ([]models.DuelDb, error)
+	DeclineDuel(duel_id int64, user_id int64) error
+	CancelInvitedDuel(duel_id int64, user_id int64) error
+	FindLogOwner(log_id int64) (int64, error)
+	CreateReport(report *models.ReportDb) (int64, error)
+	FindReports(status string, limit int) ([]models.ReportDb, error)
+	ResolveReport(report_id int64, admin_id int64, action string) error
+	SetUserSuspended(user_id int64, suspended bool) error
+	SetLogHidden(log_id int64, hidden bool) error
+	FindLogById(log_id int64) (*models.LogDB, error)
+	CreateDispute(dispute *models.DisputeDb) (int64, error)
+	FindDisputeById(dispute_id int64) (*models.DisputeDb, error)
+	ResolveDispute(dispute_id int64, status string, resolution string, resolved_by int64, decide DuelDecider) (bool, bool, error)
+	FindDisputesByDuelId(duel_id int64) ([]models.DisputeDb, error)
+	FindOpenDisputes(limit int) ([]models.DisputeDb, error)
+	SetReaction(log_id int64, user_id int64, emoji string) error
+	FindDuelReactions(duel_id int64, viewer_id int64) ([]models.LogReactionDb, error)
+	CountDuelComments(duel_id int64) (map[int64]int, error)
+	CreateComment(comment *models.LogCommentDb) (int64, error)
+	FindCommentById(comment_id int64) (*models.LogCommentDb, error)
+	FindLogComments(log_id int64) ([]models.LogCommentDb, error)
+	CreateBackfill(backfill *models.BackfillDb) (int64, error)
+	FindBackfillById(backfill_id int64) (*models.BackfillDb, error)
+	FindBackfillsByDuelId(duel_id int64) ([]models.BackfillDb, error)
+	RejectBackfill(backfill_id int64, resolved_by int64) error
+	ApproveBackfill(backfill_id int64, resolved_by int64, dailyTarget float64) (bool, error)
+	FindLeaderboard(column string, viewerID int64, hasCursor bool, afterScore int64, afterUserID int64, limit int) ([]models.LeaderboardEntryDb, error)
+	CreateLobbyEntry(entry *models.LobbyEntryDb) (int64, error)
+	FindLobbyEntryById(entry_id int64) (*models.LobbyEntryDb, error)
+	FindLobbyEntries(viewer_id int64, category string, days int) ([]models.LobbyEntryDb, error)
+	JoinLobbyEntry(user_id int64, entry_id int64, target int) error
+	CancelLobbyEntry(user_id int64, entry_id int64) error
 	CreateTestData() error
 	Stop()
 }
 
 type Repository struct {
-	Db *sqlx.DB
+	Db    *sqlx.DB
+	Clock clock.Clock
 }
 
 func New() *Repository {
@@ -115,11 +596,13 @@ func New() *Repository {
 	)
 	db, err := sqlx.Connect("postgres", connectionString)
 	if err != nil {
-		slog.Error("error while connecting to db: ", err.Error())
+		slog.Error("error while connecting to db", "error", err.Error())
 	}
 	db.MustExec(schema)
+	mustCheckDuelStatuses(db)
+	db.MustExec(duelStatusIndexes)
 
-	return &Repository{Db: db}
+	return &Repository{Db: db, Clock: clock.Real{}}
 }
 
 var _ RepositoryInterface = &Repository{}
@@ -129,10 +612,10 @@ func (r *Repository) CreateUser(maxID string, firstName string, photoUrl string)
 	query := `
 		INSERT INTO users (max_id, first_name, photo_url, streak, wins) 
 		VALUES ($1, $2, $3, $4, $5) 
-		RETURNING id, max_id, streak, wins, last_time_contributed
+		RETURNING id, max_id, streak, wins, last_time_contributed, streak_freezes, rating
 	`
 	err := r.Db.QueryRow(query, maxID, firstName, photoUrl, 0, 0).Scan(
-		&user.ID, &user.MaxID, &user.Streak, &user.Wins, &user.LastTimeContributed,
+		&user.ID, &user.MaxID, &user.Streak, &user.Wins, &user.LastTimeContributed, &user.StreakFreezes, &user.Rating,
 	)
 	if err != nil {
 		return nil, err
@@ -144,11 +627,11 @@ func (r *Repository) FindUserByMaxId(maxID string) (*models.UserDb, error) {
 	var user models.UserDb
 	err := r.Db.QueryRow(`
 		SELECT id, max_id, first_name, photo_url, streak, 
-		wins, TO_CHAR(last_time_contributed, 'YYYY-MM-DD') 
+		wins, TO_CHAR(last_time_contributed, 'YYYY-MM-DD'), streak_freezes, rating, suspended
 		FROM users 
 		WHERE max_id = $1
 	`, maxID).Scan(&user.ID, &user.MaxID, &user.FirstName, &user.PhotoUrl, &user.Streak,
-		&user.Wins, &user.LastTimeContributed)
+		&user.Wins, &user.LastTimeContributed, &user.StreakFreezes, &user.Rating, &user.Suspended)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -163,11 +646,11 @@ func (r *Repository) FindUserById(id int64) (*models.UserDb, error) {
 	var user models.UserDb
 	err := r.Db.QueryRow(`
 		SELECT id, max_id, first_name, photo_url, streak,
-		wins, TO_CHAR(last_time_contributed, 'YYYY-MM-DD') 
+		wins, TO_CHAR(last_time_contributed, 'YYYY-MM-DD'), streak_freezes, rating, suspended
 		FROM users 
 		WHERE id = $1
 	`, id).Scan(&user.ID, &user.MaxID, &user.FirstName, &user.PhotoUrl, &user.Streak,
-		&user.Wins, &user.LastTimeContributed)
+		&user.Wins, &user.LastTimeContributed, &user.StreakFreezes, &user.Rating, &user.Suspended)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -183,7 +666,7 @@ func (r *Repository) Stop() {
 	r.Db.Close()
 }
 
-func (r *Repository) CreateHabit(user_id int64, habit_name string, habit_category string) error {
+func (r *Repository) CreateHabit(user_id int64, habit_name string, habit_category string, unit string, dailyTarget float64) error {
 	res := r.Db.QueryRow(`SELECT id FROM habit_categories WHERE user_id = $1 AND name = $2`, user_id, habit_category)
 	var categoryId int64
 	err := res.Scan(&categoryId)
@@ -198,8 +681,9 @@ func (r *Repository) CreateHabit(user_id int64, habit_name string, habit_categor
 		return res.Err()
 	}
 	_, err = r.Db.Exec(`
-		INSERT INTO habits (user_id, habit_category_id, name) VALUES ($1, $2, $3)
-	`, user_id, categoryId, habit_name)
+		INSERT INTO habits (user_id, habit_category_id, name, unit, daily_target)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0))
+	`, user_id, categoryId, habit_name, unit, dailyTarget)
 	if err != nil {
 		return err
 	}
@@ -208,7 +692,8 @@ func (r *Repository) CreateHabit(user_id int64, habit_name string, habit_categor
 
 func (r *Repository) FindHabitsByUserId(user_id int64) ([]dto.HabitDto, error) {
 	rows, err := r.Db.Query(
-		`SELECT h.id, h.name AS habit_name, hc.name AS category_name FROM habits h
+		`SELECT h.id, h.name AS habit_name, hc.name AS category_name,
+		h.unit, COALESCE(h.daily_target, 0) FROM habits h
 		JOIN habit_categories hc ON h.habit_category_id = hc.id
 		WHERE h.user_id = $1`,
 		user_id,
@@ -219,17 +704,40 @@ func (r *Repository) FindHabitsByUserId(user_id int64) ([]dto.HabitDto, error) {
 	var habits []dto.HabitDto = []dto.HabitDto{}
 	for rows.Next() {
 		habit := dto.HabitDto{}
-		rows.Scan(&habit.Id, &habit.Name, &habit.Category)
+		rows.Scan(&habit.Id, &habit.Name, &habit.Category, &habit.Unit, &habit.DailyTarget)
 		habits = append(habits, habit)
 	}
+	for i := range habits {
+		habits[i].Streak, habits[i].BestStreak, err = r.FindHabitStreak(user_id, habits[i].Id)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return habits, nil
 }
 
+func (r *Repository) FindHabitById(habit_id int) (*dto.HabitDto, error) {
+	var habit dto.HabitDto
+	err := r.Db.QueryRow(
+		`SELECT h.id, h.name, hc.name, h.unit, COALESCE(h.daily_target, 0) FROM habits h
+		JOIN habit_categories hc ON h.habit_category_id = hc.id
+		WHERE h.id = $1`,
+		habit_id,
+	).Scan(&habit.Id, &habit.Name, &habit.Category, &habit.Unit, &habit.DailyTarget)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("habit does not exist")
+		}
+		return nil, err
+	}
+	return &habit, nil
+}
+
 func (r *Repository) FindDuelLogsByUser(user_id int64) ([]dto.LogDto, error) {
 	// DEPRECATED - rewrite if you want to use this func
 	rows, err := r.Db.Query(
 		`SELECT id, owner_id, message, photo, duel_id,
-		TO_CHAR(created_at, 'YYYY-MM-DD') FROM logs WHERE owner_id = $1`, user_id,
+		TO_CHAR(created_at, 'YYYY-MM-DD') FROM logs WHERE owner_id = $1 AND NOT hidden`, user_id,
 	)
 
 	if err != nil {
@@ -248,11 +756,14 @@ func (r *Repository) FindDuelLogsByUser(user_id int64) ([]dto.LogDto, error) {
 
 func (r *Repository) FindDuelLogsByDuelId(duel_id int64) ([]dto.LogDto, error) {
 	rows, err := r.Db.Query(
-		`SELECT logs.id, logs.owner_id, users.max_id, logs.message, logs.photo,
-		logs.duel_id, TO_CHAR(logs.created_at, 'YYYY-MM-DD')
+		`SELECT logs.id, logs.owner_id, users.max_id, users.first_name, p.team_id, teams.name, logs.message, logs.photo,
+		logs.duel_id, TO_CHAR(logs.created_at, 'YYYY-MM-DD'), logs.value, logs.counted
 		FROM logs
 		JOIN users ON logs.owner_id = users.id
-		WHERE duel_id = $1`, duel_id,
+		LEFT JOIN duel_participants p ON p.duel_id = logs.duel_id AND p.user_id = logs.owner_id
+		LEFT JOIN teams ON p.team_id = teams.id
+		WHERE logs.duel_id = $1 AND NOT logs.hidden
+		ORDER BY logs.id`, duel_id,
 	)
 	if err != nil {
 		return nil, err
@@ -261,7 +772,8 @@ func (r *Repository) FindDuelLogsByDuelId(duel_id int64) ([]dto.LogDto, error) {
 	var logs []dto.LogDto = []dto.LogDto{}
 	for rows.Next() {
 		log := dto.LogDto{}
-		rows.Scan(&log.LogID, &log.OwnerID, &log.MaxID, &log.Message, &log.Photo, &log.DuelID, &log.CreatedAt)
+		rows.Scan(&log.LogID, &log.OwnerID, &log.MaxID, &log.OwnerFirstName, &log.TeamId, &log.TeamName,
+			&log.Message, &log.Photo, &log.DuelID, &log.CreatedAt, &log.Value, &log.Counted)
 		logs = append(logs, log)
 	}
 
@@ -270,216 +782,660 @@ func (r *Repository) FindDuelLogsByDuelId(duel_id int64) ([]dto.LogDto, error) {
 
 func (r *Repository) CreateDuelLog(log *models.LogDB) error {
 	query := `
-		INSERT INTO logs (owner_id, duel_id, message, photo)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO logs (owner_id, duel_id, message, photo, created_at, value, counted, logged_at,
+			streak_before, streak_freezes_before, last_contributed_before)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
 	`
-	_, err := r.Db.Exec(
+	var streakBefore, freezesBefore sql.NullInt64
+	var contributedBefore sql.NullString
+	if log.StreakBefore != nil {
+		streakBefore = sql.NullInt64{Int64: int64(log.StreakBefore.Streak), Valid: true}
+		freezesBefore = sql.NullInt64{Int64: int64(log.StreakBefore.StreakFreezes), Valid: true}
+		contributedBefore = log.StreakBefore.LastTimeContributed
+	}
+	return r.Db.QueryRow(
 		query,
 		log.OwnerID,
 		log.DuelID,
 		log.Message,
 		log.Photo,
-	)
-	return err
+		r.Clock.Today(),
+		log.Value,
+		log.Counted,
+		r.Clock.Now(),
+		streakBefore,
+		freezesBefore,
+		contributedBefore,
+	).Scan(&log.ID)
 }
 
-func (r *Repository) CreateDuel(user_id int64, habit_id int, random_hash string, days int) error {
+func (r *Repository) CreateDuel(user_id int64, habit_id int, random_hash string, settings models.DuelSettings) error {
 	var invitedStatusId int
 	err := r.Db.QueryRow(`SELECT id FROM duel_status WHERE value = 'invited'`).Scan(&invitedStatusId)
 	if err != nil {
 		return err
 	}
-	var duelId int
-	err = r.Db.QueryRow(
-		`INSERT INTO duels (duration, habit_id, user1_id, status_id) VALUES ($1, $2, $3, $4) RETURNING id`,
-		days, habit_id, user_id, invitedStatusId,
-	).Scan(&duelId)
+
+	tx, err := r.Db.Beginx()
 	if err != nil {
 		return err
 	}
-	_, err = r.Db.Exec(
+	defer tx.Rollback()
+
+	duelId, err := r.insertDuel(tx, user_id, habit_id, invitedStatusId, settings)
+	if err != nil {
+		return err
+	}
+	if settings.TeamId != 0 {
+		err = addTeamToDuel(tx, duelId, settings.TeamId)
+	} else {
+		_, err = tx.Exec(`INSERT INTO duel_participants (duel_id, user_id) VALUES ($1, $2)`, duelId, user_id)
+	}
+	if err != nil {
+		return err
+	}
+	// Ставка создателя замораживается сразу, соперник вносит такую же при принятии
+	if err := r.escrowStake(tx, duelId, user_id); err != nil {
+		return err
+	}
+	_, err = tx.Exec(
 		`INSERT INTO invitations (generatedHash, duel_id) VALUES ($1, $2)`,
 		random_hash, duelId,
 	)
 	if err != nil {
 		return err
 	}
-	return nil
+	return tx.Commit()
 }
 
-func (r *Repository) ActivateDuelFromInvitationHash(user_id int64, invitationHash string) error {
+// insertDuel stores the duel row created by user_id with the given settings.
+func (r *Repository) insertDuel(tx *sqlx.Tx, user_id int64, habit_id int, status_id int, settings models.DuelSettings) (int64, error) {
+	duelType, teamScoring := models.DuelTypeIndividual, models.TeamScoringCombined
+	if settings.TeamId != 0 {
+		duelType, teamScoring = models.DuelTypeTeam, settings.TeamScoring
+	}
+
+	var duelId int64
+	err := tx.QueryRow(
+		`INSERT INTO duels (duration, habit_id, user1_id, status_id, start_date,
+		schedule_type, schedule_weekdays, schedule_times_per_week, scoring_mode, max_participants,
+		duel_type, team_scoring, stake, rematch_of, invited_user_id,
+		proof_photo_required, proof_min_message_length, proof_not_before, proof_not_after, proof_timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, 0), NULLIF($15, 0),
+		$16, $17, $18, $19, $20) RETURNING id`,
+		settings.Days, habit_id, user_id, status_id, r.Clock.Today(),
+		settings.Schedule.Type, weekdaysToMask(settings.Schedule.Weekdays), settings.Schedule.TimesPerWeek,
+		settings.ScoringMode, settings.MaxParticipants, duelType, teamScoring, settings.Stake,
+		settings.RematchOf, settings.InvitedUserId,
+		settings.ProofRules.PhotoRequired, settings.ProofRules.MinMessageLength, settings.ProofRules.NotBefore,
+		settings.ProofRules.NotAfter, settings.ProofRules.Timezone,
+	).Scan(&duelId)
+	return duelId, err
+}
+
+// ActivateDuelFromInvitationHash adds the user to the duel. Once the duel is full it
+// starts today; target is the number of scheduled check-ins needed to win.
+// Returns whether the duel was started by this join.
+func (r *Repository) ActivateDuelFromInvitationHash(user_id int64, invitationHash string, target int) (bool, error) {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	activated, err := r.joinDuelByInvitationHash(tx, user_id, invitationHash, target)
+	if err != nil {
+		return false, err
+	}
+	return activated, tx.Commit()
+}
+
+// joinDuelByInvitationHash is ActivateDuelFromInvitationHash inside the caller's transaction.
+func (r *Repository) joinDuelByInvitationHash(tx *sqlx.Tx, user_id int64, invitationHash string, target int) (bool, error) {
 	var duelId int64
 	var invitationId int
-	err := r.Db.QueryRow(`SELECT duel_id, id FROM invitations WHERE generatedHash = $1`,
+	err := tx.QueryRow(`SELECT duel_id, id FROM invitations WHERE generatedHash = $1`,
 		invitationHash).Scan(&duelId, &invitationId)
 	if err != nil {
-		return errors.New("invitation link has been expired or does not exist")
+		return false, errors.New("invitation link has been expired or does not exist")
+	}
+
+	// Lock the duel row so that concurrent joins cannot exceed the cap
+	var status, duelType string
+	var maxParticipants int
+	var invitedUserId sql.NullInt64
+	err = tx.QueryRow(
+		`SELECT duel_status.value, duels.max_participants, duels.duel_type, duels.invited_user_id FROM duels
+		JOIN duel_status ON duels.status_id = duel_status.id
+		WHERE duels.id = $1 FOR UPDATE OF duels`, duelId,
+	).Scan(&status, &maxParticipants, &duelType, &invitedUserId)
+	if err != nil {
+		return false, err
+	}
+	if status != "invited" {
+		return false, errors.New("duel is not for invitation")
+	}
+	if invitedUserId.Valid && invitedUserId.Int64 != user_id {
+		return false, errors.New("this invitation was sent to another user")
+	}
+	if duelType == models.DuelTypeTeam {
+		return false, errors.New("team duels are accepted on behalf of a team")
+	}
+
+	var alreadyJoined bool
+	var participants int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FILTER (WHERE user_id = $2) > 0, COUNT(*) FROM duel_participants WHERE duel_id = $1`,
+		duelId, user_id,
+	).Scan(&alreadyJoined, &participants)
+	if err != nil {
+		return false, err
+	}
+	if alreadyJoined {
+		return false, errors.New("you are already a participant of this duel")
+	}
+
+	var blocked bool
+	err = tx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM duel_participants p
+		JOIN user_blocks b ON (b.blocker_id = $2 AND b.blocked_id = p.user_id) OR (b.blocker_id = p.user_id AND b.blocked_id = $2)
+		WHERE p.duel_id = $1)`,
+		duelId, user_id,
+	).Scan(&blocked)
+	if err != nil {
+		return false, err
+	}
+	if blocked {
+		return false, errors.New("you cannot join a duel with a user you have blocked or who has blocked you")
+	}
+	if participants >= maxParticipants {
+		return false, errors.New("duel is full")
+	}
+
+	_, err = tx.Exec(`INSERT INTO duel_participants (duel_id, user_id) VALUES ($1, $2)`, duelId, user_id)
+	if err != nil {
+		return false, err
+	}
+	if err := r.escrowStake(tx, duelId, user_id); err != nil {
+		return false, err
+	}
+	_, err = tx.Exec(`UPDATE duels SET user2_id = $1 WHERE id = $2 AND user2_id IS NULL`, user_id, duelId)
+	if err != nil {
+		return false, err
 	}
 
-	duelDb, err := r.GetDuelById(duelId)
+	activated := participants+1 >= maxParticipants
+	if activated {
+		if err := r.activateDuel(tx, duelId, target); err != nil {
+			return false, err
+		}
+	}
+
+	return activated, nil
+}
+
+// StartDuel starts a duel that is still waiting for participants.
+func (r *Repository) StartDuel(duel_id int64, target int) error {
+	tx, err := r.Db.Beginx()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	if duelDb.Status != "invited" {
-		return errors.New("duel is not for invitation")
+	if err := r.activateDuel(tx, duel_id, target); err != nil {
+		return err
 	}
-	if duelDb.User1_id == user_id {
-		return errors.New("you cannot start a duel with yourself")
+	return tx.Commit()
+}
+
+// HasRematch reports whether the duel already has a rematch that was not declined or cancelled.
+func (r *Repository) HasRematch(duel_id int64) (bool, error) {
+	var exists bool
+	err := r.Db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM duels WHERE rematch_of = $1 AND status_id NOT IN (
+			SELECT id FROM duel_status WHERE value IN ('declined', 'cancelled')
+		))`, duel_id,
+	).Scan(&exists)
+	return exists, err
+}
+
+func (r *Repository) FindInvitationHashByDuelId(duel_id int64) (string, error) {
+	var invitationHash string
+	err := r.Db.QueryRow(`SELECT generatedHash FROM invitations WHERE duel_id = $1`, duel_id).Scan(&invitationHash)
+	if err != nil {
+		return "", errors.New("duel is not waiting for participants")
 	}
+	return invitationHash, nil
+}
 
-	_, err = r.Db.Exec(`UPDATE duels SET user2_id = $1, status_id = 2 WHERE id = $2`, user_id, duelId)
+// DeclineDuel closes a direct invitation refused by the invited user and returns the stakes.
+func (r *Repository) DeclineDuel(duel_id int64, user_id int64) error {
+	tx, err := r.Db.Beginx()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	_, err = r.Db.Exec(`DELETE FROM invitations WHERE id = $1`, invitationId)
+	res, err := tx.Exec(
+		`UPDATE duels SET status_id = (SELECT id FROM duel_status WHERE value = 'declined'), end_date = $1
+		WHERE id = $2 AND status_id = (SELECT id FROM duel_status WHERE value = 'invited') AND invited_user_id = $3`,
+		r.Clock.Today(), duel_id, user_id,
+	)
 	if err != nil {
 		return err
 	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("there is no pending invitation to this duel for you")
+	}
+	_, err = tx.Exec(`DELETE FROM invitations WHERE duel_id = $1`, duel_id)
+	if err != nil {
+		return err
+	}
+	if err := r.refundStakes(tx, duel_id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-	return nil
+// CancelInvitedDuel closes a duel nobody has accepted yet on behalf of its
+// creator and returns the stakes of everyone who has joined it.
+func (r *Repository) CancelInvitedDuel(duel_id int64, user_id int64) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.cancelInvitedDuel(tx, duel_id, user_id); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *Repository) GetDuelById(duel_id int64) (*models.DuelDb, error) {
+// cancelInvitedDuel is CancelInvitedDuel inside the caller's transaction.
+func (r *Repository) cancelInvitedDuel(tx *sqlx.Tx, duel_id int64, user_id int64) error {
+	res, err := tx.Exec(
+		`UPDATE duels SET status_id = (SELECT id FROM duel_status WHERE value = 'cancelled'), end_date = $1
+		WHERE id = $2 AND status_id = (SELECT id FROM duel_status WHERE value = 'invited') AND user1_id = $3`,
+		r.Clock.Today(), duel_id, user_id,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("there is no duel of yours waiting for an opponent")
+	}
+	for _, query := range []string{
+		`DELETE FROM invitations WHERE duel_id = $1`,
+		`DELETE FROM lobby_entries WHERE duel_id = $1`,
+	} {
+		if _, err := tx.Exec(query, duel_id); err != nil {
+			return err
+		}
+	}
+	return r.refundStakes(tx, duel_id)
+}
+
+func (r *Repository) activateDuel(tx *sqlx.Tx, duel_id int64, target int) error {
+	res, err := tx.Exec(
+		`UPDATE duels SET status_id = (SELECT id FROM duel_status WHERE value = 'active'), start_date = $1, target = $2
+		WHERE id = $3 AND status_id = (SELECT id FROM duel_status WHERE value = 'invited')`,
+		r.Clock.Today(), target, duel_id,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("duel is not for invitation")
+	}
+
+	_, err = tx.Exec(`DELETE FROM invitations WHERE duel_id = $1`, duel_id)
+	if err != nil {
+		return err
+	}
+	// The duel is no longer waiting for an opponent in the lobby
+	_, err = tx.Exec(`DELETE FROM lobby_entries WHERE duel_id = $1`, duel_id)
+	return err
+}
+
+const duelSelectQuery = `
+	SELECT duels.id, duels.duration, COALESCE(duels.target, duels.duration), duels.habit_id, habits.name,
+	habit_categories.name, duels.user1_id, duels.user2_id, u1.first_name,
+	u2.first_name, u1.photo_url, u2.photo_url, TO_CHAR(duels.start_date, 'YYYY-MM-DD'),
+	TO_CHAR(duels.end_date, 'YYYY-MM-DD'), duels.winner_id, duel_status.value,
+	duels.schedule_type, duels.schedule_weekdays, duels.schedule_times_per_week,
+	duels.scoring_mode, habits.unit, COALESCE(habits.daily_target, 0), duels.max_participants,
+	duels.duel_type, duels.team_scoring, duels.winner_team_id, duels.stake,
+	duels.rematch_of, duels.invited_user_id, duels.proof_photo_required, duels.proof_min_message_length,
+	duels.proof_not_before, duels.proof_not_after, duels.proof_timezone
+	FROM duels
+	JOIN habits ON duels.habit_id = habits.id
+	JOIN habit_categories ON habits.habit_category_id = habit_categories.id
+	JOIN duel_status ON duels.status_id = duel_status.id
+	LEFT JOIN users u1 ON duels.user1_id = u1.id
+	LEFT JOIN users u2 ON duels.user2_id = u2.id
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// queryer runs reads on the database or inside a transaction.
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func scanDuel(row rowScanner) (models.DuelDb, error) {
 	var duelDb models.DuelDb
-	err := r.Db.QueryRow(
-		`SELECT duels.id, duels.duration, duels.habit_id, habits.name,
-		habit_categories.name, duels.user1_id, duels.user2_id,
-		duels.user1_completed, duels.user2_completed, u1.first_name,
-		u2.first_name, u1.photo_url, u2.photo_url, TO_CHAR(duels.start_date, 'YYYY-MM-DD'),
-		TO_CHAR(duels.end_date, 'YYYY-MM-DD'), duels.winner_id, duel_status.value
-		FROM duels
-		JOIN habits ON duels.habit_id = habits.id
-		JOIN habit_categories ON habits.habit_category_id = habit_categories.id
-		JOIN duel_status ON duels.status_id = duel_status.id
-		LEFT JOIN users u1 ON duels.user1_id = u1.id
-		LEFT JOIN users u2 ON duels.user2_id = u2.id
-		WHERE duels.id = $1`, duel_id,
-	).Scan(&duelDb.Id, &duelDb.Duration, &duelDb.HabitId, &duelDb.HabitName, &duelDb.HabitCategory,
+	var weekdaysMask int
+	err := row.Scan(&duelDb.Id, &duelDb.Duration, &duelDb.Target, &duelDb.HabitId, &duelDb.HabitName, &duelDb.HabitCategory,
 		&duelDb.User1_id, &duelDb.User2_id,
-		&duelDb.User1_completed, &duelDb.User2_completed,
 		&duelDb.User1_firstName, &duelDb.User2_firstName,
 		&duelDb.User1_photoUrl, &duelDb.User2_photoUrl, &duelDb.StartDate,
-		&duelDb.EndDate, &duelDb.WinnerId, &duelDb.Status)
+		&duelDb.EndDate, &duelDb.WinnerId, &duelDb.Status,
+		&duelDb.Schedule.Type, &weekdaysMask, &duelDb.Schedule.TimesPerWeek,
+		&duelDb.ScoringMode, &duelDb.HabitUnit, &duelDb.HabitDailyTarget, &duelDb.MaxParticipants,
+		&duelDb.DuelType, &duelDb.TeamScoring, &duelDb.WinnerTeamId, &duelDb.Stake,
+		&duelDb.RematchOf, &duelDb.InvitedUserId, &duelDb.ProofRules.PhotoRequired, &duelDb.ProofRules.MinMessageLength,
+		&duelDb.ProofRules.NotBefore, &duelDb.ProofRules.NotAfter, &duelDb.ProofRules.Timezone)
+	if err != nil {
+		return duelDb, err
+	}
+	duelDb.Schedule.Weekdays = weekdaysFromMask(weekdaysMask)
+	return duelDb, nil
+}
+
+// Scheduled weekdays are stored as a bitmask, bit N is the ISO weekday N (1 - Monday, 7 - Sunday).
+func weekdaysToMask(weekdays []int) int {
+	mask := 0
+	for _, day := range weekdays {
+		mask |= 1 << day
+	}
+	return mask
+}
+
+func weekdaysFromMask(mask int) []int {
+	var weekdays []int = []int{}
+	for day := 1; day <= 7; day++ {
+		if mask&(1<<day) != 0 {
+			weekdays = append(weekdays, day)
+		}
+	}
+	return weekdays
+}
+
+func (r *Repository) GetDuelById(duel_id int64) (*models.DuelDb, error) {
+	return r.getDuelById(r.Db, duel_id)
+}
+
+func (r *Repository) getDuelById(q queryer, duel_id int64) (*models.DuelDb, error) {
+	duelDb, err := scanDuel(q.QueryRow(duelSelectQuery+`WHERE duels.id = $1`, duel_id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("custom error: no rows in duels result set")
 		}
 		return nil, err
 	}
+	if err := r.fillParticipants(q, &duelDb); err != nil {
+		return nil, err
+	}
 	return &duelDb, nil
 }
 
+func (r *Repository) FindDuelByInvitationHash(invitationHash string) (*models.DuelDb, error) {
+	var duelId int64
+	err := r.Db.QueryRow(`SELECT duel_id FROM invitations WHERE generatedHash = $1`, invitationHash).Scan(&duelId)
+	if err != nil {
+		return nil, errors.New("invitation link has been expired or does not exist")
+	}
+	return r.GetDuelById(duelId)
+}
+
 func (r *Repository) FindDuelsByUserId(user_id int64) ([]models.DuelDb, error) {
+	return r.findDuels(`WHERE duels.id IN (SELECT duel_id FROM duel_participants WHERE user_id = $1)`, user_id)
+}
+
+func (r *Repository) FindActiveDuels() ([]models.DuelDb, error) {
+	return r.findDuels(`WHERE duel_status.value = 'active'`)
+}
+
+// FindDirectInvitations returns the duels waiting for the user to accept or decline them.
+func (r *Repository) FindDirectInvitations(user_id int64) ([]models.DuelDb, error) {
+	return r.findDuels(`WHERE duels.invited_user_id = $1 AND duel_status.value = 'invited' ORDER BY duels.id DESC`, user_id)
+}
+
+func (r *Repository) findDuels(filter string, args ...any) ([]models.DuelDb, error) {
 	var duels []models.DuelDb = []models.DuelDb{}
-	rows, err := r.Db.Query(
-		`SELECT duels.id, duels.duration, duels.habit_id, habits.name,
-		habit_categories.name, duels.user1_id, duels.user2_id,
-		duels.user1_completed, duels.user2_completed, u1.first_name,
-		u2.first_name, u1.photo_url, u2.photo_url, TO_CHAR(duels.start_date, 'YYYY-MM-DD'),
-		TO_CHAR(duels.end_date, 'YYYY-MM-DD'), duels.winner_id, duel_status.value
-		FROM duels JOIN habits ON duels.habit_id = habits.id
-		JOIN habit_categories ON habits.habit_category_id = habit_categories.id
-		JOIN duel_status ON duels.status_id = duel_status.id
-		LEFT JOIN users u1 ON duels.user1_id = u1.id
-		LEFT JOIN users u2 ON duels.user2_id = u2.id
-		WHERE duels.user1_id = $1 OR duels.user2_id = $1`, user_id,
-	)
+	rows, err := r.Db.Query(duelSelectQuery+filter, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 	for rows.Next() {
-		duelDb := models.DuelDb{}
-		err = rows.Scan(&duelDb.Id, &duelDb.Duration, &duelDb.HabitId, &duelDb.HabitName, &duelDb.HabitCategory,
-			&duelDb.User1_id, &duelDb.User2_id,
-			&duelDb.User1_completed, &duelDb.User2_completed,
-			&duelDb.User1_firstName, &duelDb.User2_firstName,
-			&duelDb.User1_photoUrl, &duelDb.User2_photoUrl, &duelDb.StartDate,
-			&duelDb.EndDate, &duelDb.WinnerId, &duelDb.Status)
+		duelDb, err := scanDuel(rows)
 		if err != nil {
 			return nil, err
 		}
 		duels = append(duels, duelDb)
 	}
+	rows.Close()
+	for i := range duels {
+		if err := r.fillParticipants(r.Db, &duels[i]); err != nil {
+			return nil, err
+		}
+	}
 	return duels, nil
 }
 
-func (r *Repository) IncrementDuelCounter(duel *models.DuelDb, userID int64) (bool, error) {
+// IncrementDuelCounter counts one more completed day for the player and returns the new counter.
+func (r *Repository) IncrementDuelCounter(duel *models.DuelDb, userID int64) (int64, error) {
+	var counter int64
+	err := r.Db.QueryRow(
+		`UPDATE duel_participants
+		SET completed = completed + 1
+		WHERE duel_id = $1 AND user_id = $2
+		RETURNING completed`,
+		duel.Id, userID,
+	).Scan(&counter)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("user is not a participant of this duel")
+	}
+	if err != nil {
+		return 0, err
+	}
+	return counter, nil
+}
 
-	var counter int
+func (r *Repository) IncrementUserStreakAndUpdateLastTimeContributed(user *models.UserDb) error {
+	_, err := r.Db.Exec(`UPDATE users SET streak = streak + 1, last_time_contributed = $2 WHERE id = $1`, user.ID, r.Clock.Today())
+	if err != nil {
+		return err
+	}
+	return nil
+}
 
-	switch {
-	case duel.User1_id == userID:
-		if err := r.Db.QueryRow(
-			`UPDATE duels
-             SET user1_completed = user1_completed + 1
-             WHERE id = $1
-             RETURNING user1_completed`,
-			duel.Id,
-		).Scan(&counter); err != nil {
-			return false, err
-		}
+func (r *Repository) ResetUserStreakToOneAndUpdateLastTimeContributed(user *models.UserDb) error {
+	_, err := r.Db.Exec(`UPDATE users SET streak = 1, last_time_contributed = $2 WHERE id = $1`, user.ID, r.Clock.Today())
+	if err != nil {
+		return err
+	}
+	return nil
+}
 
-	case duel.User2_id.Valid && duel.User2_id.Int64 == userID:
-		if err := r.Db.QueryRow(
-			`UPDATE duels
-             SET user2_completed = user2_completed + 1
-             WHERE id = $1
-             RETURNING user2_completed`,
-			duel.Id,
-		).Scan(&counter); err != nil {
-			return false, err
-		}
+// UseStreakFreezesAndIncrementUserStreak spends one freeze per missed day and continues the streak.
+func (r *Repository) UseStreakFreezesAndIncrementUserStreak(user *models.UserDb, missedDays []string) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	default:
-		return false, errors.New("user is not a participant of this duel")
-	}
-
-	won := false
-	if counter >= duel.Duration {
-		won = true
-		if _, err := r.Db.Exec(
-			`UPDATE duels
-             SET winner_id = $1,
-                 end_date  = $2,
-                 status_id = 3
-             WHERE id = $3`,
-			userID,
-			time.Now().Format("2006-01-02"),
-			duel.Id,
-		); err != nil {
-			return false, err
+	res, err := tx.Exec(
+		`UPDATE users SET streak = streak + 1, streak_freezes = streak_freezes - $2, last_time_contributed = $3
+		WHERE id = $1 AND streak_freezes >= $2`,
+		user.ID, len(missedDays), r.Clock.Today(),
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("not enough streak freezes")
+	}
+
+	for _, day := range missedDays {
+		_, err = tx.Exec(
+			`INSERT INTO streak_freezes (user_id, frozen_date, used_at) VALUES ($1, $2, $3)`,
+			user.ID, day, r.Clock.Today(),
+		)
+		if err != nil {
+			return err
 		}
 	}
 
-	return won, nil
+	return tx.Commit()
 }
 
-func (r *Repository) IncrementUserStreakAndUpdateLastTimeContributed(user *models.UserDb) error {
-	_, err := r.Db.Exec(`UPDATE users SET streak = streak + 1, last_time_contributed = CURRENT_DATE WHERE id = $1`, user.ID)
+func (r *Repository) AwardStreakFreeze(user *models.UserDb) error {
+	_, err := r.Db.Exec(`UPDATE users SET streak_freezes = streak_freezes + 1 WHERE id = $1`, user.ID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *Repository) ResetUserStreakToOneAndUpdateLastTimeContributed(user *models.UserDb) error {
-	_, err := r.Db.Exec(`UPDATE users SET streak = 1, last_time_contributed = CURRENT_DATE WHERE id = $1`, user.ID)
+func (r *Repository) FindFrozenDaysByUserId(userID int64) ([]string, error) {
+	rows, err := r.Db.Query(
+		`SELECT TO_CHAR(frozen_date, 'YYYY-MM-DD') FROM streak_freezes WHERE user_id = $1 ORDER BY frozen_date`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []string = []string{}
+	for rows.Next() {
+		var day string
+		if err := rows.Scan(&day); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// EndDuel closes the duel, stores the final place of every participant and
+// updates their ratings. An invalid winnerID means there is no single winner.
+func (r *Repository) EndDuel(duelID int, winnerID sql.NullInt64, endDate string, places map[int64]int, forfeitedBy sql.NullInt64) error {
+	tx, err := r.Db.Beginx()
 	if err != nil {
 		return err
 	}
-	return nil
+	defer tx.Rollback()
+
+	if err := r.endDuel(tx, duelID, winnerID, endDate, places, forfeitedBy); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *Repository) IncrementWinCounter(user *models.UserDb) error {
-	_, err := r.Db.Exec(`UPDATE users SET wins = wins + 1 WHERE id = $1`, user.ID)
+func (r *Repository) endDuel(tx *sqlx.Tx, duelID int, winnerID sql.NullInt64, endDate string, places map[int64]int, forfeitedBy sql.NullInt64) error {
+	// Only an active duel can end, so a duel reviewed and checked into at the same
+	// time is not settled twice
+	res, err := tx.Exec(
+		`UPDATE duels SET winner_id = $1, end_date = $2, status_id = (SELECT id FROM duel_status WHERE value = 'ended'),
+		forfeited_by = $3 WHERE id = $4 AND status_id = (SELECT id FROM duel_status WHERE value = 'active')`,
+		winnerID, endDate, forfeitedBy, duelID,
+	)
 	if err != nil {
 		return err
 	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("duel already ended")
+	}
+	for userID, place := range places {
+		_, err = tx.Exec(
+			`UPDATE duel_participants SET place = $1 WHERE duel_id = $2 AND user_id = $3`,
+			place, duelID, userID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if err := r.applyRatings(tx, duelID, forfeitedBy); err != nil {
+		return err
+	}
+	var winners []int64
+	if winnerID.Valid {
+		winners = append(winners, winnerID.Int64)
+	}
+	if err := incrementWins(tx, winners); err != nil {
+		return err
+	}
+	if err := r.settleDuelCoins(tx, int64(duelID), winners); err != nil {
+		return err
+	}
+	return completeTournamentDuel(tx, duelID, winnerID)
+}
+
+func incrementWins(tx *sqlx.Tx, user_ids []int64) error {
+	for _, userID := range user_ids {
+		if _, err := tx.Exec(`UPDATE users SET wins = wins + 1 WHERE id = $1`, userID); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (r *Repository) CountUserDuelLogsBetween(userID int64, duelID int, from string, to string) (int, error) {
+	var count int
+	err := r.Db.QueryRow(
+		`SELECT COUNT(DISTINCT created_at) FROM logs
+		WHERE owner_id = $1 AND duel_id = $2 AND counted AND created_at BETWEEN $3 AND $4`,
+		userID, duelID, from, to,
+	).Scan(&count)
+	return count, err
+}
+
+// FindCountedDays returns the days on which the player's check-in counted in the duel.
+func (r *Repository) FindCountedDays(userID int64, duelID int64) ([]string, error) {
+	return findCountedDays(r.Db, userID, duelID)
+}
+
+func findCountedDays(q queryer, userID int64, duelID int64) ([]string, error) {
+	rows, err := q.Query(
+		`SELECT DISTINCT TO_CHAR(created_at, 'YYYY-MM-DD') FROM logs
+		WHERE owner_id = $1 AND duel_id = $2 AND counted`,
+		userID, duelID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []string = []string{}
+	for rows.Next() {
+		var day string
+		if err := rows.Scan(&day); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
 func (r *Repository) HasUserContributedToDuelToday(userID int64, duelID int64, date string) (bool, error) {
 	var exists bool
 	err := r.Db.QueryRow(`
@@ -489,12 +1445,130 @@ func (r *Repository) HasUserContributedToDuelToday(userID int64, duelID int64, d
             WHERE owner_id = $1 
               AND duel_id = $2
               AND created_at = $3
+              AND counted
         )
     `, userID, duelID, date).Scan(&exists)
 
 	return exists, err
 }
 
+func (r *Repository) SumUserDuelValueOnDate(userID int64, duelID int64, date string) (float64, error) {
+	var sum float64
+	err := r.Db.QueryRow(
+		`SELECT COALESCE(SUM(value), 0) FROM logs WHERE owner_id = $1 AND duel_id = $2 AND created_at = $3
+		AND `+undisputedLog,
+		userID, duelID, date,
+	).Scan(&sum)
+	return sum, err
+}
+
+// duelPeriod is the calendar period of the duel, up to its end date if it
+// ended early, with the days counted to the player.
+func duelPeriod(schedule models.Schedule, startDate string, duration int, endDate sql.NullString, counted []string) (calendar.Period, error) {
+	start, err := time.Parse(clock.DateLayout, startDate)
+	if err != nil {
+		return calendar.Period{}, err
+	}
+	end := start.AddDate(0, 0, duration-1)
+	if endDate.Valid {
+		ended, err := time.Parse(clock.DateLayout, endDate.String)
+		if err != nil {
+			return calendar.Period{}, err
+		}
+		if ended.Before(end) {
+			end = ended
+		}
+	}
+
+	period := calendar.Period{Schedule: schedule, Start: start, End: end, Counted: map[string]bool{}}
+	for _, day := range counted {
+		period.Counted[day] = true
+	}
+	return period, nil
+}
+
+func (r *Repository) streak(periods []calendar.Period) (int, int, error) {
+	today, err := time.Parse(clock.DateLayout, r.Clock.Today())
+	if err != nil {
+		return 0, 0, err
+	}
+	current, best := calendar.Streak(periods, today)
+	return current, best, nil
+}
+
+// FindHabitStreak returns the current and the best streak of a user across all
+// duels on the habit; a check-in missed in any of them breaks the streak.
+func (r *Repository) FindHabitStreak(userID int64, habitID int) (int, int, error) {
+	rows, err := r.Db.Query(
+		`SELECT duels.id, TO_CHAR(duels.start_date, 'YYYY-MM-DD'), duels.duration, TO_CHAR(duels.end_date, 'YYYY-MM-DD'),
+		duels.schedule_type, duels.schedule_weekdays, duels.schedule_times_per_week
+		FROM duels
+		JOIN duel_status ON duels.status_id = duel_status.id
+		JOIN duel_participants p ON p.duel_id = duels.id AND p.user_id = $1
+		WHERE duels.habit_id = $2 AND duel_status.value IN ('active', 'ended')`,
+		userID, habitID,
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+	type habitDuel struct {
+		id        int64
+		startDate string
+		duration  int
+		endDate   sql.NullString
+		schedule  models.Schedule
+	}
+	var duels []habitDuel
+	for rows.Next() {
+		var duel habitDuel
+		var weekdaysMask int
+		err := rows.Scan(&duel.id, &duel.startDate, &duel.duration, &duel.endDate,
+			&duel.schedule.Type, &weekdaysMask, &duel.schedule.TimesPerWeek)
+		if err != nil {
+			return 0, 0, err
+		}
+		duel.schedule.Weekdays = weekdaysFromMask(weekdaysMask)
+		duels = append(duels, duel)
+	}
+	rows.Close()
+
+	var periods []calendar.Period
+	for _, duel := range duels {
+		counted, err := findCountedDays(r.Db, userID, duel.id)
+		if err != nil {
+			return 0, 0, err
+		}
+		period, err := duelPeriod(duel.schedule, duel.startDate, duel.duration, duel.endDate, counted)
+		if err != nil {
+			return 0, 0, err
+		}
+		periods = append(periods, period)
+	}
+	return r.streak(periods)
+}
+
+// FindDuelStreak returns the current and the best streak of a user inside one duel.
+func (r *Repository) FindDuelStreak(userID int64, duelID int) (int, int, error) {
+	duel, err := r.getDuelById(r.Db, int64(duelID))
+	if err != nil {
+		return 0, 0, err
+	}
+	return r.findDuelStreak(r.Db, userID, duel)
+}
+
+func (r *Repository) findDuelStreak(q queryer, userID int64, duel *models.DuelDb) (int, int, error) {
+	counted, err := findCountedDays(q, userID, int64(duel.Id))
+	if err != nil {
+		return 0, 0, err
+	}
+	period, err := duelPeriod(duel.Schedule, duel.StartDate, duel.Duration, duel.EndDate, counted)
+	if err != nil {
+		return 0, 0, err
+	}
+	return r.streak([]calendar.Period{period})
+}
+
 // -- For dev testing -- //
 func (r *Repository) CreateTestData() error {
 	// ---------- AVATARS ----------
@@ -505,7 +1579,7 @@ func (r *Repository) CreateTestData() error {
 		"https://avatars.mds.yandex.net/i?id=d8b749ef8f2c051edbec63ac66459fe576e5750f-9895871-images-thumbs&n=13",
 	}
 
-	rand.Seed(time.Now().UnixNano())
+	rand.Seed(r.Clock.Now().UnixNano())
 
 	// ---------- USERS ----------
 	users := []struct {
@@ -584,20 +1658,34 @@ func (r *Repository) CreateTestData() error {
 	// Duel 1: User1 vs User2
 	var duel1ID int64
 	if err := r.Db.QueryRow(`
-		INSERT INTO duels (duration, habit_id, user1_id, user2_id, status_id)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO duels (duration, habit_id, user1_id, user2_id, status_id, start_date)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
-	`, 5, habitIDs[0], userIDs["MAXID_1"], userIDs["MAXID_2"], activeStatusID).Scan(&duel1ID); err != nil {
+	`, 5, habitIDs[0], userIDs["MAXID_1"], userIDs["MAXID_2"], activeStatusID, r.Clock.Today()).Scan(&duel1ID); err != nil {
+		return err
+	}
+
+	if _, err := r.Db.Exec(
+		`INSERT INTO duel_participants (duel_id, user_id) VALUES ($1, $2), ($1, $3)`,
+		duel1ID, userIDs["MAXID_1"], userIDs["MAXID_2"],
+	); err != nil {
 		return err
 	}
 
 	// Duel 2: User3 vs User4
 	var duel2ID int64
 	if err := r.Db.QueryRow(`
-		INSERT INTO duels (duration, habit_id, user1_id, user2_id, status_id)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO duels (duration, habit_id, user1_id, user2_id, status_id, start_date)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
-	`, 7, habitIDs[1], userIDs["MAXID_3"], userIDs["MAXID_4"], activeStatusID).Scan(&duel2ID); err != nil {
+	`, 7, habitIDs[1], userIDs["MAXID_3"], userIDs["MAXID_4"], activeStatusID, r.Clock.Today()).Scan(&duel2ID); err != nil {
+		return err
+	}
+
+	if _, err := r.Db.Exec(
+		`INSERT INTO duel_participants (duel_id, user_id) VALUES ($1, $2), ($1, $3)`,
+		duel2ID, userIDs["MAXID_3"], userIDs["MAXID_4"],
+	); err != nil {
 		return err
 	}
 
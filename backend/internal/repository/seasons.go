@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/models"
+)
+
+const seasonSelectQuery = `
+	SELECT id, name, TO_CHAR(start_date, 'YYYY-MM-DD'), TO_CHAR(end_date, 'YYYY-MM-DD'), status FROM seasons
+`
+
+func scanSeasons(rows *sql.Rows) ([]models.SeasonDb, error) {
+	defer rows.Close()
+	var seasons []models.SeasonDb = []models.SeasonDb{}
+	for rows.Next() {
+		season := models.SeasonDb{}
+		err := rows.Scan(&season.Id, &season.Name, &season.StartDate, &season.EndDate, &season.Status)
+		if err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, season)
+	}
+	return seasons, nil
+}
+
+// CreateSeason adds a season; seasons must not overlap.
+func (r *Repository) CreateSeason(name string, startDate string, endDate string) (int64, error) {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Serialize season creation so that two overlapping seasons cannot slip in together
+	if _, err := tx.Exec(`LOCK TABLE seasons IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return 0, err
+	}
+	var overlapping bool
+	err = tx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM seasons WHERE start_date <= $2 AND end_date >= $1)`, startDate, endDate,
+	).Scan(&overlapping)
+	if err != nil {
+		return 0, err
+	}
+	if overlapping {
+		return 0, errors.New("season overlaps with an existing season")
+	}
+
+	var id int64
+	err = tx.QueryRow(
+		`INSERT INTO seasons (name, start_date, end_date, status) VALUES ($1, $2, $3, $4) RETURNING id`,
+		name, startDate, endDate, models.SeasonActive,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func (r *Repository) FindSeasons() ([]models.SeasonDb, error) {
+	rows, err := r.Db.Query(seasonSelectQuery + `ORDER BY start_date DESC`)
+	if err != nil {
+		return nil, err
+	}
+	return scanSeasons(rows)
+}
+
+func (r *Repository) FindSeasonById(season_id int64) (*models.SeasonDb, error) {
+	season := models.SeasonDb{}
+	err := r.Db.QueryRow(seasonSelectQuery+`WHERE id = $1`, season_id).Scan(
+		&season.Id, &season.Name, &season.StartDate, &season.EndDate, &season.Status,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("season does not exist")
+		}
+		return nil, err
+	}
+	return &season, nil
+}
+
+// FindCurrentSeason returns the season running today, nil if there is none.
+func (r *Repository) FindCurrentSeason() (*models.SeasonDb, error) {
+	season := models.SeasonDb{}
+	err := r.Db.QueryRow(
+		seasonSelectQuery+`WHERE start_date <= $1 AND end_date >= $1`, r.Clock.Today(),
+	).Scan(&season.Id, &season.Name, &season.StartDate, &season.EndDate, &season.Status)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// FindSeasonsToClose returns active seasons whose last day is over.
+func (r *Repository) FindSeasonsToClose() ([]models.SeasonDb, error) {
+	rows, err := r.Db.Query(
+		seasonSelectQuery+`WHERE status = $1 AND end_date < $2 ORDER BY start_date`,
+		models.SeasonActive, r.Clock.Today(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanSeasons(rows)
+}
+
+// seasonStatsQuery ranks the players who played in the season ($1 - $2) by
+// wins, then by counted check-ins.
+const seasonStatsQuery = `
+	WITH stats AS (
+		SELECT u.id AS user_id, u.first_name, COALESCE(u.photo_url, '') AS photo_url,
+			(SELECT COUNT(*) FROM duel_participants p JOIN duels d ON p.duel_id = d.id
+				WHERE p.user_id = u.id AND (d.winner_id = u.id OR d.winner_team_id = p.team_id)
+				AND d.end_date BETWEEN $1 AND $2) AS wins,
+			(SELECT COUNT(*) FROM duel_participants p JOIN duels d ON p.duel_id = d.id
+				WHERE p.user_id = u.id AND d.status_id = (SELECT id FROM duel_status WHERE value = 'ended')
+				AND d.end_date BETWEEN $1 AND $2) AS duels_played,
+			(SELECT COUNT(*) FROM logs l
+				WHERE l.owner_id = u.id AND l.counted AND l.created_at BETWEEN $1 AND $2) AS completions
+		FROM users u
+	), ranked AS (
+		SELECT RANK() OVER (ORDER BY wins DESC, completions DESC) AS rank, * FROM stats
+		WHERE duels_played > 0 OR completions > 0
+	)
+	SELECT rank, user_id, first_name, photo_url, wins, duels_played, completions FROM ranked
+`
+
+func scanStandings(rows *sql.Rows) ([]models.SeasonStandingDb, error) {
+	defer rows.Close()
+	var standings []models.SeasonStandingDb = []models.SeasonStandingDb{}
+	for rows.Next() {
+		standing := models.SeasonStandingDb{}
+		err := rows.Scan(&standing.Rank, &standing.UserId, &standing.FirstName, &standing.PhotoUrl,
+			&standing.Wins, &standing.DuelsPlayed, &standing.Completions)
+		if err != nil {
+			return nil, err
+		}
+		standings = append(standings, standing)
+	}
+	return standings, nil
+}
+
+// FindLiveSeasonStandings calculates the standings of a season from duels and logs, limit 0 means all.
+func (r *Repository) FindLiveSeasonStandings(season *models.SeasonDb, limit int) ([]models.SeasonStandingDb, error) {
+	rows, err := r.Db.Query(seasonStatsQuery+`ORDER BY rank, user_id LIMIT NULLIF($3, 0)`, season.StartDate, season.EndDate, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanStandings(rows)
+}
+
+// FindUserSeasonStanding returns the user's numbers in the season, nil if the user has not played.
+func (r *Repository) FindUserSeasonStanding(season *models.SeasonDb, user_id int64) (*models.SeasonStandingDb, error) {
+	rows, err := r.Db.Query(seasonStatsQuery+`WHERE user_id = $3`, season.StartDate, season.EndDate, user_id)
+	if err != nil {
+		return nil, err
+	}
+	standings, err := scanStandings(rows)
+	if err != nil || len(standings) == 0 {
+		return nil, err
+	}
+	return &standings[0], nil
+}
+
+func (r *Repository) FindArchivedSeasonStandings(season_id int64, limit int) ([]models.SeasonStandingDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT s.rank, s.user_id, u.first_name, COALESCE(u.photo_url, ''), s.wins, s.duels_played,
+		s.completions, s.reward
+		FROM season_standings s
+		JOIN users u ON s.user_id = u.id
+		WHERE s.season_id = $1
+		ORDER BY s.rank, s.user_id LIMIT NULLIF($2, 0)`, season_id, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var standings []models.SeasonStandingDb = []models.SeasonStandingDb{}
+	for rows.Next() {
+		standing := models.SeasonStandingDb{}
+		err := rows.Scan(&standing.Rank, &standing.UserId, &standing.FirstName, &standing.PhotoUrl,
+			&standing.Wins, &standing.DuelsPlayed, &standing.Completions, &standing.Reward)
+		if err != nil {
+			return nil, err
+		}
+		standings = append(standings, standing)
+	}
+	return standings, nil
+}
+
+// CloseSeason archives the final standings and marks the season closed.
+func (r *Repository) CloseSeason(season_id int64, standings []models.SeasonStandingDb) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`UPDATE seasons SET status = $1 WHERE id = $2 AND status = $3`,
+		models.SeasonClosed, season_id, models.SeasonActive,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("season is already closed")
+	}
+
+	for _, standing := range standings {
+		_, err = tx.Exec(
+			`INSERT INTO season_standings (season_id, user_id, rank, wins, duels_played, completions, reward)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			season_id, standing.UserId, standing.Rank, standing.Wins, standing.DuelsPlayed,
+			standing.Completions, standing.Reward,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func (r *Repository) CreateTeam(owner_id int64, name string, invitationHash string) (int64, error) {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var teamId int64
+	err = tx.QueryRow(
+		`INSERT INTO teams (name, owner_id, invitation_hash) VALUES ($1, $2, $3) RETURNING id`,
+		name, owner_id, invitationHash,
+	).Scan(&teamId)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(`INSERT INTO team_members (team_id, user_id) VALUES ($1, $2)`, teamId, owner_id)
+	if err != nil {
+		return 0, err
+	}
+	return teamId, tx.Commit()
+}
+
+func (r *Repository) FindTeamById(team_id int64) (*models.TeamDb, error) {
+	var team models.TeamDb
+	err := r.Db.QueryRow(
+		`SELECT id, name, owner_id, invitation_hash FROM teams WHERE id = $1`, team_id,
+	).Scan(&team.Id, &team.Name, &team.OwnerId, &team.InvitationHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("team does not exist")
+		}
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (r *Repository) JoinTeamByInvitationHash(user_id int64, invitationHash string) error {
+	var teamId int64
+	err := r.Db.QueryRow(`SELECT id FROM teams WHERE invitation_hash = $1`, invitationHash).Scan(&teamId)
+	if err != nil {
+		return errors.New("team invitation link does not exist")
+	}
+	res, err := r.Db.Exec(
+		`INSERT INTO team_members (team_id, user_id) VALUES ($1, $2) ON CONFLICT (team_id, user_id) DO NOTHING`,
+		teamId, user_id,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("you are already a member of this team")
+	}
+	return nil
+}
+
+// LeaveTeam removes the member; if the owner leaves, the longest-standing member becomes the owner.
+func (r *Repository) LeaveTeam(user_id int64, team_id int64) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, team_id, user_id)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("you are not a member of this team")
+	}
+
+	_, err = tx.Exec(
+		`UPDATE teams SET owner_id = (SELECT user_id FROM team_members WHERE team_id = $1 ORDER BY id LIMIT 1)
+		WHERE id = $1 AND owner_id = $2
+		AND EXISTS (SELECT 1 FROM team_members WHERE team_id = $1)`,
+		team_id, user_id,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) IsTeamMember(user_id int64, team_id int64) (bool, error) {
+	var exists bool
+	err := r.Db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM team_members WHERE team_id = $1 AND user_id = $2)`, team_id, user_id,
+	).Scan(&exists)
+	return exists, err
+}
+
+func (r *Repository) FindTeamMembers(team_id int64) ([]dto.TeamMemberDto, error) {
+	rows, err := r.Db.Query(
+		`SELECT u.id, u.first_name, u.photo_url FROM team_members tm
+		JOIN users u ON tm.user_id = u.id
+		WHERE tm.team_id = $1 ORDER BY tm.id`, team_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []dto.TeamMemberDto = []dto.TeamMemberDto{}
+	for rows.Next() {
+		member := dto.TeamMemberDto{}
+		var photoUrl sql.NullString
+		if err := rows.Scan(&member.UserId, &member.FirstName, &photoUrl); err != nil {
+			return nil, err
+		}
+		member.PhotoUrl = photoUrl.String
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (r *Repository) FindTeamsByUserId(user_id int64) ([]models.TeamDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT t.id, t.name, t.owner_id, t.invitation_hash FROM teams t
+		JOIN team_members tm ON tm.team_id = t.id
+		WHERE tm.user_id = $1 ORDER BY t.id`, user_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []models.TeamDb = []models.TeamDb{}
+	for rows.Next() {
+		team := models.TeamDb{}
+		if err := rows.Scan(&team.Id, &team.Name, &team.OwnerId, &team.InvitationHash); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+// addTeamToDuel enters the team into the duel and makes its current members participants.
+func addTeamToDuel(tx *sqlx.Tx, duel_id int64, team_id int64) error {
+	_, err := tx.Exec(`INSERT INTO duel_teams (duel_id, team_id) VALUES ($1, $2)`, duel_id, team_id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`INSERT INTO duel_participants (duel_id, user_id, team_id)
+		SELECT $1, user_id, team_id FROM team_members WHERE team_id = $2`,
+		duel_id, team_id,
+	)
+	return err
+}
+
+// ActivateTeamDuelFromInvitationHash enters the team as the opponent and starts the duel today.
+func (r *Repository) ActivateTeamDuelFromInvitationHash(team_id int64, invitationHash string, target int) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var duelId int64
+	err = tx.QueryRow(`SELECT duel_id FROM invitations WHERE generatedHash = $1`, invitationHash).Scan(&duelId)
+	if err != nil {
+		return errors.New("invitation link has been expired or does not exist")
+	}
+
+	var status, duelType string
+	err = tx.QueryRow(
+		`SELECT duel_status.value, duels.duel_type FROM duels
+		JOIN duel_status ON duels.status_id = duel_status.id
+		WHERE duels.id = $1 FOR UPDATE OF duels`, duelId,
+	).Scan(&status, &duelType)
+	if err != nil {
+		return err
+	}
+	if status != "invited" {
+		return errors.New("duel is not for invitation")
+	}
+	if duelType != models.DuelTypeTeam {
+		return errors.New("duel is not a team duel")
+	}
+
+	var overlapping bool
+	err = tx.QueryRow(
+		`SELECT EXISTS (
+			SELECT 1 FROM team_members WHERE team_id = $1
+			AND user_id IN (SELECT user_id FROM duel_participants WHERE duel_id = $2)
+		)`, team_id, duelId,
+	).Scan(&overlapping)
+	if err != nil {
+		return err
+	}
+	if overlapping {
+		return errors.New("a member of your team is already on the other side of this duel")
+	}
+
+	if err := addTeamToDuel(tx, duelId, team_id); err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`UPDATE duels SET user2_id = (SELECT owner_id FROM teams WHERE id = $1) WHERE id = $2`,
+		team_id, duelId,
+	)
+	if err != nil {
+		return err
+	}
+	if err := r.activateDuel(tx, duelId, target); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindDuelTeams returns the teams of a team duel with their current scores. A team
+// score is the sum of its members' counters, or their average for average scoring.
+func (r *Repository) FindDuelTeams(duel_id int, teamScoring string) ([]models.DuelTeamDb, error) {
+	return findDuelTeams(r.Db, duel_id, teamScoring)
+}
+
+func findDuelTeams(q queryer, duel_id int, teamScoring string) ([]models.DuelTeamDb, error) {
+	aggregate := "SUM"
+	if teamScoring == models.TeamScoringAverage {
+		aggregate = "AVG"
+	}
+	rows, err := q.Query(
+		`SELECT dt.team_id, t.name, dt.place, COUNT(p.id),
+		COALESCE(`+aggregate+`(p.completed), 0),
+		COALESCE(`+aggregate+`((SELECT COALESCE(SUM(value), 0) FROM logs WHERE logs.duel_id = p.duel_id AND logs.owner_id = p.user_id
+			AND `+undisputedLog+`)), 0)
+		FROM duel_teams dt
+		JOIN teams t ON dt.team_id = t.id
+		LEFT JOIN duel_participants p ON p.duel_id = dt.duel_id AND p.team_id = dt.team_id
+		WHERE dt.duel_id = $1
+		GROUP BY dt.id, dt.team_id, t.name, dt.place
+		ORDER BY dt.id`, duel_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []models.DuelTeamDb = []models.DuelTeamDb{}
+	for rows.Next() {
+		team := models.DuelTeamDb{}
+		err := rows.Scan(&team.TeamId, &team.Name, &team.Place, &team.Members, &team.Score, &team.Volume)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+// EndTeamDuel closes a team duel; every member gets the place of the team and
+// is rated against the members of the other teams.
+func (r *Repository) EndTeamDuel(duelID int, winnerTeamID sql.NullInt64, endDate string, places map[int64]int) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.endTeamDuel(tx, duelID, winnerTeamID, endDate, places); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) endTeamDuel(tx *sqlx.Tx, duelID int, winnerTeamID sql.NullInt64, endDate string, places map[int64]int) error {
+	res, err := tx.Exec(
+		`UPDATE duels SET winner_team_id = $1, end_date = $2, status_id = (SELECT id FROM duel_status WHERE value = 'ended')
+		WHERE id = $3 AND status_id = (SELECT id FROM duel_status WHERE value = 'active')`,
+		winnerTeamID, endDate, duelID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("duel already ended")
+	}
+	for teamID, place := range places {
+		_, err = tx.Exec(`UPDATE duel_teams SET place = $1 WHERE duel_id = $2 AND team_id = $3`, place, duelID, teamID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`UPDATE duel_participants SET place = $1 WHERE duel_id = $2 AND team_id = $3`,
+			place, duelID, teamID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if err := r.applyRatings(tx, duelID, sql.NullInt64{}); err != nil {
+		return err
+	}
+	var winners []int64
+	if winnerTeamID.Valid {
+		err = tx.Select(&winners,
+			`SELECT user_id FROM duel_participants WHERE duel_id = $1 AND team_id = $2 ORDER BY user_id`,
+			duelID, winnerTeamID.Int64,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if err := incrementWins(tx, winners); err != nil {
+		return err
+	}
+	return r.settleDuelCoins(tx, int64(duelID), winners)
+}
@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const tournamentSelectQuery = `
+	SELECT tournaments.id, tournaments.name, tournaments.organizer_id, tournaments.habit_id, habits.name,
+	tournaments.days, tournaments.scoring_mode, tournaments.format, tournaments.status,
+	tournaments.invitation_hash, tournaments.winner_id
+	FROM tournaments
+	JOIN habits ON tournaments.habit_id = habits.id
+`
+
+func scanTournament(row rowScanner) (*models.TournamentDb, error) {
+	var tournament models.TournamentDb
+	err := row.Scan(&tournament.Id, &tournament.Name, &tournament.OrganizerId, &tournament.HabitId,
+		&tournament.HabitName, &tournament.Days, &tournament.ScoringMode, &tournament.Format,
+		&tournament.Status, &tournament.InvitationHash, &tournament.WinnerId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("tournament does not exist")
+		}
+		return nil, err
+	}
+	return &tournament, nil
+}
+
+func (r *Repository) CreateTournament(tournament *models.TournamentDb) (int64, error) {
+	var id int64
+	err := r.Db.QueryRow(
+		`INSERT INTO tournaments (name, organizer_id, habit_id, days, scoring_mode, format, status, invitation_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		tournament.Name, tournament.OrganizerId, tournament.HabitId, tournament.Days,
+		tournament.ScoringMode, tournament.Format, models.TournamentRegistration, tournament.InvitationHash,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *Repository) FindTournamentById(tournament_id int64) (*models.TournamentDb, error) {
+	return scanTournament(r.Db.QueryRow(tournamentSelectQuery+`WHERE tournaments.id = $1`, tournament_id))
+}
+
+// JoinTournamentByInvitationHash registers the player; the seed is the registration order.
+func (r *Repository) JoinTournamentByInvitationHash(user_id int64, invitationHash string, maxPlayers int) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var tournamentId int64
+	var status string
+	err = tx.QueryRow(
+		`SELECT id, status FROM tournaments WHERE invitation_hash = $1 FOR UPDATE`, invitationHash,
+	).Scan(&tournamentId, &status)
+	if err != nil {
+		return errors.New("tournament invitation link does not exist")
+	}
+	if status != models.TournamentRegistration {
+		return errors.New("tournament registration is closed")
+	}
+
+	var alreadyJoined bool
+	var players int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FILTER (WHERE user_id = $2) > 0, COUNT(*) FROM tournament_players WHERE tournament_id = $1`,
+		tournamentId, user_id,
+	).Scan(&alreadyJoined, &players)
+	if err != nil {
+		return err
+	}
+	if alreadyJoined {
+		return errors.New("you are already registered for this tournament")
+	}
+	if players >= maxPlayers {
+		return errors.New("tournament is full")
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO tournament_players (tournament_id, user_id, seed) VALUES ($1, $2, $3)`,
+		tournamentId, user_id, players+1,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) FindTournamentPlayers(tournament_id int64) ([]models.TournamentPlayerDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT tp.user_id, u.first_name, u.photo_url, tp.seed FROM tournament_players tp
+		JOIN users u ON tp.user_id = u.id
+		WHERE tp.tournament_id = $1 ORDER BY tp.seed`, tournament_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var players []models.TournamentPlayerDb = []models.TournamentPlayerDb{}
+	for rows.Next() {
+		player := models.TournamentPlayerDb{}
+		if err := rows.Scan(&player.UserId, &player.FirstName, &player.PhotoUrl, &player.Seed); err != nil {
+			return nil, err
+		}
+		players = append(players, player)
+	}
+	return players, nil
+}
+
+// StartTournament stores the whole bracket and closes the registration.
+func (r *Repository) StartTournament(tournament_id int64, matches []models.TournamentMatchDb) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`UPDATE tournaments SET status = $1 WHERE id = $2 AND status = $3`,
+		models.TournamentActive, tournament_id, models.TournamentRegistration,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("tournament has already started")
+	}
+
+	for _, match := range matches {
+		_, err = tx.Exec(
+			`INSERT INTO tournament_matches (tournament_id, round, position, player1_id, player2_id)
+			VALUES ($1, $2, $3, $4, $5)`,
+			tournament_id, match.Round, match.Position, match.Player1Id, match.Player2Id,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) FindTournamentMatches(tournament_id int64) ([]models.TournamentMatchDb, error) {
+	rows, err := r.Db.Query(
+		`SELECT id, round, position, player1_id, player2_id, duel_id, winner_id, finished
+		FROM tournament_matches WHERE tournament_id = $1 ORDER BY round, position`, tournament_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []models.TournamentMatchDb = []models.TournamentMatchDb{}
+	for rows.Next() {
+		match := models.TournamentMatchDb{}
+		err := rows.Scan(&match.Id, &match.Round, &match.Position, &match.Player1Id, &match.Player2Id,
+			&match.DuelId, &match.WinnerId, &match.Finished)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// FindTournamentIdByDuelId returns the tournament the duel is played in, 0 for ordinary duels.
+func (r *Repository) FindTournamentIdByDuelId(duel_id int) (int64, error) {
+	var tournamentId int64
+	err := r.Db.QueryRow(`SELECT tournament_id FROM tournament_matches WHERE duel_id = $1`, duel_id).Scan(&tournamentId)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return tournamentId, err
+}
+
+// StartTournamentMatchDuel creates the duel of a match between its two players,
+// attaches it to the match and starts it today. The pairing is set by the
+// bracket, so blocks between the players are not checked, and tournament duels
+// have no stake to escrow.
+func (r *Repository) StartTournamentMatchDuel(match_id int64, player1_id int64, player2_id int64, habit_id int, settings models.DuelSettings, target int) error {
+	var invitedStatusId int
+	err := r.Db.QueryRow(`SELECT id FROM duel_status WHERE value = 'invited'`).Scan(&invitedStatusId)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	duelId, err := r.insertDuel(tx, player1_id, habit_id, invitedStatusId, settings)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`INSERT INTO duel_participants (duel_id, user_id) VALUES ($1, $2), ($1, $3)`,
+		duelId, player1_id, player2_id,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE duels SET user2_id = $1 WHERE id = $2`, player2_id, duelId)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(
+		`UPDATE tournament_matches SET duel_id = $1 WHERE id = $2 AND duel_id IS NULL`, duelId, match_id,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("match duel has already been started")
+	}
+	if err := r.activateDuel(tx, duelId, target); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CompleteTournamentMatch stores the result of a match played without a duel, such as a bye.
+func (r *Repository) CompleteTournamentMatch(match_id int64, winnerID sql.NullInt64) error {
+	tx, err := r.Db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := completeTournamentMatch(tx, match_id, winnerID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// completeTournamentDuel completes the tournament match played in the duel, if
+// there is one. Called when the duel ends, in the same transaction.
+func completeTournamentDuel(tx *sqlx.Tx, duel_id int, winnerID sql.NullInt64) error {
+	var matchId int64
+	err := tx.QueryRow(`SELECT id FROM tournament_matches WHERE duel_id = $1`, duel_id).Scan(&matchId)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return completeTournamentMatch(tx, matchId, winnerID)
+}
+
+// completeTournamentMatch stores the match result. In single elimination the
+// winner moves to the next round; a draw is won by the better seed.
+func completeTournamentMatch(tx *sqlx.Tx, match_id int64, winnerID sql.NullInt64) error {
+	var tournamentId int64
+	var round, position, lastRound int
+	var player1Id, player2Id sql.NullInt64
+	var format string
+	var finished bool
+	err := tx.QueryRow(
+		`SELECT m.tournament_id, m.round, m.position, m.player1_id, m.player2_id, m.finished, t.format,
+		(SELECT MAX(round) FROM tournament_matches WHERE tournament_id = m.tournament_id)
+		FROM tournament_matches m
+		JOIN tournaments t ON m.tournament_id = t.id
+		WHERE m.id = $1 FOR UPDATE OF m`, match_id,
+	).Scan(&tournamentId, &round, &position, &player1Id, &player2Id, &finished, &format, &lastRound)
+	if err != nil {
+		return err
+	}
+	if finished {
+		return errors.New("tournament match is already finished")
+	}
+
+	if !winnerID.Valid && format == models.TournamentSingleElimination {
+		// Игроки с меньшим посевом выше
+		err = tx.QueryRow(
+			`SELECT user_id FROM tournament_players WHERE tournament_id = $1 AND user_id IN ($2, $3)
+			ORDER BY seed LIMIT 1`,
+			tournamentId, player1Id, player2Id,
+		).Scan(&winnerID)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(
+		`UPDATE tournament_matches SET winner_id = $1, finished = TRUE WHERE id = $2`, winnerID, match_id,
+	)
+	if err != nil {
+		return err
+	}
+	if format != models.TournamentSingleElimination || !winnerID.Valid || round == lastRound {
+		return nil
+	}
+
+	// Победитель занимает место 1 или 2 в матче следующего раунда
+	column := "player1_id"
+	if position%2 == 1 {
+		column = "player2_id"
+	}
+	_, err = tx.Exec(
+		`UPDATE tournament_matches SET `+column+` = $1 WHERE tournament_id = $2 AND round = $3 AND position = $4`,
+		winnerID, tournamentId, round+1, position/2,
+	)
+	return err
+}
+
+// FindActiveTournamentIds returns the tournaments that have started and not ended yet.
+func (r *Repository) FindActiveTournamentIds() ([]int64, error) {
+	var ids []int64 = []int64{}
+	err := r.Db.Select(&ids, `SELECT id FROM tournaments WHERE status = $1 ORDER BY id`, models.TournamentActive)
+	return ids, err
+}
+
+func (r *Repository) EndTournament(tournament_id int64, winnerID sql.NullInt64) error {
+	_, err := r.Db.Exec(
+		`UPDATE tournaments SET status = $1, winner_id = $2 WHERE id = $3`,
+		models.TournamentEnded, winnerID, tournament_id,
+	)
+	return err
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+)
+
+// Domain events the achievement rules react to.
+const (
+	achievementEventCheckIn   = "check_in"
+	achievementEventDuelEnded = "duel_ended"
+)
+
+// comebackDeficit is how many counted days a winner had to be behind for a comeback win.
+const comebackDeficit = 3
+
+// achievementRule awards a badge once its condition holds. A rule is only
+// evaluated on the events that can change the numbers it looks at.
+type achievementRule struct {
+	code        string
+	title       string
+	description string
+	events      []string
+	earned      func(stats *models.AchievementStatsDb) bool
+}
+
+var achievementRules = []achievementRule{
+	{
+		code:        "first_check_in",
+		title:       "Первый шаг",
+		description: "Сделать первую засчитанную отметку",
+		events:      []string{achievementEventCheckIn},
+		earned:      func(stats *models.AchievementStatsDb) bool { return stats.CheckIns >= 1 },
+	},
+	{
+		code:        "streak_7",
+		title:       "Неделя без пропусков",
+		description: "Держать стрик 7 дней",
+		events:      []string{achievementEventCheckIn},
+		earned:      func(stats *models.AchievementStatsDb) bool { return stats.Streak >= 7 },
+	},
+	{
+		code:        "streak_30",
+		title:       "Железная воля",
+		description: "Держать стрик 30 дней",
+		events:      []string{achievementEventCheckIn},
+		earned:      func(stats *models.AchievementStatsDb) bool { return stats.Streak >= 30 },
+	},
+	{
+		code:        "photo_proof_10",
+		title:       "Фотодоказательства",
+		description: "Прикладывать фото в 10 дуэлях",
+		events:      []string{achievementEventCheckIn},
+		earned:      func(stats *models.AchievementStatsDb) bool { return stats.PhotoDuels >= 10 },
+	},
+	{
+		code:        "first_win",
+		title:       "Первая победа",
+		description: "Выиграть дуэль",
+		events:      []string{achievementEventDuelEnded},
+		earned:      func(stats *models.AchievementStatsDb) bool { return stats.Wins >= 1 },
+	},
+	{
+		code:        "wins_10",
+		title:       "Дуэлянт",
+		description: "Выиграть 10 дуэлей",
+		events:      []string{achievementEventDuelEnded},
+		earned:      func(stats *models.AchievementStatsDb) bool { return stats.Wins >= 10 },
+	},
+	{
+		code:        "duels_played_25",
+		title:       "Ветеран",
+		description: "Завершить 25 дуэлей",
+		events:      []string{achievementEventDuelEnded},
+		earned:      func(stats *models.AchievementStatsDb) bool { return stats.DuelsPlayed >= 25 },
+	},
+	{
+		code:        "comeback_win",
+		title:       "Камбэк",
+		description: "Выиграть дуэль один на один, отставая на 3 засчитанных дня",
+		events:      []string{achievementEventDuelEnded},
+		earned:      func(stats *models.AchievementStatsDb) bool { return stats.ComebackWins >= 1 },
+	},
+	{
+		code:        "tournament_champion",
+		title:       "Чемпион турнира",
+		description: "Выиграть турнир",
+		events:      []string{achievementEventDuelEnded},
+		earned:      func(stats *models.AchievementStatsDb) bool { return stats.TournamentWins >= 1 },
+	},
+}
+
+func (rule achievementRule) handles(event string) bool {
+	for _, e := range rule.events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateAchievements awards the badges whose rules react to the event and
+// hold for the user. An empty event evaluates every rule.
+func (s *Service) evaluateAchievements(user_id int64, event string) error {
+	stats, err := s.Repository.FindAchievementStats(user_id, comebackDeficit)
+	if err != nil {
+		return err
+	}
+	var codes []string
+	for _, rule := range achievementRules {
+		if (event == "" || rule.handles(event)) && rule.earned(stats) {
+			codes = append(codes, rule.code)
+		}
+	}
+	return s.Repository.AwardAchievements(user_id, codes)
+}
+
+// BackfillAchievements evaluates every rule for every user, so that badges
+// added later are awarded for what players had already done.
+func (s *Service) BackfillAchievements() error {
+	userIds, err := s.Repository.FindAllUserIds()
+	if err != nil {
+		return err
+	}
+	for _, userId := range userIds {
+		if err := s.evaluateAchievements(userId, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) GetUserAchievements(user_id int64) ([]dto.AchievementDto, error) {
+	awarded, err := s.Repository.FindAchievementsByUserId(user_id)
+	if err != nil {
+		return nil, err
+	}
+	rules := map[string]achievementRule{}
+	for _, rule := range achievementRules {
+		rules[rule.code] = rule
+	}
+
+	var achievements []dto.AchievementDto = []dto.AchievementDto{}
+	for _, achievement := range awarded {
+		rule, ok := rules[achievement.Code]
+		if !ok {
+			// Правило удалено, а бейдж остался в базе
+			continue
+		}
+		achievements = append(achievements, dto.AchievementDto{
+			Code:        rule.code,
+			Title:       rule.title,
+			Description: rule.description,
+			AwardedAt:   achievement.AwardedAt,
+		})
+	}
+	return achievements, nil
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"maxbot/internal/clock"
+	"maxbot/internal/models"
+	"time"
+	"unicode/utf8"
+)
+
+// opponentsOf returns the participants playing against the user: everyone
+// else in individual duels, the members of the other teams in team duels.
+func opponentsOf(duel *models.DuelDb, user_id int64) []int64 {
+	side := competitorID(duel, user_id)
+	var opponents []int64
+	for _, participant := range duel.Participants {
+		if competitorID(duel, participant.UserId) != side {
+			opponents = append(opponents, participant.UserId)
+		}
+	}
+	return opponents
+}
+
+// checkBackfillDay checks that a check-in could have been made on the past day:
+// it is inside the duel period, scheduled, and not counted yet.
+func (s *Service) checkBackfillDay(duel *models.DuelDb, owner_id int64, day string) error {
+	dayDate, err := time.Parse(clock.DateLayout, day)
+	if err != nil {
+		return errors.New("date should be in YYYY-MM-DD format")
+	}
+	startDate, err := time.Parse(clock.DateLayout, duel.StartDate)
+	if err != nil {
+		return err
+	}
+	if dayDate.Before(startDate) {
+		return errors.New("the date is before the start of the duel")
+	}
+	if day >= s.Clock.Today() {
+		return errors.New("only past days can be backfilled, check in for today as usual")
+	}
+	if err := s.checkSchedule(duel, owner_id, day); err != nil {
+		return err
+	}
+	if duel.HabitDailyTarget > 0 {
+		return nil
+	}
+	counted, err := s.Repository.HasUserContributedToDuelToday(owner_id, int64(duel.Id), day)
+	if err != nil {
+		return err
+	}
+	if counted {
+		return errors.New("this day is already counted")
+	}
+	return nil
+}
+
+// RequestBackfill asks the opponents to confirm a check-in for a missed past
+// day of the duel. The log is created once one of them approves it.
+func (s *Service) RequestBackfill(user *models.UserDb, duel_id int64, day string, message string, photo []byte, value *float64) (int64, error) {
+	if utf8.RuneCountInString(message) > maxMessageLength {
+		return 0, errors.New("message too long (max 500 characters)")
+	}
+	duel, err := s.Repository.GetDuelById(duel_id)
+	if err != nil {
+		return 0, err
+	}
+	if duel.Status != "active" {
+		return 0, errors.New("duel is not active")
+	}
+	if !isParticipant(duel, user.ID) {
+		return 0, errors.New("user is not a participant of this duel")
+	}
+	opponents := opponentsOf(duel, user.ID)
+	if len(opponents) == 0 {
+		return 0, errors.New("there is no opponent to approve the check-in")
+	}
+	if duel.HabitDailyTarget > 0 && (value == nil || *value <= 0) {
+		return 0, fmt.Errorf("value in %s should be greater than 0", duel.HabitUnit)
+	}
+	// Окно времени отметок относится к моменту отправки, для прошедшего дня проверяется только содержимое
+	if err := checkProofContent(duel.ProofRules, message, photo); err != nil {
+		return 0, err
+	}
+	if err := s.checkBackfillDay(duel, user.ID, day); err != nil {
+		return 0, err
+	}
+
+	id, err := s.Repository.CreateBackfill(&models.BackfillDb{
+		DuelId:  duel_id,
+		OwnerId: user.ID,
+		Day:     day,
+		Message: message,
+		Photo:   photo,
+		Value:   value,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, opponent := range opponents {
+		s.notify(opponent, fmt.Sprintf("%s просит засчитать отметку за %s в дуэли «%s»: «%s». Подтвердите или отклоните её.",
+			user.FirstName, day, duel.HabitName, message))
+	}
+	return id, nil
+}
+
+// ResolveBackfill lets an opponent approve or reject a backfill request. An
+// approved check-in counts like a check-in made on that day and may end the duel.
+func (s *Service) ResolveBackfill(user_id int64, backfill_id int64, approved bool) error {
+	backfill, err := s.Repository.FindBackfillById(backfill_id)
+	if err != nil {
+		return err
+	}
+	duel, err := s.Repository.GetDuelById(backfill.DuelId)
+	if err != nil {
+		return err
+	}
+	isOpponent := false
+	for _, opponent := range opponentsOf(duel, backfill.OwnerId) {
+		isOpponent = isOpponent || opponent == user_id
+	}
+	if !isOpponent {
+		return errors.New("only an opponent can approve or reject the check-in")
+	}
+
+	if !approved {
+		if err := s.Repository.RejectBackfill(backfill_id, user_id); err != nil {
+			return err
+		}
+		s.notify(backfill.OwnerId, fmt.Sprintf("Соперник не подтвердил отметку за %s в дуэли «%s».", backfill.Day, duel.HabitName))
+		return nil
+	}
+
+	if duel.Status != "active" {
+		return errors.New("duel is not active")
+	}
+	// С момента запроса день мог засчитаться или недельная норма могла закончиться
+	if err := s.checkBackfillDay(duel, backfill.OwnerId, backfill.Day); err != nil {
+		return err
+	}
+	counted, err := s.Repository.ApproveBackfill(backfill_id, user_id, duel.HabitDailyTarget)
+	if err != nil {
+		return err
+	}
+	s.notify(backfill.OwnerId, fmt.Sprintf("Соперник подтвердил отметку за %s в дуэли «%s».", backfill.Day, duel.HabitName))
+	if !counted {
+		return nil
+	}
+
+	if err := s.evaluateAchievements(backfill.OwnerId, achievementEventCheckIn); err != nil {
+		return err
+	}
+	duel, err = s.Repository.GetDuelById(backfill.DuelId)
+	if err != nil {
+		return err
+	}
+	strategy, err := scoringFor(duel)
+	if err != nil {
+		return err
+	}
+	today, err := time.Parse(clock.DateLayout, s.Clock.Today())
+	if err != nil {
+		return err
+	}
+	if result := strategy.checkIn(duel, backfill.OwnerId, today); result.ended {
+		return s.finishDuel(duel, result)
+	}
+	return nil
+}
+
+// GetBackfills returns the backfill requests of the duel to its participants.
+func (s *Service) GetBackfills(user_id int64, duel_id int64) ([]models.BackfillDb, error) {
+	duel, err := s.Repository.GetDuelById(duel_id)
+	if err != nil {
+		return nil, err
+	}
+	if !isParticipant(duel, user_id) {
+		return nil, errors.New("user is not a participant of this duel")
+	}
+	return s.Repository.FindBackfillsByDuelId(duel_id)
+}
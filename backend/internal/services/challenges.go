@@ -0,0 +1,76 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maxbot/internal/models"
+)
+
+// notify sends a bot message to the user. A failed notification is only logged,
+// it must not undo the action it is about.
+func (s *Service) notify(user_id int64, text string) {
+	if s.Notifier == nil {
+		return
+	}
+	user, err := s.Repository.FindUserById(user_id)
+	if err == nil && user == nil {
+		err = errors.New("user does not exist")
+	}
+	if err == nil {
+		err = s.Notifier.Notify(user.MaxID, text)
+	}
+	if err != nil {
+		slog.Error("error while sending notification", "user_id", user_id, "error", err.Error())
+	}
+}
+
+// ChallengeUser creates a 1v1 duel and sends it directly to the chosen user,
+// found by the internal ID or, if it is 0, by the MAX ID.
+func (s *Service) ChallengeUser(user_id int64, targetId int64, targetMaxId string, habit_id int, settings models.DuelSettings) (*models.DuelDb, error) {
+	var target *models.UserDb
+	var err error
+	switch {
+	case targetId != 0:
+		target, err = s.Repository.FindUserById(targetId)
+	case targetMaxId != "":
+		target, err = s.Repository.FindUserByMaxId(targetMaxId)
+	default:
+		return nil, errors.New("opponent_id or opponent_max_id is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, errors.New("user does not exist")
+	}
+	if target.ID == user_id {
+		return nil, errors.New("you cannot challenge yourself")
+	}
+	if settings.TeamId != 0 || (settings.MaxParticipants != 0 && settings.MaxParticipants != 2) {
+		return nil, errors.New("only 1v1 duels can be sent as a challenge")
+	}
+
+	settings.MaxParticipants = 2
+	settings.InvitedUserId = target.ID
+	invitationHash, err := s.createDuel(user_id, habit_id, settings)
+	if err != nil {
+		return nil, err
+	}
+	duel, err := s.Repository.FindDuelByInvitationHash(invitationHash)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyChallenge(duel, invitationHash, "вызывает вас на дуэль")
+	return duel, nil
+}
+
+// notifyChallenge tells the invited user about a direct invitation.
+func (s *Service) notifyChallenge(duel *models.DuelDb, invitationHash string, action string) {
+	text := fmt.Sprintf("%s %s «%s» на %d дн.", duel.User1_firstName, action, duel.HabitName, duel.Duration)
+	if duel.Stake > 0 {
+		text += fmt.Sprintf(" Ставка: %d монет.", duel.Stake)
+	}
+	text += " Принять или отклонить: " + duelInvitationLink(invitationHash)
+	s.notify(duel.InvitedUserId.Int64, text)
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+)
+
+const (
+	maxDuelStake          = 10000
+	defaultCoinHistoryLen = 50
+	maxCoinHistoryLen     = 500
+)
+
+func (s *Service) GetCoinBalance(user_id int64) (int64, error) {
+	return s.Repository.FindCoinBalance(user_id)
+}
+
+func (s *Service) GetCoinHistory(user_id int64, limit int) ([]models.CoinEntryDb, error) {
+	if limit <= 0 {
+		limit = defaultCoinHistoryLen
+	}
+	return s.Repository.FindCoinHistory(user_id, min(limit, maxCoinHistoryLen))
+}
+
+func (s *Service) AuditCoins() (*dto.CoinAuditDto, error) {
+	return s.Repository.AuditCoinLedger()
+}
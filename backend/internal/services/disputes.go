@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"maxbot/internal/clock"
+	"maxbot/internal/models"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// disputeWindowDays is how many days after the check-in day, and after the
+	// end of the duel, its log can still be disputed
+	disputeWindowDays = 1
+	maxDisputeReason  = 500
+	disputesPageSize  = 100
+)
+
+// DisputeLog lets an opponent challenge a log of the duel within the dispute window.
+// With exclude the log stops counting until the dispute is resolved. The log that
+// decided a duel can still be disputed shortly after the end; exclude is ignored then.
+func (s *Service) DisputeLog(user *models.UserDb, log_id int64, reason string, exclude bool) (int64, error) {
+	if length := utf8.RuneCountInString(reason); length == 0 || length > maxDisputeReason {
+		return 0, errors.New("reason should be from 1 to 500 characters")
+	}
+	log, err := s.Repository.FindLogById(log_id)
+	if err != nil {
+		return 0, err
+	}
+	duel, err := s.Repository.GetDuelById(log.DuelID)
+	if err != nil {
+		return 0, err
+	}
+	if duel.Status != "active" && duel.Status != "ended" {
+		return 0, errors.New("logs can only be disputed while the duel is active or shortly after it ends")
+	}
+	if !isParticipant(duel, user.ID) {
+		return 0, errors.New("you are not a participant of this duel")
+	}
+	if competitorID(duel, user.ID) == competitorID(duel, log.OwnerID) {
+		return 0, errors.New("only opponents can dispute a log")
+	}
+
+	today, err := time.Parse(clock.DateLayout, s.Clock.Today())
+	if err != nil {
+		return 0, err
+	}
+	logDate, err := time.Parse(clock.DateLayout, log.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	if logDate.AddDate(0, 0, disputeWindowDays).Before(today) {
+		return 0, errors.New("the dispute window for this log has passed")
+	}
+	if duel.Status == "ended" {
+		endDate, err := time.Parse(clock.DateLayout, duel.EndDate.String)
+		if err != nil {
+			return 0, err
+		}
+		if endDate.AddDate(0, 0, disputeWindowDays).Before(today) {
+			return 0, errors.New("the dispute window for this duel has passed")
+		}
+		tournamentId, err := s.Repository.FindTournamentIdByDuelId(duel.Id)
+		if err != nil {
+			return 0, err
+		}
+		if tournamentId != 0 {
+			return 0, errors.New("the duel decided a tournament match, its result can no longer change")
+		}
+		exclude = false
+	}
+
+	disputeId, err := s.Repository.CreateDispute(&models.DisputeDb{
+		LogId:      log.ID,
+		DuelId:     log.DuelID,
+		OwnerId:    log.OwnerID,
+		DisputerId: user.ID,
+		Reason:     reason,
+		Excluded:   exclude,
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.notify(log.OwnerID, fmt.Sprintf("%s оспаривает вашу отметку %s в дуэли «%s»: %s",
+		user.FirstName, log.CreatedAt, duel.HabitName, reason))
+	return disputeId, nil
+}
+
+// ConcedeDispute is the log owner agreeing with the dispute: the log stops counting.
+func (s *Service) ConcedeDispute(user_id int64, dispute_id int64) error {
+	dispute, err := s.Repository.FindDisputeById(dispute_id)
+	if err != nil {
+		return err
+	}
+	if dispute.OwnerId != user_id {
+		return errors.New("only the author of the log can concede the dispute")
+	}
+	if err := s.resolveDispute(dispute, models.DisputeStatusUpheld, models.DisputeResolutionConceded, user_id); err != nil {
+		return err
+	}
+	s.notify(dispute.DisputerId, "Соперник согласился с вашим спором: отметка не засчитана.")
+	return nil
+}
+
+// WithdrawDispute is the opponent taking the dispute back: the log counts again.
+func (s *Service) WithdrawDispute(user_id int64, dispute_id int64) error {
+	dispute, err := s.Repository.FindDisputeById(dispute_id)
+	if err != nil {
+		return err
+	}
+	if dispute.DisputerId != user_id {
+		return errors.New("only the opponent who opened the dispute can withdraw it")
+	}
+	if err := s.resolveDispute(dispute, models.DisputeStatusRejected, models.DisputeResolutionWithdrawn, user_id); err != nil {
+		return err
+	}
+	s.notify(dispute.OwnerId, "Соперник отозвал спор: ваша отметка засчитана.")
+	return nil
+}
+
+// AdminResolveDispute settles the dispute: upheld means the log does not count.
+func (s *Service) AdminResolveDispute(admin_id int64, dispute_id int64, upheld bool) error {
+	dispute, err := s.Repository.FindDisputeById(dispute_id)
+	if err != nil {
+		return err
+	}
+	status, verdict := models.DisputeStatusRejected, "отметка засчитана"
+	if upheld {
+		status, verdict = models.DisputeStatusUpheld, "отметка не засчитана"
+	}
+	if err := s.resolveDispute(dispute, status, models.DisputeResolutionAdmin, admin_id); err != nil {
+		return err
+	}
+	text := fmt.Sprintf("Администратор решил спор по отметке №%d: %s.", dispute.LogId, verdict)
+	s.notify(dispute.OwnerId, text)
+	s.notify(dispute.DisputerId, text)
+	return nil
+}
+
+func (s *Service) GetOpenDisputes() ([]models.DisputeDb, error) {
+	return s.Repository.FindOpenDisputes(disputesPageSize)
+}
+
+// resolveDispute closes the dispute. A log counted again may end an active duel
+// just like a new check-in; an ended duel that the log decided is reopened and
+// decided again.
+func (s *Service) resolveDispute(dispute *models.DisputeDb, status string, resolution string, resolved_by int64) error {
+	recounted, reopened, err := s.Repository.ResolveDispute(dispute.Id, status, resolution, resolved_by, s.decideReopenedDuel)
+	if err != nil {
+		return err
+	}
+	if reopened {
+		return s.announceRedecidedDuel(dispute.DuelId, fmt.Sprintf("Спор по отметке №%d решён", dispute.LogId), 0)
+	}
+	if !recounted {
+		return nil
+	}
+
+	duel, err := s.Repository.GetDuelById(dispute.DuelId)
+	if err != nil {
+		return err
+	}
+	strategy, err := scoringFor(duel)
+	if err != nil {
+		return err
+	}
+	today, err := time.Parse(clock.DateLayout, s.Clock.Today())
+	if err != nil {
+		return err
+	}
+	if result := strategy.checkIn(duel, dispute.OwnerId, today); result.ended {
+		return s.finishDuel(duel, result)
+	}
+	return nil
+}
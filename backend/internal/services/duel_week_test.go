@@ -0,0 +1,219 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/clock"
+	"maxbot/internal/models"
+	"maxbot/internal/notifier"
+	"maxbot/internal/repository"
+	"testing"
+	"time"
+)
+
+// memoryRepository keeps users and one duel in memory, enough to play the duel
+// through the service. Methods the tests do not need are left to the embedded
+// nil interface and panic if called.
+type memoryRepository struct {
+	repository.RepositoryInterface
+	clock clock.Clock
+	users map[int64]*models.UserDb
+	duel  models.DuelDb
+	logs  []models.LogDB
+	coins map[int64]int64
+}
+
+func newMemoryRepository(c clock.Clock, duel models.DuelDb, users ...models.UserDb) *memoryRepository {
+	repo := &memoryRepository{clock: c, users: map[int64]*models.UserDb{}, duel: duel, coins: map[int64]int64{}}
+	for i := range users {
+		repo.users[users[i].ID] = &users[i]
+	}
+	return repo
+}
+
+func (m *memoryRepository) FindUserById(id int64) (*models.UserDb, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return nil, errors.New("user does not exist")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (m *memoryRepository) GetDuelById(duel_id int64) (*models.DuelDb, error) {
+	if duel_id != int64(m.duel.Id) {
+		return nil, errors.New("custom error: no rows in duels result set")
+	}
+	copied := m.duel
+	copied.Participants = append([]models.ParticipantDb(nil), m.duel.Participants...)
+	return &copied, nil
+}
+
+func (m *memoryRepository) HasUserContributedToDuelToday(userID int64, duelID int64, date string) (bool, error) {
+	for _, log := range m.logs {
+		if log.OwnerID == userID && log.DuelID == duelID && log.CreatedAt == date && log.Counted {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryRepository) CreateDuelLog(log *models.LogDB) error {
+	log.ID = int64(len(m.logs) + 1)
+	log.CreatedAt = m.clock.Today()
+	log.LoggedAt = sql.NullTime{Time: m.clock.Now(), Valid: true}
+	m.logs = append(m.logs, *log)
+	return nil
+}
+
+func (m *memoryRepository) IncrementDuelCounter(duel *models.DuelDb, userID int64) (int64, error) {
+	for i := range m.duel.Participants {
+		if participant := &m.duel.Participants[i]; participant.UserId == userID {
+			participant.Completed++
+			return participant.Completed, nil
+		}
+	}
+	return 0, errors.New("user is not a participant of this duel")
+}
+
+func (m *memoryRepository) IncrementUserStreakAndUpdateLastTimeContributed(user *models.UserDb) error {
+	m.users[user.ID].Streak++
+	m.users[user.ID].LastTimeContributed = sql.NullString{String: m.clock.Today(), Valid: true}
+	return nil
+}
+
+func (m *memoryRepository) ResetUserStreakToOneAndUpdateLastTimeContributed(user *models.UserDb) error {
+	m.users[user.ID].Streak = 1
+	m.users[user.ID].LastTimeContributed = sql.NullString{String: m.clock.Today(), Valid: true}
+	return nil
+}
+
+func (m *memoryRepository) AwardStreakFreeze(user *models.UserDb) error {
+	m.users[user.ID].StreakFreezes++
+	return nil
+}
+
+func (m *memoryRepository) RewardCoins(user_id int64, amount int64, reason string, duel_id int64) error {
+	m.coins[user_id] += amount
+	return nil
+}
+
+func (m *memoryRepository) FindAchievementStats(user_id int64, comebackDeficit int) (*models.AchievementStatsDb, error) {
+	return &models.AchievementStatsDb{}, nil
+}
+
+func (m *memoryRepository) AwardAchievements(user_id int64, codes []string) error {
+	return nil
+}
+
+func (m *memoryRepository) EndDuel(duelID int, winnerID sql.NullInt64, endDate string, places map[int64]int, forfeitedBy sql.NullInt64) error {
+	m.duel.Status = "ended"
+	m.duel.WinnerId = winnerID
+	m.duel.EndDate = sql.NullString{String: endDate, Valid: true}
+	for i := range m.duel.Participants {
+		place := places[m.duel.Participants[i].UserId]
+		m.duel.Participants[i].Place = sql.NullInt64{Int64: int64(place), Valid: place != 0}
+	}
+	if winnerID.Valid {
+		m.users[winnerID.Int64].Wins++
+	}
+	return nil
+}
+
+func (m *memoryRepository) FindTournamentIdByDuelId(duel_id int) (int64, error) {
+	return 0, nil
+}
+
+// TestDuelWeek plays a 7-day first-to-target duel day by day on the fake clock:
+// Alice checks in every day, Bob skips days 3 and 7.
+func TestDuelWeek(t *testing.T) {
+	const aliceID, bobID, duelID = 1, 2, 10
+	fake := clock.NewFake(time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC))
+	repo := newMemoryRepository(fake,
+		models.DuelDb{
+			Id:          duelID,
+			Duration:    7,
+			Target:      7,
+			Schedule:    models.Schedule{Type: models.ScheduleDaily},
+			HabitName:   "Push-ups",
+			ScoringMode: models.ScoringFirstToTarget,
+			DuelType:    models.DuelTypeIndividual,
+			User1_id:    aliceID,
+			User2_id:    sql.NullInt64{Int64: bobID, Valid: true},
+			StartDate:   fake.Today(),
+			Status:      "active",
+			Participants: []models.ParticipantDb{
+				{UserId: aliceID, FirstName: "Alice"},
+				{UserId: bobID, FirstName: "Bob"},
+			},
+		},
+		models.UserDb{ID: aliceID, FirstName: "Alice"},
+		models.UserDb{ID: bobID, FirstName: "Bob"},
+	)
+	s := &Service{Repository: repo, Clock: fake, Notifier: notifier.Log{}}
+
+	checkIn := func(userID int64) error {
+		user, err := repo.FindUserById(userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.CreateDuelLog(user, userID, duelID, "done for today", nil, nil)
+	}
+	streakOf := func(userID int64) int {
+		return repo.users[userID].Streak
+	}
+
+	for day := 1; day <= 7; day++ {
+		if day > 1 {
+			fake.AdvanceDays(1)
+		}
+		if err := checkIn(aliceID); err != nil {
+			t.Fatalf("day %d: Alice check-in: %v", day, err)
+		}
+		if day == 1 {
+			fake.Advance(2 * time.Hour)
+			if err := checkIn(aliceID); err == nil {
+				t.Fatal("day 1: a second check-in on the same day should be rejected")
+			}
+		}
+		if day != 3 && day != 7 {
+			if err := checkIn(bobID); err != nil {
+				t.Fatalf("day %d: Bob check-in: %v", day, err)
+			}
+		}
+
+		if got := streakOf(aliceID); got != day {
+			t.Errorf("day %d: Alice streak = %d, want %d", day, got, day)
+		}
+		// Bob's streak starts over after the missed day 3
+		wantBob := map[int]int{1: 1, 2: 2, 3: 2, 4: 1, 5: 2, 6: 3, 7: 3}[day]
+		if got := streakOf(bobID); got != wantBob {
+			t.Errorf("day %d: Bob streak = %d, want %d", day, got, wantBob)
+		}
+		if day < 7 && repo.duel.Status != "active" {
+			t.Fatalf("day %d: duel status = %q, want active", day, repo.duel.Status)
+		}
+	}
+
+	if repo.duel.Status != "ended" {
+		t.Fatalf("duel status = %q, want ended", repo.duel.Status)
+	}
+	if repo.duel.WinnerId != (sql.NullInt64{Int64: aliceID, Valid: true}) {
+		t.Errorf("winner = %v, want Alice", repo.duel.WinnerId)
+	}
+	if repo.duel.EndDate.String != fake.Today() {
+		t.Errorf("end date = %s, want %s", repo.duel.EndDate.String, fake.Today())
+	}
+	if got := repo.users[aliceID].Wins; got != 1 {
+		t.Errorf("Alice wins = %d, want 1", got)
+	}
+	if got := repo.users[bobID].Wins; got != 0 {
+		t.Errorf("Bob wins = %d, want 0", got)
+	}
+	if got, want := repo.coins[bobID], int64(5*models.CoinsPerCheckIn); got != want {
+		t.Errorf("Bob coins = %d, want %d for 5 check-ins", got, want)
+	}
+	if err := checkIn(bobID); err == nil {
+		t.Error("a check-in into the ended duel should be rejected")
+	}
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+)
+
+const friendSuggestionsLimit = 20
+
+func (s *Service) findOtherUser(user_id int64, other_id int64) (*models.UserDb, error) {
+	if user_id == other_id {
+		return nil, errors.New("choose another user")
+	}
+	other, err := s.Repository.FindUserById(other_id)
+	if err != nil {
+		return nil, err
+	}
+	if other == nil {
+		return nil, errors.New("user does not exist")
+	}
+	return other, nil
+}
+
+// SendFriendRequest asks the other user for friendship; a request to someone
+// who has already asked the user accepts theirs. Returns the friendship status.
+func (s *Service) SendFriendRequest(user *models.UserDb, other_id int64) (string, error) {
+	if _, err := s.findOtherUser(user.ID, other_id); err != nil {
+		return "", err
+	}
+	status, err := s.Repository.SendFriendRequest(user.ID, other_id)
+	if err != nil {
+		return "", err
+	}
+	if status == models.FriendshipAccepted {
+		s.notify(other_id, fmt.Sprintf("%s принял(а) вашу заявку в друзья.", user.FirstName))
+	} else {
+		s.notify(other_id, fmt.Sprintf("%s хочет добавить вас в друзья.", user.FirstName))
+	}
+	return status, nil
+}
+
+func (s *Service) AcceptFriendRequest(user *models.UserDb, requester_id int64) error {
+	if err := s.Repository.AcceptFriendRequest(user.ID, requester_id); err != nil {
+		return err
+	}
+	s.notify(requester_id, fmt.Sprintf("%s принял(а) вашу заявку в друзья.", user.FirstName))
+	return nil
+}
+
+func (s *Service) RemoveFriend(user_id int64, other_id int64) error {
+	return s.Repository.RemoveFriend(user_id, other_id)
+}
+
+// GetFriends returns the friends of the user with their active duels.
+func (s *Service) GetFriends(user_id int64) ([]models.FriendDb, error) {
+	friends, err := s.Repository.FindFriends(user_id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range friends {
+		friends[i].Duels, err = s.Repository.FindActiveDuelsByUserId(friends[i].UserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return friends, nil
+}
+
+func (s *Service) GetFriendRequests(user_id int64) ([]models.FriendRequestDb, error) {
+	return s.Repository.FindFriendRequests(user_id)
+}
+
+func (s *Service) GetFriendSuggestions(user_id int64) ([]models.FriendSuggestionDb, error) {
+	return s.Repository.FindFriendSuggestions(user_id, friendSuggestionsLimit)
+}
+
+func (s *Service) BlockUser(user_id int64, other_id int64) error {
+	if _, err := s.findOtherUser(user_id, other_id); err != nil {
+		return err
+	}
+	return s.Repository.BlockUser(user_id, other_id)
+}
+
+func (s *Service) UnblockUser(user_id int64, other_id int64) error {
+	return s.Repository.UnblockUser(user_id, other_id)
+}
+
+func (s *Service) GetBlockedUsers(user_id int64) ([]models.BlockedUserDb, error) {
+	return s.Repository.FindBlockedUsers(user_id)
+}
+
+func (s *Service) GetVisibility(user_id int64) (string, error) {
+	return s.Repository.FindVisibility(user_id)
+}
+
+func (s *Service) SetVisibility(user_id int64, visibility string) error {
+	if visibility != models.VisibilityEveryone && visibility != models.VisibilityFriends {
+		return errors.New("visibility should be one of: everyone, friends")
+	}
+	return s.Repository.SetVisibility(user_id, visibility)
+}
+
+// CanView reports whether the viewer may see the profile and logs of the owner.
+// viewer_id 0 is an anonymous viewer. Blocks hide users from each other.
+func (s *Service) CanView(viewer_id int64, owner_id int64) (bool, error) {
+	if viewer_id == owner_id {
+		return true, nil
+	}
+	if viewer_id != 0 {
+		blocked, err := s.Repository.IsBlocked(viewer_id, owner_id)
+		if err != nil || blocked {
+			return false, err
+		}
+	}
+	visibility, err := s.Repository.FindVisibility(owner_id)
+	if err != nil {
+		return false, err
+	}
+	if visibility == models.VisibilityEveryone {
+		return true, nil
+	}
+	if viewer_id == 0 {
+		return false, nil
+	}
+	return s.Repository.AreFriends(viewer_id, owner_id)
+}
+
+// GetUserProfile returns the public profile of a user. A profile hidden from
+// the viewer only has the name and the photo.
+func (s *Service) GetUserProfile(viewer_id int64, user_id int64) (*dto.ProfileDto, error) {
+	user, err := s.Repository.FindUserById(user_id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user does not exist")
+	}
+	profile := dto.ProfileDto{
+		UserId:    user.ID,
+		FirstName: user.FirstName,
+		PhotoUrl:  user.PhotoUrl,
+	}
+	if viewer_id != 0 && viewer_id != user_id {
+		if profile.IsFriend, err = s.Repository.AreFriends(viewer_id, user_id); err != nil {
+			return nil, err
+		}
+	}
+
+	canView, err := s.CanView(viewer_id, user_id)
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		profile.Hidden = true
+		return &profile, nil
+	}
+
+	profile.Streak = user.Streak
+	profile.Wins = user.Wins
+	profile.Rating = user.Rating
+	if profile.Achievements, err = s.GetUserAchievements(user_id); err != nil {
+		return nil, err
+	}
+	if profile.ActiveDuels, err = s.Repository.FindActiveDuelsByUserId(user_id); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+)
+
+// GetHeadToHead returns the record of the user against the opponent: totals,
+// the current series, a breakdown by habit and the rematch chains.
+func (s *Service) GetHeadToHead(user_id int64, opponent_id int64) (*dto.HeadToHeadDto, error) {
+	if user_id == opponent_id {
+		return nil, errors.New("choose another user as the opponent")
+	}
+	opponent, err := s.Repository.FindUserById(opponent_id)
+	if err != nil {
+		return nil, err
+	}
+	if opponent == nil {
+		return nil, errors.New("user does not exist")
+	}
+	duels, err := s.Repository.FindHeadToHeadDuels(user_id, opponent_id)
+	if err != nil {
+		return nil, err
+	}
+
+	headToHead := dto.HeadToHeadDto{
+		OpponentId:        opponent.ID,
+		OpponentFirstName: opponent.FirstName,
+		OpponentPhotoUrl:  opponent.PhotoUrl,
+		Habits:            []dto.HeadToHeadHabitDto{},
+		RematchChains:     []dto.RematchChainDto{},
+		Duels:             make([]models.HeadToHeadDuelDb, 0, len(duels)),
+	}
+
+	type habitKey struct{ name, category string }
+	habits := map[habitKey]int{}
+	chains := map[int64]int{} // Дуэль -> индекс её цепочки реваншей
+	for _, duel := range duels {
+		countResult(duel.Result, &headToHead.Wins, &headToHead.Losses, &headToHead.Draws)
+
+		if duel.Result == headToHead.SeriesResult {
+			headToHead.SeriesLength++
+		} else {
+			headToHead.SeriesResult, headToHead.SeriesLength = duel.Result, 1
+		}
+
+		key := habitKey{duel.HabitName, duel.HabitCategory}
+		i, ok := habits[key]
+		if !ok {
+			i = len(headToHead.Habits)
+			habits[key] = i
+			headToHead.Habits = append(headToHead.Habits, dto.HeadToHeadHabitDto{HabitName: duel.HabitName, HabitCategory: duel.HabitCategory})
+		}
+		habit := &headToHead.Habits[i]
+		countResult(duel.Result, &habit.Wins, &habit.Losses, &habit.Draws)
+
+		// Rematches end after the duel they follow, so the chain is already known
+		chain, ok := chains[duel.RematchOf.Int64]
+		if !duel.RematchOf.Valid || !ok {
+			chain = len(headToHead.RematchChains)
+			headToHead.RematchChains = append(headToHead.RematchChains, dto.RematchChainDto{})
+		}
+		chains[duel.DuelId] = chain
+		rematchChain := &headToHead.RematchChains[chain]
+		rematchChain.DuelIds = append(rematchChain.DuelIds, duel.DuelId)
+		countResult(duel.Result, &rematchChain.Wins, &rematchChain.Losses, &rematchChain.Draws)
+	}
+
+	// A single duel is not a chain
+	var rematchChains []dto.RematchChainDto = []dto.RematchChainDto{}
+	for _, chain := range headToHead.RematchChains {
+		if len(chain.DuelIds) > 1 {
+			rematchChains = append(rematchChains, chain)
+		}
+	}
+	headToHead.RematchChains = rematchChains
+
+	for i := len(duels) - 1; i >= 0; i-- {
+		headToHead.Duels = append(headToHead.Duels, duels[i])
+	}
+	return &headToHead, nil
+}
+
+func countResult(result string, wins *int, losses *int, draws *int) {
+	switch result {
+	case models.HeadToHeadWin:
+		*wins++
+	case models.HeadToHeadLoss:
+		*losses++
+	default:
+		*draws++
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maxbot/internal/dto"
+)
+
+const (
+	defaultLeaderboardLimit = 20
+	maxLeaderboardLimit     = 100
+)
+
+// leaderboardColumns maps a metric and a window to the aggregate column.
+// Streak and rating are current values, so they have no windows.
+var leaderboardColumns = map[string]map[string]string{
+	"wins":        {"week": "wins_week", "month": "wins_month", "all": "wins_all"},
+	"completions": {"week": "completions_week", "month": "completions_month", "all": "completions_all"},
+	"streak":      {"all": "streak"},
+	"rating":      {"all": "rating"},
+}
+
+// RefreshLeaderboards recalculates the aggregates behind the leaderboards.
+func (s *Service) RefreshLeaderboards() error {
+	return s.Repository.RefreshLeaderboardStats()
+}
+
+// GetLeaderboard returns a page of the global or friends leaderboard. The
+// cursor is the next_cursor of the previous page, empty for the first page.
+func (s *Service) GetLeaderboard(user_id int64, metric string, window string, scope string, cursor string, limit int) (*dto.LeaderboardDto, error) {
+	windows, ok := leaderboardColumns[metric]
+	if !ok {
+		return nil, errors.New("metric should be one of: wins, streak, rating, completions")
+	}
+	if window == "" || metric == "streak" || metric == "rating" {
+		window = "all"
+	}
+	column, ok := windows[window]
+	if !ok {
+		return nil, errors.New("window should be one of: week, month, all")
+	}
+
+	var viewerID int64
+	switch scope {
+	case "", "global":
+		scope = "global"
+	case "friends":
+		viewerID = user_id
+	default:
+		return nil, errors.New("scope should be one of: global, friends")
+	}
+
+	if limit == 0 {
+		limit = defaultLeaderboardLimit
+	}
+	if limit < 1 || limit > maxLeaderboardLimit {
+		return nil, fmt.Errorf("limit should be from 1 to %d", maxLeaderboardLimit)
+	}
+
+	var afterScore, afterUserID int64
+	hasCursor := cursor != ""
+	if hasCursor {
+		raw, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		if _, err := fmt.Sscanf(string(raw), "%d:%d", &afterScore, &afterUserID); err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+	}
+
+	entries, err := s.Repository.FindLeaderboard(column, viewerID, hasCursor, afterScore, afterUserID, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := &dto.LeaderboardDto{Metric: metric, Window: window, Scope: scope, Entries: entries}
+	if len(entries) == limit {
+		last := entries[len(entries)-1]
+		result.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", last.Score, last.UserId)))
+	}
+	return result, nil
+}
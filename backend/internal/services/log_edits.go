@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"maxbot/internal/clock"
+	"maxbot/internal/models"
+	"time"
+	"unicode/utf8"
+)
+
+const defaultLogEditWindow = 15 * time.Minute
+
+func (s *Service) logEditWindow() time.Duration {
+	if s.LogEditWindow > 0 {
+		return s.LogEditWindow
+	}
+	return defaultLogEditWindow
+}
+
+// findEditableLog returns the log if the user owns it and the edit window is still open.
+func (s *Service) findEditableLog(user_id int64, log_id int64) (*models.LogDB, error) {
+	log, err := s.Repository.FindLogById(log_id)
+	if err != nil {
+		return nil, err
+	}
+	if log.OwnerID != user_id {
+		return nil, errors.New("you can only change your own logs")
+	}
+	window := s.logEditWindow()
+	if !log.LoggedAt.Valid || s.Clock.Now().Sub(log.LoggedAt.Time) > window {
+		return nil, fmt.Errorf("logs can only be changed within %d minutes after sending", int(window.Minutes()))
+	}
+	return log, nil
+}
+
+// EditDuelLog replaces the message of the log and, if a new photo is given or
+// removePhoto is set, its photo. The result must still meet the proof rules.
+func (s *Service) EditDuelLog(user_id int64, log_id int64, message string, photo []byte, removePhoto bool) error {
+	if utf8.RuneCountInString(message) > maxMessageLength {
+		return errors.New("message too long (max 500 characters)")
+	}
+	log, err := s.findEditableLog(user_id, log_id)
+	if err != nil {
+		return err
+	}
+
+	newPhoto := log.Photo
+	switch {
+	case len(photo) > 0:
+		newPhoto = &photo
+	case removePhoto:
+		newPhoto = nil
+	}
+
+	duel, err := s.Repository.GetDuelById(log.DuelID)
+	if err != nil {
+		return err
+	}
+	var photoBytes []byte
+	if newPhoto != nil {
+		photoBytes = *newPhoto
+	}
+	// Время отправки не меняется, поэтому проверяется только содержимое
+	if err := checkProofContent(duel.ProofRules, message, photoBytes); err != nil {
+		return err
+	}
+	return s.Repository.UpdateLog(log.ID, message, newPhoto)
+}
+
+// DeleteDuelLog deletes the log and rolls back the day it counted. If the log
+// decided an ended duel, the duel is reopened and decided again without it.
+func (s *Service) DeleteDuelLog(user_id int64, log_id int64) error {
+	log, err := s.findEditableLog(user_id, log_id)
+	if err != nil {
+		return err
+	}
+	duel, err := s.Repository.GetDuelById(log.DuelID)
+	if err != nil {
+		return err
+	}
+	reopened, err := s.Repository.DeleteLog(log.ID, duel.HabitDailyTarget, s.decideReopenedDuel)
+	if err != nil || !reopened {
+		return err
+	}
+	return s.announceRedecidedDuel(log.DuelID, fmt.Sprintf("Отметка в дуэли «%s» удалена", duel.HabitName), user_id)
+}
+
+// decideReopenedDuel ends a reopened duel again if its period is over or a
+// player still has reached the target; otherwise the duel goes on. It runs
+// inside the transaction that reopened the duel.
+func (s *Service) decideReopenedDuel(duel *models.DuelDb, countedDays func(userID int64, duelID int64) ([]string, error)) (*models.DuelOutcome, error) {
+	strategy, err := scoringFor(duel)
+	if err != nil {
+		return nil, err
+	}
+	today, err := time.Parse(clock.DateLayout, s.Clock.Today())
+	if err != nil {
+		return nil, err
+	}
+	result, err := strategy.review(countedDays, duel, today)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; !result.ended && i < len(duel.Participants); i++ {
+		result = strategy.checkIn(duel, duel.Participants[i].UserId, today)
+	}
+	if !result.ended {
+		return nil, nil
+	}
+	return &models.DuelOutcome{
+		WinnerId: result.winner(),
+		EndDate:  result.endDate.Format(clock.DateLayout),
+		Places:   result.places,
+	}, nil
+}
+
+// announceRedecidedDuel tells the participants, except the one who caused it,
+// that the result of the duel was decided again, and evaluates the achievements
+// if the duel has ended again.
+func (s *Service) announceRedecidedDuel(duel_id int64, reason string, except_id int64) error {
+	duel, err := s.Repository.GetDuelById(duel_id)
+	if err != nil {
+		return err
+	}
+	message := fmt.Sprintf("%s, итог дуэли пересчитан: дуэль продолжается.", reason)
+	if duel.Status == "ended" {
+		message = fmt.Sprintf("%s, итог дуэли пересчитан.", reason)
+	}
+	for _, participant := range duel.Participants {
+		if participant.UserId != except_id {
+			s.notify(participant.UserId, message)
+		}
+	}
+	if duel.Status != "ended" {
+		return nil
+	}
+	for _, participant := range duel.Participants {
+		if err := s.evaluateAchievements(participant.UserId, achievementEventDuelEnded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
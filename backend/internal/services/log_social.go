@@ -0,0 +1,132 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"maxbot/internal/models"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxCommentLength = 300
+
+// canViewDuelLogsOf reports whether the viewer may see the logs of the owner in
+// the duel: participants see each other unless one has blocked the other,
+// everyone else depends on the owner's visibility.
+func (s *Service) canViewDuelLogsOf(viewer_id int64, duel *models.DuelDb, owner_id int64) (bool, error) {
+	if viewer_id == owner_id {
+		return true, nil
+	}
+	if viewer_id != 0 && isParticipant(duel, viewer_id) {
+		blocked, err := s.Repository.IsBlocked(viewer_id, owner_id)
+		return !blocked, err
+	}
+	return s.CanView(viewer_id, owner_id)
+}
+
+// findVisibleLog returns the log with its duel if the viewer may see it.
+func (s *Service) findVisibleLog(viewer_id int64, log_id int64) (*models.LogDB, *models.DuelDb, error) {
+	log, err := s.Repository.FindLogById(log_id)
+	if err != nil {
+		return nil, nil, err
+	}
+	duel, err := s.Repository.GetDuelById(log.DuelID)
+	if err != nil {
+		return nil, nil, err
+	}
+	canView, err := s.canViewDuelLogsOf(viewer_id, duel, log.OwnerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canView {
+		return nil, nil, errors.New("log does not exist")
+	}
+	return log, duel, nil
+}
+
+// ReactToLog sets the user's reaction to someone else's log, an empty emoji removes it.
+func (s *Service) ReactToLog(user_id int64, log_id int64, emoji string) error {
+	if emoji != "" && !slices.Contains(models.LogReactions, emoji) {
+		return fmt.Errorf("emoji should be one of: %s", strings.Join(models.LogReactions, " "))
+	}
+	log, _, err := s.findVisibleLog(user_id, log_id)
+	if err != nil {
+		return err
+	}
+	if log.OwnerID == user_id {
+		return errors.New("you cannot react to your own log")
+	}
+	return s.Repository.SetReaction(log_id, user_id, emoji)
+}
+
+// CommentOnLog adds a comment to the log or, with parent_id, a reply to another
+// comment. The author of the log and of the parent comment are notified.
+func (s *Service) CommentOnLog(user *models.UserDb, log_id int64, parent_id int64, message string) (int64, error) {
+	message = strings.TrimSpace(message)
+	if length := utf8.RuneCountInString(message); length == 0 || length > maxCommentLength {
+		return 0, fmt.Errorf("comment should be from 1 to %d characters", maxCommentLength)
+	}
+	log, duel, err := s.findVisibleLog(user.ID, log_id)
+	if err != nil {
+		return 0, err
+	}
+	var parent *models.LogCommentDb
+	if parent_id != 0 {
+		if parent, err = s.Repository.FindCommentById(parent_id); err != nil {
+			return 0, err
+		}
+		if parent.LogId != log_id {
+			return 0, errors.New("the comment you reply to belongs to another log")
+		}
+	}
+
+	commentId, err := s.Repository.CreateComment(&models.LogCommentDb{
+		LogId:    log_id,
+		ParentId: sql.NullInt64{Int64: parent_id, Valid: parent_id != 0},
+		UserId:   user.ID,
+		Message:  message,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if log.OwnerID != user.ID {
+		s.notify(log.OwnerID, fmt.Sprintf("%s прокомментировал(а) вашу отметку в дуэли «%s»: %s",
+			user.FirstName, duel.HabitName, message))
+	}
+	if parent != nil && parent.UserId != user.ID && parent.UserId != log.OwnerID {
+		s.notify(parent.UserId, fmt.Sprintf("%s ответил(а) на ваш комментарий в дуэли «%s»: %s",
+			user.FirstName, duel.HabitName, message))
+	}
+	return commentId, nil
+}
+
+// GetLogComments returns the comments on the log the viewer may see, without
+// those of users blocked with the viewer. viewer_id 0 is an anonymous viewer.
+func (s *Service) GetLogComments(viewer_id int64, log_id int64) ([]models.LogCommentDb, error) {
+	if _, _, err := s.findVisibleLog(viewer_id, log_id); err != nil {
+		return nil, err
+	}
+	comments, err := s.Repository.FindLogComments(log_id)
+	if err != nil || viewer_id == 0 {
+		return comments, err
+	}
+
+	blocked := map[int64]bool{viewer_id: false}
+	var visible []models.LogCommentDb = []models.LogCommentDb{}
+	for _, comment := range comments {
+		isBlocked, ok := blocked[comment.UserId]
+		if !ok {
+			if isBlocked, err = s.Repository.IsBlocked(viewer_id, comment.UserId); err != nil {
+				return nil, err
+			}
+			blocked[comment.UserId] = isBlocked
+		}
+		if !isBlocked {
+			visible = append(visible, comment)
+		}
+	}
+	return visible, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"sort"
+)
+
+// lobbyRatingGap is the largest rating difference between matched players.
+const lobbyRatingGap = 200
+
+func ratingGap(a int, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// PostToLobby looks for an open entry with the same habit category and
+// duration and the closest rating. If there is none, the user's own entry is
+// published with a new duel waiting for an opponent.
+func (s *Service) PostToLobby(user_id int64, habit_id int, days int) (*dto.LobbyPostResultDto, error) {
+	if days < 1 || days > 30 {
+		return nil, errors.New("days value should be from 1 to 30")
+	}
+	habit, err := s.Repository.FindHabitById(habit_id)
+	if err != nil {
+		return nil, err
+	}
+	rating, err := s.Repository.FindUserSkillRating(user_id, habit.Category)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.Repository.FindLobbyEntries(user_id, habit.Category, days)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []models.LobbyEntryDb
+	for _, entry := range entries {
+		if entry.UserId == user_id {
+			return nil, errors.New("you already have an open lobby entry for this habit category and duration")
+		}
+		if ratingGap(entry.Rating, rating) <= lobbyRatingGap {
+			candidates = append(candidates, entry)
+		}
+	}
+	// Ближайший по рейтингу, при равенстве - тот, кто ждёт дольше
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return ratingGap(candidates[i].Rating, rating) < ratingGap(candidates[j].Rating, rating)
+	})
+	for _, candidate := range candidates {
+		// The entry may be taken by someone else in the meantime, then try the next one
+		if err := s.joinLobbyEntry(user_id, &candidate); err == nil {
+			return &dto.LobbyPostResultDto{Matched: true, DuelId: candidate.DuelId}, nil
+		}
+	}
+
+	invitationHash, err := s.createDuel(user_id, habit_id, models.DuelSettings{Days: days})
+	if err != nil {
+		return nil, err
+	}
+	duel, err := s.Repository.FindDuelByInvitationHash(invitationHash)
+	if err != nil {
+		return nil, err
+	}
+	entryId, err := s.Repository.CreateLobbyEntry(&models.LobbyEntryDb{
+		UserId:        user_id,
+		DuelId:        int64(duel.Id),
+		HabitCategory: habit.Category,
+		Days:          days,
+		Rating:        rating,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LobbyPostResultDto{DuelId: int64(duel.Id), EntryId: entryId}, nil
+}
+
+// GetLobby returns open entries, the closest ratings to the user first.
+func (s *Service) GetLobby(user_id int64, category string, days int) ([]models.LobbyEntryDb, error) {
+	rating, err := s.Repository.FindUserSkillRating(user_id, category)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.Repository.FindLobbyEntries(user_id, category, days)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return ratingGap(entries[i].Rating, rating) < ratingGap(entries[j].Rating, rating)
+	})
+	return entries, nil
+}
+
+func (s *Service) JoinLobbyEntry(user_id int64, entry_id int64) error {
+	entry, err := s.Repository.FindLobbyEntryById(entry_id)
+	if err != nil {
+		return err
+	}
+	return s.joinLobbyEntry(user_id, entry)
+}
+
+func (s *Service) joinLobbyEntry(user_id int64, entry *models.LobbyEntryDb) error {
+	if entry.UserId == user_id {
+		return errors.New("you cannot join your own lobby entry")
+	}
+	duel, err := s.Repository.GetDuelById(entry.DuelId)
+	if err != nil {
+		return err
+	}
+	target, err := s.targetIfStartedToday(duel)
+	if err != nil {
+		return err
+	}
+	return s.Repository.JoinLobbyEntry(user_id, entry.Id, target)
+}
+
+func (s *Service) CancelLobbyEntry(user_id int64, entry_id int64) error {
+	return s.Repository.CancelLobbyEntry(user_id, entry_id)
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"maxbot/internal/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const timeOfDayLayout = "15:04"
+
+// validateProofRules checks the rules chosen for a new duel.
+func validateProofRules(rules *models.ProofRules) error {
+	if rules.MinMessageLength < 0 || rules.MinMessageLength > maxMessageLength {
+		return fmt.Errorf("min message length should be from 0 to %d", maxMessageLength)
+	}
+	for _, value := range []string{rules.NotBefore, rules.NotAfter} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(timeOfDayLayout, value); err != nil {
+			return errors.New("not_before and not_after should be in HH:MM format")
+		}
+	}
+	if rules.NotBefore != "" && rules.NotBefore == rules.NotAfter {
+		return errors.New("not_before and not_after should differ")
+	}
+	if rules.Timezone != "" {
+		if _, err := time.LoadLocation(rules.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone: %s", rules.Timezone)
+		}
+	}
+	return nil
+}
+
+// checkProofContent rejects a message or a photo that does not meet the rules of the duel.
+func checkProofContent(rules models.ProofRules, message string, photo []byte) error {
+	if rules.PhotoRequired && len(photo) == 0 {
+		return errors.New("this duel requires a photo as proof")
+	}
+	if length := utf8.RuneCountInString(strings.TrimSpace(message)); length < rules.MinMessageLength {
+		return fmt.Errorf("this duel requires a message of at least %d characters, yours has %d", rules.MinMessageLength, length)
+	}
+	return nil
+}
+
+// checkProofRules rejects a log that does not meet the rules of the duel.
+func (s *Service) checkProofRules(duel *models.DuelDb, message string, photo []byte) error {
+	rules := duel.ProofRules
+	if err := checkProofContent(rules, message, photo); err != nil {
+		return err
+	}
+	if rules.NotBefore == "" && rules.NotAfter == "" {
+		return nil
+	}
+
+	now := s.Clock.Now()
+	if rules.Timezone != "" {
+		location, err := time.LoadLocation(rules.Timezone)
+		if err != nil {
+			return err
+		}
+		now = now.In(location)
+	}
+	current := now.Format(timeOfDayLayout)
+	// Строки ЧЧ:ММ сравниваются как время; окно вида 22:00-02:00 переходит через полночь
+	var inWindow bool
+	switch {
+	case rules.NotBefore == "":
+		inWindow = current < rules.NotAfter
+	case rules.NotAfter == "":
+		inWindow = current >= rules.NotBefore
+	case rules.NotBefore < rules.NotAfter:
+		inWindow = current >= rules.NotBefore && current < rules.NotAfter
+	default:
+		inWindow = current >= rules.NotBefore || current < rules.NotAfter
+	}
+	if !inWindow {
+		return fmt.Errorf("this duel only accepts logs %s, it is %s now", describeWindow(rules), current)
+	}
+	return nil
+}
+
+func describeWindow(rules models.ProofRules) string {
+	window := ""
+	if rules.NotBefore != "" {
+		window = "from " + rules.NotBefore
+	}
+	if rules.NotAfter != "" {
+		window = strings.TrimSpace(window + " before " + rules.NotAfter)
+	}
+	if rules.Timezone != "" {
+		window += " (" + rules.Timezone + ")"
+	}
+	return window
+}
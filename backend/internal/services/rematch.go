@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"maxbot/internal/models"
+)
+
+// Rematch creates a duel with the habit and rules of an ended 1v1 duel and
+// sends it to the former opponent as a direct invitation.
+func (s *Service) Rematch(user_id int64, duel_id int64) (*models.DuelDb, error) {
+	duel, err := s.Repository.GetDuelById(duel_id)
+	if err != nil {
+		return nil, err
+	}
+	if !isParticipant(duel, user_id) {
+		return nil, errors.New("you are not a participant of this duel")
+	}
+	if duel.Status != "ended" {
+		return nil, errors.New("only ended duels can be rematched")
+	}
+	if duel.DuelType == models.DuelTypeTeam || len(duel.Participants) != 2 {
+		return nil, errors.New("only 1v1 duels can be rematched")
+	}
+	if hasRematch, err := s.Repository.HasRematch(duel_id); err != nil {
+		return nil, err
+	} else if hasRematch {
+		return nil, errors.New("a rematch of this duel has already been offered")
+	}
+	var opponentId int64
+	for _, participant := range duel.Participants {
+		if participant.UserId != user_id {
+			opponentId = participant.UserId
+		}
+	}
+
+	invitationHash, err := s.createDuel(user_id, duel.HabitId, models.DuelSettings{
+		Days:          duel.Duration,
+		Schedule:      duel.Schedule,
+		ScoringMode:   duel.ScoringMode,
+		Stake:         duel.Stake,
+		ProofRules:    duel.ProofRules,
+		RematchOf:     int64(duel.Id),
+		InvitedUserId: opponentId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rematch, err := s.Repository.FindDuelByInvitationHash(invitationHash)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyChallenge(rematch, invitationHash, "предлагает реванш в дуэли")
+	return rematch, nil
+}
+
+func (s *Service) GetDirectInvitations(user_id int64) ([]models.DuelDb, error) {
+	return s.Repository.FindDirectInvitations(user_id)
+}
+
+// AcceptDirectInvitation joins a duel the user was invited to directly, without a link.
+func (s *Service) AcceptDirectInvitation(user_id int64, duel_id int64) error {
+	duel, err := s.Repository.GetDuelById(duel_id)
+	if err != nil {
+		return err
+	}
+	if duel.Status != "invited" || !duel.InvitedUserId.Valid || duel.InvitedUserId.Int64 != user_id {
+		return errors.New("there is no pending invitation to this duel for you")
+	}
+	invitationHash, err := s.Repository.FindInvitationHashByDuelId(duel_id)
+	if err != nil {
+		return err
+	}
+	if err := s.AcceptInvitation(user_id, invitationHash); err != nil {
+		return err
+	}
+	s.notify(duel.User1_id, fmt.Sprintf("Вызов на дуэль «%s» принят, дуэль началась!", duel.HabitName))
+	return nil
+}
+
+func (s *Service) DeclineDirectInvitation(user_id int64, duel_id int64) error {
+	duel, err := s.Repository.GetDuelById(duel_id)
+	if err != nil {
+		return err
+	}
+	if err := s.Repository.DeclineDuel(duel_id, user_id); err != nil {
+		return err
+	}
+	s.notify(duel.User1_id, fmt.Sprintf("Вызов на дуэль «%s» отклонён.", duel.HabitName))
+	return nil
+}
+
+// CancelInvitation lets the creator withdraw a duel nobody has accepted; the
+// escrowed stakes go back to their owners.
+func (s *Service) CancelInvitation(user_id int64, duel_id int64) error {
+	duel, err := s.Repository.GetDuelById(duel_id)
+	if err != nil {
+		return err
+	}
+	if err := s.Repository.CancelInvitedDuel(duel_id, user_id); err != nil {
+		return err
+	}
+	for _, participant := range duel.Participants {
+		if participant.UserId != user_id {
+			s.notify(participant.UserId, fmt.Sprintf("Дуэль «%s» отменена создателем, ставка возвращена.", duel.HabitName))
+		}
+	}
+	if duel.InvitedUserId.Valid {
+		s.notify(duel.InvitedUserId.Int64, fmt.Sprintf("Вызов на дуэль «%s» отменён.", duel.HabitName))
+	}
+	return nil
+}
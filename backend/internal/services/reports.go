@@ -0,0 +1,89 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/models"
+	"unicode/utf8"
+)
+
+const (
+	maxReportDetailsLength = 500
+	reportsPageSize        = 100
+)
+
+func validateReport(reason string, details string) error {
+	switch reason {
+	case models.ReportReasonSpam, models.ReportReasonAbuse, models.ReportReasonInappropriate,
+		models.ReportReasonFakeProof, models.ReportReasonOther:
+	default:
+		return errors.New("reason should be one of: spam, abuse, inappropriate, fake_proof, other")
+	}
+	if utf8.RuneCountInString(details) > maxReportDetailsLength {
+		return errors.New("details should be at most 500 characters")
+	}
+	return nil
+}
+
+// ReportLog sends the log to the admin review queue.
+func (s *Service) ReportLog(reporter_id int64, log_id int64, reason string, details string) (int64, error) {
+	if err := validateReport(reason, details); err != nil {
+		return 0, err
+	}
+	ownerId, err := s.Repository.FindLogOwner(log_id)
+	if err != nil {
+		return 0, err
+	}
+	if ownerId == reporter_id {
+		return 0, errors.New("you cannot report your own log")
+	}
+	return s.Repository.CreateReport(&models.ReportDb{
+		ReporterId: reporter_id,
+		UserId:     ownerId,
+		LogId:      sql.NullInt64{Int64: log_id, Valid: true},
+		Reason:     reason,
+		Details:    details,
+	})
+}
+
+// ReportUser sends the user to the admin review queue.
+func (s *Service) ReportUser(reporter_id int64, user_id int64, reason string, details string) (int64, error) {
+	if err := validateReport(reason, details); err != nil {
+		return 0, err
+	}
+	if _, err := s.findOtherUser(reporter_id, user_id); err != nil {
+		return 0, err
+	}
+	return s.Repository.CreateReport(&models.ReportDb{
+		ReporterId: reporter_id,
+		UserId:     user_id,
+		Reason:     reason,
+		Details:    details,
+	})
+}
+
+// GetReports returns the review queue: open reports by default.
+func (s *Service) GetReports(status string) ([]models.ReportDb, error) {
+	if status == "" {
+		status = models.ReportStatusOpen
+	}
+	if status != models.ReportStatusOpen && status != models.ReportStatusResolved {
+		return nil, errors.New("status should be one of: open, resolved")
+	}
+	return s.Repository.FindReports(status, reportsPageSize)
+}
+
+func (s *Service) ResolveReport(admin_id int64, report_id int64, action string) error {
+	return s.Repository.ResolveReport(report_id, admin_id, action)
+}
+
+func (s *Service) SetUserSuspended(admin_id int64, user_id int64, suspended bool) error {
+	if admin_id == user_id {
+		return errors.New("you cannot suspend yourself")
+	}
+	return s.Repository.SetUserSuspended(user_id, suspended)
+}
+
+func (s *Service) SetLogHidden(log_id int64, hidden bool) error {
+	return s.Repository.SetLogHidden(log_id, hidden)
+}
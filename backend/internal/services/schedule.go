@@ -0,0 +1,63 @@
+package services
+
+import (
+	"errors"
+	"maxbot/internal/calendar"
+	"maxbot/internal/models"
+	"time"
+)
+
+func validateSchedule(schedule *models.Schedule) error {
+	switch schedule.Type {
+	case "", models.ScheduleDaily:
+		schedule.Type = models.ScheduleDaily
+		schedule.Weekdays = nil
+		schedule.TimesPerWeek = 0
+	case models.ScheduleWeekdays:
+		if len(schedule.Weekdays) == 0 {
+			return errors.New("weekdays schedule should contain at least one day")
+		}
+		seen := map[int]bool{}
+		for _, day := range schedule.Weekdays {
+			if day < 1 || day > 7 {
+				return errors.New("weekdays should be from 1 (monday) to 7 (sunday)")
+			}
+			if seen[day] {
+				return errors.New("weekdays should not repeat")
+			}
+			seen[day] = true
+		}
+		schedule.TimesPerWeek = 0
+	case models.ScheduleWeekly:
+		if schedule.TimesPerWeek < 1 || schedule.TimesPerWeek > 7 {
+			return errors.New("times_per_week value should be from 1 to 7")
+		}
+		schedule.Weekdays = nil
+	default:
+		return errors.New("schedule type should be one of: daily, weekdays, weekly")
+	}
+	return nil
+}
+
+// scheduledCheckIns counts how many check-ins the schedule expects during
+// the duel period of the given number of days starting at start.
+func scheduledCheckIns(schedule models.Schedule, start time.Time, days int) int {
+	total := 0
+	perWeek := map[time.Time]int{}
+	for i := 0; i < days; i++ {
+		day := start.AddDate(0, 0, i)
+		switch schedule.Type {
+		case models.ScheduleWeekly:
+			monday, _ := calendar.WeekBounds(day)
+			if perWeek[monday] < schedule.TimesPerWeek {
+				perWeek[monday]++
+				total++
+			}
+		default:
+			if calendar.IsScheduledDay(schedule, day) {
+				total++
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,374 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maxbot/internal/calendar"
+	"maxbot/internal/clock"
+	"maxbot/internal/models"
+	"sort"
+	"time"
+)
+
+// duelResult is the outcome decided by a scoring strategy. places maps every
+// competitor (a participant, or a team in team duels) to the final place,
+// equal results share a place.
+type duelResult struct {
+	ended       bool
+	places      map[int64]int
+	endDate     time.Time
+	forfeitedBy sql.NullInt64
+}
+
+// winner returns the only competitor on the first place, if there is one.
+func (r duelResult) winner() sql.NullInt64 {
+	var winnerID sql.NullInt64
+	for userID, place := range r.places {
+		if place != 1 {
+			continue
+		}
+		if winnerID.Valid {
+			return sql.NullInt64{}
+		}
+		winnerID = sql.NullInt64{Int64: userID, Valid: true}
+	}
+	return winnerID
+}
+
+// countedDaysFunc returns the days on which the player's check-in counted in the duel.
+type countedDaysFunc func(userID int64, duelID int64) ([]string, error)
+
+// scoringStrategy decides when a duel ends and how participants are ranked.
+type scoringStrategy interface {
+	// checkIn is called right after a player's day was counted, duel holds the updated counters.
+	checkIn(duel *models.DuelDb, userID int64, today time.Time) duelResult
+	// review is called periodically for every active duel.
+	review(countedDays countedDaysFunc, duel *models.DuelDb, today time.Time) (duelResult, error)
+}
+
+var scoringStrategies = map[string]scoringStrategy{
+	models.ScoringFirstToTarget:   firstToTargetScoring{},
+	models.ScoringHighestInPeriod: highestInPeriodScoring{},
+	models.ScoringVolume:          volumeScoring{},
+	models.ScoringLastOneStanding: lastOneStandingScoring{},
+}
+
+func scoringFor(duel *models.DuelDb) (scoringStrategy, error) {
+	strategy, ok := scoringStrategies[duel.ScoringMode]
+	if !ok {
+		return nil, errors.New("unknown scoring mode: " + duel.ScoringMode)
+	}
+	return strategy, nil
+}
+
+func periodEnd(duel *models.DuelDb) (time.Time, error) {
+	startDate, err := time.Parse(clock.DateLayout, duel.StartDate)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return startDate.AddDate(0, 0, duel.Duration-1), nil
+}
+
+// competitor is a side of the duel that gets a place: a participant, or a team
+// in team duels. goal is the score needed to reach the duel target.
+type competitor struct {
+	id        int64
+	completed float64
+	volume    float64
+	goal      float64
+}
+
+func competitorsOf(duel *models.DuelDb) []competitor {
+	var competitors []competitor
+	if duel.DuelType == models.DuelTypeTeam {
+		for _, team := range duel.Teams {
+			goal := float64(duel.Target)
+			if duel.TeamScoring == models.TeamScoringCombined {
+				goal *= float64(team.Members)
+			}
+			competitors = append(competitors, competitor{id: team.TeamId, completed: team.Score, volume: team.Volume, goal: goal})
+		}
+		return competitors
+	}
+	for _, participant := range duel.Participants {
+		competitors = append(competitors, competitor{
+			id:        participant.UserId,
+			completed: float64(participant.Completed),
+			volume:    participant.Volume,
+			goal:      float64(duel.Target),
+		})
+	}
+	return competitors
+}
+
+// competitorID is the side the player scores for.
+func competitorID(duel *models.DuelDb, userID int64) int64 {
+	if duel.DuelType != models.DuelTypeTeam {
+		return userID
+	}
+	for _, participant := range duel.Participants {
+		if participant.UserId == userID {
+			return participant.TeamId.Int64
+		}
+	}
+	return 0
+}
+
+// rankBy gives places by descending score, equal scores share a place (1, 1, 3...).
+func rankBy(competitors []competitor, score func(competitor) float64) map[int64]int {
+	sorted := make([]competitor, len(competitors))
+	copy(sorted, competitors)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return score(sorted[i]) > score(sorted[j])
+	})
+
+	places := map[int64]int{}
+	for i, c := range sorted {
+		if i > 0 && score(c) == score(sorted[i-1]) {
+			places[c.id] = places[sorted[i-1].id]
+		} else {
+			places[c.id] = i + 1
+		}
+	}
+	return places
+}
+
+func byCompleted(c competitor) float64 {
+	return c.completed
+}
+
+func byVolume(c competitor) float64 {
+	return c.volume
+}
+
+// highestInPeriodScoring: whoever has more counted days when the period ends wins.
+type highestInPeriodScoring struct{}
+
+func (highestInPeriodScoring) checkIn(*models.DuelDb, int64, time.Time) duelResult {
+	return duelResult{}
+}
+
+func (highestInPeriodScoring) review(_ countedDaysFunc, duel *models.DuelDb, today time.Time) (duelResult, error) {
+	end, err := periodEnd(duel)
+	if err != nil || !today.After(end) {
+		return duelResult{}, err
+	}
+	return duelResult{ended: true, places: rankBy(competitorsOf(duel), byCompleted), endDate: end}, nil
+}
+
+// firstToTargetScoring: the first player (or team) to reach the target wins
+// immediately, the others are ranked by their counters. If nobody reaches the
+// target, the period result decides.
+type firstToTargetScoring struct {
+	highestInPeriodScoring
+}
+
+func (firstToTargetScoring) checkIn(duel *models.DuelDb, userID int64, today time.Time) duelResult {
+	scorerID := competitorID(duel, userID)
+	var others []competitor
+	reached := false
+	for _, c := range competitorsOf(duel) {
+		if c.id != scorerID {
+			others = append(others, c)
+		} else {
+			reached = c.completed >= c.goal
+		}
+	}
+	if !reached {
+		return duelResult{}
+	}
+
+	places := rankBy(others, byCompleted)
+	for id := range places {
+		places[id]++
+	}
+	places[scorerID] = 1
+	return duelResult{ended: true, places: places, endDate: today}
+}
+
+// volumeScoring: whoever logged more total value for the habit when the period ends wins.
+type volumeScoring struct{}
+
+func (volumeScoring) checkIn(*models.DuelDb, int64, time.Time) duelResult {
+	return duelResult{}
+}
+
+func (volumeScoring) review(_ countedDaysFunc, duel *models.DuelDb, today time.Time) (duelResult, error) {
+	end, err := periodEnd(duel)
+	if err != nil || !today.After(end) {
+		return duelResult{}, err
+	}
+	return duelResult{ended: true, places: rankBy(competitorsOf(duel), byVolume), endDate: end}, nil
+}
+
+// lastOneStandingScoring: a player is out after the first missed scheduled
+// check-in. The duel ends when at most one player is left or the period is
+// over; players who stayed longer get better places.
+type lastOneStandingScoring struct{}
+
+func (lastOneStandingScoring) checkIn(*models.DuelDb, int64, time.Time) duelResult {
+	return duelResult{}
+}
+
+func (lastOneStandingScoring) review(countedDays countedDaysFunc, duel *models.DuelDb, today time.Time) (duelResult, error) {
+	startDate, err := time.Parse(clock.DateLayout, duel.StartDate)
+	if err != nil {
+		return duelResult{}, err
+	}
+	end, err := periodEnd(duel)
+	if err != nil {
+		return duelResult{}, err
+	}
+	// Today is not over yet, so only days up to yesterday can be missed
+	lastDay := today.AddDate(0, 0, -1)
+	if lastDay.After(end) {
+		lastDay = end
+	}
+
+	// Survivors are scored after the end of the period, eliminated players by their missed day
+	survivalScore := end.AddDate(0, 0, 1)
+	outAt := map[int64]time.Time{}
+	var misses []time.Time
+	for _, participant := range duel.Participants {
+		missedDay, missed, err := firstMissedDay(countedDays, duel, participant.UserId, startDate, lastDay, end)
+		if err != nil {
+			return duelResult{}, err
+		}
+		outAt[participant.UserId] = survivalScore
+		if missed {
+			outAt[participant.UserId] = missedDay
+			misses = append(misses, missedDay)
+		}
+	}
+	sort.Slice(misses, func(i, j int) bool { return misses[i].Before(misses[j]) })
+
+	endDate := end
+	switch {
+	case len(duel.Participants)-len(misses) <= 1 && len(misses) > 0:
+		// The duel was decided on the day the last but one player dropped out
+		endDate = misses[max(len(duel.Participants)-2, 0)]
+	case !today.After(end):
+		return duelResult{}, nil
+	}
+
+	places := rankBy(competitorsOf(duel), func(c competitor) float64 {
+		return float64(outAt[c.id].Unix())
+	})
+	return duelResult{ended: true, places: places, endDate: endDate}, nil
+}
+
+// firstMissedDay finds the first scheduled check-in the player missed between
+// start and lastDay. For weekly schedules a week is missed when it is over and
+// has fewer check-ins than required.
+func firstMissedDay(countedDays countedDaysFunc, duel *models.DuelDb, userID int64, start time.Time, lastDay time.Time, end time.Time) (time.Time, bool, error) {
+	days, err := countedDays(userID, int64(duel.Id))
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	counted := map[string]bool{}
+	for _, day := range days {
+		counted[day] = true
+	}
+
+	if duel.Schedule.Type != models.ScheduleWeekly {
+		for day := start; !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+			if calendar.IsScheduledDay(duel.Schedule, day) && !counted[day.Format(clock.DateLayout)] {
+				return day, true, nil
+			}
+		}
+		return time.Time{}, false, nil
+	}
+
+	for monday, _ := calendar.WeekBounds(start); !monday.After(lastDay); monday = monday.AddDate(0, 0, 7) {
+		weekEnd := monday.AddDate(0, 0, 6)
+		if weekEnd.After(end) {
+			weekEnd = end
+		}
+		if weekEnd.After(lastDay) {
+			break
+		}
+		required, have := 0, 0
+		for day := monday; !day.After(weekEnd); day = day.AddDate(0, 0, 1) {
+			if day.Before(start) {
+				continue
+			}
+			required++
+			if counted[day.Format(clock.DateLayout)] {
+				have++
+			}
+		}
+		if required > duel.Schedule.TimesPerWeek {
+			required = duel.Schedule.TimesPerWeek
+		}
+		if have < required {
+			return weekEnd, true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+// finishDuel ends the duel and evaluates the achievements of its participants.
+func (s *Service) finishDuel(duel *models.DuelDb, result duelResult) error {
+	if err := s.storeDuelResult(duel, result); err != nil {
+		return err
+	}
+	for _, participant := range duel.Participants {
+		if err := s.evaluateAchievements(participant.UserId, achievementEventDuelEnded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeDuelResult stores the result. The repository credits the winner, every
+// member of the winning team in team duels, and completes the tournament match
+// in the same transaction; the next tournament duels are started afterwards.
+func (s *Service) storeDuelResult(duel *models.DuelDb, result duelResult) error {
+	winnerID := result.winner()
+	if duel.DuelType == models.DuelTypeTeam {
+		return s.Repository.EndTeamDuel(duel.Id, winnerID, result.endDate.Format(clock.DateLayout), result.places)
+	}
+
+	err := s.Repository.EndDuel(duel.Id, winnerID, result.endDate.Format(clock.DateLayout), result.places, result.forfeitedBy)
+	if err != nil {
+		return err
+	}
+	return s.advanceTournament(duel)
+}
+
+// ReviewActiveDuels lets the scoring strategy of every active duel end it when
+// its period is over or, for survival duels, when the players dropped out.
+func (s *Service) ReviewActiveDuels() error {
+	duels, err := s.Repository.FindActiveDuels()
+	if err != nil {
+		return err
+	}
+	today, err := time.Parse(clock.DateLayout, s.Clock.Today())
+	if err != nil {
+		return err
+	}
+
+	// A duel that fails to be reviewed must not hold back the others
+	var errs []error
+	for i := range duels {
+		if err := s.reviewDuel(&duels[i], today); err != nil {
+			slog.Error("error while reviewing duel", "duel_id", duels[i].Id, "error", err.Error())
+			errs = append(errs, fmt.Errorf("duel %d: %w", duels[i].Id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) reviewDuel(duel *models.DuelDb, today time.Time) error {
+	strategy, err := scoringFor(duel)
+	if err != nil {
+		return err
+	}
+	result, err := strategy.review(s.Repository.FindCountedDays, duel, today)
+	if err != nil || !result.ended {
+		return err
+	}
+	return s.finishDuel(duel, result)
+}
@@ -0,0 +1,580 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/clock"
+	"maxbot/internal/models"
+	"maxbot/internal/repository"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// The test duels start on Monday 2025-03-03 and last a week: the period ends on Sunday 2025-03-09.
+const testStartDate = "2025-03-03"
+
+func date(t *testing.T, value string) time.Time {
+	t.Helper()
+	day, err := time.Parse(clock.DateLayout, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return day
+}
+
+func player(userID int64, completed int64, volume float64) models.ParticipantDb {
+	return models.ParticipantDb{UserId: userID, Completed: completed, Volume: volume}
+}
+
+func testDuel(mode string, participants ...models.ParticipantDb) *models.DuelDb {
+	return &models.DuelDb{
+		Id:           1,
+		Duration:     7,
+		Target:       5,
+		Schedule:     models.Schedule{Type: models.ScheduleDaily},
+		ScoringMode:  mode,
+		DuelType:     models.DuelTypeIndividual,
+		StartDate:    testStartDate,
+		Participants: participants,
+	}
+}
+
+// countedDaysFrom serves the counted days of every player from memory.
+func countedDaysFrom(days map[int64][]string) countedDaysFunc {
+	return func(userID int64, duelID int64) ([]string, error) {
+		return days[userID], nil
+	}
+}
+
+// everyDay lists the days from the first to the last one inclusive.
+func everyDay(t *testing.T, first string, last string) []string {
+	t.Helper()
+	var days []string
+	for day := date(t, first); !day.After(date(t, last)); day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format(clock.DateLayout))
+	}
+	return days
+}
+
+// without removes the missed days from the list.
+func without(days []string, missed ...string) []string {
+	var kept []string
+	for _, day := range days {
+		skip := false
+		for _, m := range missed {
+			skip = skip || day == m
+		}
+		if !skip {
+			kept = append(kept, day)
+		}
+	}
+	return kept
+}
+
+func noWinner() sql.NullInt64 {
+	return sql.NullInt64{}
+}
+
+func winnerIs(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: true}
+}
+
+func checkResult(t *testing.T, got duelResult, ended bool, places map[int64]int, winner sql.NullInt64, endDate string) {
+	t.Helper()
+	if got.ended != ended {
+		t.Fatalf("ended = %v, want %v", got.ended, ended)
+	}
+	if !ended {
+		return
+	}
+	if !reflect.DeepEqual(got.places, places) {
+		t.Errorf("places = %v, want %v", got.places, places)
+	}
+	if got.winner() != winner {
+		t.Errorf("winner = %v, want %v", got.winner(), winner)
+	}
+	if end := got.endDate.Format(clock.DateLayout); end != endDate {
+		t.Errorf("end date = %s, want %s", end, endDate)
+	}
+}
+
+func TestRankBy(t *testing.T) {
+	tests := []struct {
+		name        string
+		competitors []competitor
+		want        map[int64]int
+	}{
+		{
+			name:        "distinct scores",
+			competitors: []competitor{{id: 1, completed: 2}, {id: 2, completed: 5}, {id: 3, completed: 3}},
+			want:        map[int64]int{2: 1, 3: 2, 1: 3},
+		},
+		{
+			name:        "tie for the first place",
+			competitors: []competitor{{id: 1, completed: 4}, {id: 2, completed: 4}, {id: 3, completed: 1}},
+			want:        map[int64]int{1: 1, 2: 1, 3: 3},
+		},
+		{
+			name:        "tie below the leader",
+			competitors: []competitor{{id: 1, completed: 1}, {id: 2, completed: 6}, {id: 3, completed: 1}},
+			want:        map[int64]int{2: 1, 1: 2, 3: 2},
+		},
+		{
+			name:        "everyone equal",
+			competitors: []competitor{{id: 1}, {id: 2}},
+			want:        map[int64]int{1: 1, 2: 1},
+		},
+		{
+			name: "no competitors",
+			want: map[int64]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rankBy(tt.competitors, byCompleted); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rankBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstToTargetScoring(t *testing.T) {
+	tests := []struct {
+		name    string
+		duel    *models.DuelDb
+		checkIn int64 // 0 - the periodic review instead of a check-in
+		today   string
+		ended   bool
+		places  map[int64]int
+		winner  sql.NullInt64
+		endDate string
+	}{
+		{
+			name:    "reaching the target wins at once",
+			duel:    testDuel(models.ScoringFirstToTarget, player(1, 5, 0), player(2, 4, 0), player(3, 4, 0)),
+			checkIn: 1,
+			today:   "2025-03-07",
+			ended:   true,
+			places:  map[int64]int{1: 1, 2: 2, 3: 2},
+			winner:  winnerIs(1),
+			endDate: "2025-03-07",
+		},
+		{
+			name:    "a check-in below the target goes on",
+			duel:    testDuel(models.ScoringFirstToTarget, player(1, 5, 0), player(2, 4, 0)),
+			checkIn: 2,
+			today:   "2025-03-07",
+		},
+		{
+			name:  "the review waits for the end of the period",
+			duel:  testDuel(models.ScoringFirstToTarget, player(1, 4, 0), player(2, 4, 0)),
+			today: "2025-03-09",
+		},
+		{
+			name:    "nobody reached the target, equal counters draw",
+			duel:    testDuel(models.ScoringFirstToTarget, player(1, 4, 0), player(2, 4, 0)),
+			today:   "2025-03-10",
+			ended:   true,
+			places:  map[int64]int{1: 1, 2: 1},
+			winner:  noWinner(),
+			endDate: "2025-03-09",
+		},
+		{
+			name:    "nobody reached the target, more check-ins win",
+			duel:    testDuel(models.ScoringFirstToTarget, player(1, 3, 0), player(2, 4, 0)),
+			today:   "2025-03-10",
+			ended:   true,
+			places:  map[int64]int{2: 1, 1: 2},
+			winner:  winnerIs(2),
+			endDate: "2025-03-09",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := firstToTargetScoring{}
+			var result duelResult
+			if tt.checkIn != 0 {
+				result = strategy.checkIn(tt.duel, tt.checkIn, date(t, tt.today))
+			} else {
+				var err error
+				if result, err = strategy.review(countedDaysFrom(nil), tt.duel, date(t, tt.today)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			checkResult(t, result, tt.ended, tt.places, tt.winner, tt.endDate)
+		})
+	}
+}
+
+func TestFirstToTargetScoringTeams(t *testing.T) {
+	// Combined scoring: a team of two needs twice the target
+	duel := testDuel(models.ScoringFirstToTarget,
+		models.ParticipantDb{UserId: 1, TeamId: winnerIs(10)},
+		models.ParticipantDb{UserId: 2, TeamId: winnerIs(10)},
+		models.ParticipantDb{UserId: 3, TeamId: winnerIs(20)},
+	)
+	duel.DuelType = models.DuelTypeTeam
+	duel.TeamScoring = models.TeamScoringCombined
+	duel.Teams = []models.DuelTeamDb{{TeamId: 10, Members: 2, Score: 9}, {TeamId: 20, Members: 1, Score: 5}}
+
+	today := date(t, "2025-03-08")
+	checkResult(t, firstToTargetScoring{}.checkIn(duel, 1, today), false, nil, noWinner(), "")
+	checkResult(t, firstToTargetScoring{}.checkIn(duel, 3, today), true,
+		map[int64]int{20: 1, 10: 2}, winnerIs(20), "2025-03-08")
+}
+
+func TestHighestInPeriodScoring(t *testing.T) {
+	tests := []struct {
+		name    string
+		duel    *models.DuelDb
+		today   string
+		ended   bool
+		places  map[int64]int
+		winner  sql.NullInt64
+		endDate string
+	}{
+		{
+			name:  "the period is not over",
+			duel:  testDuel(models.ScoringHighestInPeriod, player(1, 7, 0), player(2, 2, 0)),
+			today: "2025-03-09",
+		},
+		{
+			name:    "more counted days win",
+			duel:    testDuel(models.ScoringHighestInPeriod, player(1, 7, 0), player(2, 6, 0)),
+			today:   "2025-03-10",
+			ended:   true,
+			places:  map[int64]int{1: 1, 2: 2},
+			winner:  winnerIs(1),
+			endDate: "2025-03-09",
+		},
+		{
+			name:    "equal counted days draw",
+			duel:    testDuel(models.ScoringHighestInPeriod, player(1, 6, 0), player(2, 6, 0)),
+			today:   "2025-03-12",
+			ended:   true,
+			places:  map[int64]int{1: 1, 2: 1},
+			winner:  noWinner(),
+			endDate: "2025-03-09",
+		},
+		{
+			name:    "a missed scheduled day decides",
+			duel:    testDuel(models.ScoringHighestInPeriod, player(1, 7, 0), player(2, 7, 0), player(3, 6, 0)),
+			today:   "2025-03-10",
+			ended:   true,
+			places:  map[int64]int{1: 1, 2: 1, 3: 3},
+			winner:  noWinner(),
+			endDate: "2025-03-09",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := highestInPeriodScoring{}
+			if result := strategy.checkIn(tt.duel, 1, date(t, tt.today)); result.ended {
+				t.Fatal("a check-in should never end the duel")
+			}
+			result, err := strategy.review(countedDaysFrom(nil), tt.duel, date(t, tt.today))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkResult(t, result, tt.ended, tt.places, tt.winner, tt.endDate)
+		})
+	}
+}
+
+func TestVolumeScoring(t *testing.T) {
+	tests := []struct {
+		name    string
+		duel    *models.DuelDb
+		today   string
+		ended   bool
+		places  map[int64]int
+		winner  sql.NullInt64
+		endDate string
+	}{
+		{
+			name:  "the period is not over",
+			duel:  testDuel(models.ScoringVolume, player(1, 7, 120), player(2, 7, 30)),
+			today: "2025-03-08",
+		},
+		{
+			name:    "more total value wins",
+			duel:    testDuel(models.ScoringVolume, player(1, 7, 42.5), player(2, 7, 40)),
+			today:   "2025-03-10",
+			ended:   true,
+			places:  map[int64]int{1: 1, 2: 2},
+			winner:  winnerIs(1),
+			endDate: "2025-03-09",
+		},
+		{
+			name:    "equal value draws",
+			duel:    testDuel(models.ScoringVolume, player(1, 5, 40), player(2, 7, 40)),
+			today:   "2025-03-10",
+			ended:   true,
+			places:  map[int64]int{1: 1, 2: 1},
+			winner:  noWinner(),
+			endDate: "2025-03-09",
+		},
+		{
+			name:    "a missed scheduled day does not matter, the value does",
+			duel:    testDuel(models.ScoringVolume, player(1, 6, 80), player(2, 7, 70)),
+			today:   "2025-03-10",
+			ended:   true,
+			places:  map[int64]int{1: 1, 2: 2},
+			winner:  winnerIs(1),
+			endDate: "2025-03-09",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := volumeScoring{}
+			if result := strategy.checkIn(tt.duel, 1, date(t, tt.today)); result.ended {
+				t.Fatal("a check-in should never end the duel")
+			}
+			result, err := strategy.review(countedDaysFrom(nil), tt.duel, date(t, tt.today))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkResult(t, result, tt.ended, tt.places, tt.winner, tt.endDate)
+		})
+	}
+}
+
+func TestLastOneStandingScoring(t *testing.T) {
+	week := everyDay(t, "2025-03-03", "2025-03-09")
+	tests := []struct {
+		name    string
+		counted map[int64][]string
+		today   string
+		ended   bool
+		places  map[int64]int
+		winner  sql.NullInt64
+		endDate string
+	}{
+		{
+			name:    "nobody missed a day yet",
+			counted: map[int64][]string{1: everyDay(t, "2025-03-03", "2025-03-05"), 2: everyDay(t, "2025-03-03", "2025-03-05")},
+			today:   "2025-03-06",
+		},
+		{
+			name:    "today is not over, so it is not missed",
+			counted: map[int64][]string{1: everyDay(t, "2025-03-03", "2025-03-05"), 2: everyDay(t, "2025-03-03", "2025-03-04")},
+			today:   "2025-03-05",
+		},
+		{
+			name:    "a missed scheduled day ends the duel on that day",
+			counted: map[int64][]string{1: everyDay(t, "2025-03-03", "2025-03-06"), 2: without(everyDay(t, "2025-03-03", "2025-03-06"), "2025-03-05")},
+			today:   "2025-03-07",
+			ended:   true,
+			places:  map[int64]int{1: 1, 2: 2},
+			winner:  winnerIs(1),
+			endDate: "2025-03-05",
+		},
+		{
+			name:    "the player who stayed longer wins",
+			counted: map[int64][]string{1: without(week, "2025-03-07"), 2: without(week, "2025-03-04")},
+			today:   "2025-03-08",
+			ended:   true,
+			places:  map[int64]int{1: 1, 2: 2},
+			winner:  winnerIs(1),
+			endDate: "2025-03-04",
+		},
+		{
+			name:    "missing the same day draws",
+			counted: map[int64][]string{1: without(week, "2025-03-04"), 2: without(week, "2025-03-04")},
+			today:   "2025-03-05",
+			ended:   true,
+			places:  map[int64]int{1: 1, 2: 1},
+			winner:  noWinner(),
+			endDate: "2025-03-04",
+		},
+		{
+			name:    "everyone survived the period",
+			counted: map[int64][]string{1: week, 2: week},
+			today:   "2025-03-10",
+			ended:   true,
+			places:  map[int64]int{1: 1, 2: 1},
+			winner:  noWinner(),
+			endDate: "2025-03-09",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duel := testDuel(models.ScoringLastOneStanding, player(1, 0, 0), player(2, 0, 0))
+			strategy := lastOneStandingScoring{}
+			if result := strategy.checkIn(duel, 1, date(t, tt.today)); result.ended {
+				t.Fatal("a check-in should never end the duel")
+			}
+			result, err := strategy.review(countedDaysFrom(tt.counted), duel, date(t, tt.today))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkResult(t, result, tt.ended, tt.places, tt.winner, tt.endDate)
+		})
+	}
+}
+
+func TestLastOneStandingScoringThreePlayers(t *testing.T) {
+	// The duel is decided when the last but one player drops out
+	week := everyDay(t, "2025-03-03", "2025-03-09")
+	duel := testDuel(models.ScoringLastOneStanding, player(1, 0, 0), player(2, 0, 0), player(3, 0, 0))
+	counted := countedDaysFrom(map[int64][]string{
+		1: week,
+		2: without(week, "2025-03-06"),
+		3: without(week, "2025-03-04"),
+	})
+
+	result, err := lastOneStandingScoring{}.review(counted, duel, date(t, "2025-03-05"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResult(t, result, false, nil, noWinner(), "")
+
+	result, err = lastOneStandingScoring{}.review(counted, duel, date(t, "2025-03-08"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResult(t, result, true, map[int64]int{1: 1, 2: 2, 3: 3}, winnerIs(1), "2025-03-06")
+}
+
+func TestLastOneStandingScoringError(t *testing.T) {
+	failing := func(userID int64, duelID int64) ([]string, error) {
+		return nil, errors.New("database is down")
+	}
+	duel := testDuel(models.ScoringLastOneStanding, player(1, 0, 0), player(2, 0, 0))
+	if _, err := (lastOneStandingScoring{}).review(failing, duel, date(t, "2025-03-05")); err == nil {
+		t.Fatal("expected the error of countedDays")
+	}
+}
+
+func TestFirstMissedDay(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule models.Schedule
+		counted  []string
+		lastDay  string
+		missed   bool
+		want     string
+	}{
+		{
+			name:     "daily, nothing missed",
+			schedule: models.Schedule{Type: models.ScheduleDaily},
+			counted:  everyDay(t, "2025-03-03", "2025-03-06"),
+			lastDay:  "2025-03-06",
+		},
+		{
+			name:     "daily, the first gap is missed",
+			schedule: models.Schedule{Type: models.ScheduleDaily},
+			counted:  without(everyDay(t, "2025-03-03", "2025-03-08"), "2025-03-05", "2025-03-07"),
+			lastDay:  "2025-03-08",
+			missed:   true,
+			want:     "2025-03-05",
+		},
+		{
+			name:     "daily, days after lastDay do not count",
+			schedule: models.Schedule{Type: models.ScheduleDaily},
+			counted:  everyDay(t, "2025-03-03", "2025-03-04"),
+			lastDay:  "2025-03-04",
+		},
+		{
+			name:     "weekdays, a day off is not missed",
+			schedule: models.Schedule{Type: models.ScheduleWeekdays, Weekdays: []int{1, 3, 5}},
+			counted:  []string{"2025-03-03", "2025-03-05", "2025-03-07"},
+			lastDay:  "2025-03-09",
+		},
+		{
+			name:     "weekdays, a scheduled day is missed",
+			schedule: models.Schedule{Type: models.ScheduleWeekdays, Weekdays: []int{1, 3, 5}},
+			counted:  []string{"2025-03-03", "2025-03-04", "2025-03-07"},
+			lastDay:  "2025-03-09",
+			missed:   true,
+			want:     "2025-03-05",
+		},
+		{
+			name:     "weekly, the week has enough check-ins",
+			schedule: models.Schedule{Type: models.ScheduleWeekly, TimesPerWeek: 3},
+			counted:  []string{"2025-03-04", "2025-03-06", "2025-03-09"},
+			lastDay:  "2025-03-09",
+		},
+		{
+			name:     "weekly, a short week is missed on its last day",
+			schedule: models.Schedule{Type: models.ScheduleWeekly, TimesPerWeek: 3},
+			counted:  []string{"2025-03-04", "2025-03-06"},
+			lastDay:  "2025-03-09",
+			missed:   true,
+			want:     "2025-03-09",
+		},
+		{
+			name:     "weekly, a week that is not over is not missed",
+			schedule: models.Schedule{Type: models.ScheduleWeekly, TimesPerWeek: 3},
+			counted:  []string{"2025-03-04"},
+			lastDay:  "2025-03-08",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duel := testDuel(models.ScoringLastOneStanding, player(1, 0, 0))
+			duel.Schedule = tt.schedule
+			start, end := date(t, testStartDate), date(t, "2025-03-09")
+
+			day, missed, err := firstMissedDay(countedDaysFrom(map[int64][]string{1: tt.counted}), duel, 1, start, date(t, tt.lastDay), end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if missed != tt.missed {
+				t.Fatalf("missed = %v, want %v", missed, tt.missed)
+			}
+			if missed && day.Format(clock.DateLayout) != tt.want {
+				t.Errorf("missed day = %s, want %s", day.Format(clock.DateLayout), tt.want)
+			}
+		})
+	}
+}
+
+// reviewRepository serves the active duels to ReviewActiveDuels and records the ended ones.
+type reviewRepository struct {
+	repository.RepositoryInterface
+	duels []models.DuelDb
+	ended []int
+}
+
+func (m *reviewRepository) FindActiveDuels() ([]models.DuelDb, error) {
+	return m.duels, nil
+}
+
+func (m *reviewRepository) FindCountedDays(userID int64, duelID int64) ([]string, error) {
+	return nil, nil
+}
+
+func (m *reviewRepository) EndDuel(duelID int, winnerID sql.NullInt64, endDate string, places map[int64]int, forfeitedBy sql.NullInt64) error {
+	m.ended = append(m.ended, duelID)
+	return nil
+}
+
+func (m *reviewRepository) FindTournamentIdByDuelId(duel_id int) (int64, error) {
+	return 0, nil
+}
+
+func (m *reviewRepository) FindAchievementStats(user_id int64, comebackDeficit int) (*models.AchievementStatsDb, error) {
+	return &models.AchievementStatsDb{}, nil
+}
+
+func (m *reviewRepository) AwardAchievements(user_id int64, codes []string) error {
+	return nil
+}
+
+func TestReviewActiveDuelsContinuesAfterError(t *testing.T) {
+	broken := testDuel("unknown", player(1, 4, 0), player(2, 3, 0))
+	over := testDuel(models.ScoringFirstToTarget, player(3, 4, 0), player(4, 3, 0))
+	over.Id = 2
+	repo := &reviewRepository{duels: []models.DuelDb{*broken, *over}}
+	s := &Service{Repository: repo, Clock: clock.NewFake(date(t, "2025-03-10"))}
+
+	err := s.ReviewActiveDuels()
+	if err == nil {
+		t.Error("the error of the broken duel should be returned")
+	}
+	if !reflect.DeepEqual(repo.ended, []int{2}) {
+		t.Errorf("ended duels = %v, want [2]", repo.ended)
+	}
+}
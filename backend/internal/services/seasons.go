@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"maxbot/internal/clock"
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"strings"
+	"time"
+)
+
+const (
+	defaultStandingsLimit = 50
+	maxStandingsLimit     = 500
+)
+
+func (s *Service) CreateSeason(name string, startDate string, endDate string) (*models.SeasonDb, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 64 {
+		return nil, errors.New("season name should be from 1 to 64 characters")
+	}
+	start, err := time.Parse(clock.DateLayout, startDate)
+	if err != nil {
+		return nil, errors.New("start_date should be in YYYY-MM-DD format")
+	}
+	end, err := time.Parse(clock.DateLayout, endDate)
+	if err != nil {
+		return nil, errors.New("end_date should be in YYYY-MM-DD format")
+	}
+	if end.Before(start) {
+		return nil, errors.New("season should end after it starts")
+	}
+	seasonId, err := s.Repository.CreateSeason(name, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return s.Repository.FindSeasonById(seasonId)
+}
+
+func (s *Service) GetSeasons() ([]models.SeasonDb, error) {
+	return s.Repository.FindSeasons()
+}
+
+// GetSeasonStandings returns the archived standings of a closed season or the
+// live ones of a running season. season_id 0 means the current season.
+func (s *Service) GetSeasonStandings(season_id int64, limit int) (*dto.SeasonStandingsDto, error) {
+	if limit <= 0 {
+		limit = defaultStandingsLimit
+	}
+	limit = min(limit, maxStandingsLimit)
+	var season *models.SeasonDb
+	var err error
+	if season_id == 0 {
+		season, err = s.Repository.FindCurrentSeason()
+		if err == nil && season == nil {
+			err = errors.New("no season is running now")
+		}
+	} else {
+		season, err = s.Repository.FindSeasonById(season_id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var standings []models.SeasonStandingDb
+	if season.Status == models.SeasonClosed {
+		standings, err = s.Repository.FindArchivedSeasonStandings(season.Id, limit)
+	} else {
+		standings, err = s.Repository.FindLiveSeasonStandings(season, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &dto.SeasonStandingsDto{Season: *season, Standings: standings}, nil
+}
+
+// GetUserSeasonStanding returns the current season and the user's numbers in
+// it. Both are nil when no season is running; the standing is nil when the user
+// has not played in the season yet.
+func (s *Service) GetUserSeasonStanding(user_id int64) (*models.SeasonDb, *models.SeasonStandingDb, error) {
+	season, err := s.Repository.FindCurrentSeason()
+	if err != nil || season == nil {
+		return nil, nil, err
+	}
+	standing, err := s.Repository.FindUserSeasonStanding(season, user_id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return season, standing, nil
+}
+
+// seasonReward is the reward for the final place in a season.
+func seasonReward(rank int) string {
+	switch {
+	case rank == 1:
+		return "champion"
+	case rank == 2:
+		return "silver"
+	case rank == 3:
+		return "bronze"
+	case rank <= 10:
+		return "top10"
+	}
+	return ""
+}
+
+// CloseEndedSeasons archives the final standings of seasons whose last day is over.
+func (s *Service) CloseEndedSeasons() error {
+	seasons, err := s.Repository.FindSeasonsToClose()
+	if err != nil {
+		return err
+	}
+	for i := range seasons {
+		standings, err := s.Repository.FindLiveSeasonStandings(&seasons[i], 0)
+		if err != nil {
+			return err
+		}
+		for j := range standings {
+			standings[j].Reward = seasonReward(standings[j].Rank)
+		}
+		if err := s.Repository.CloseSeason(seasons[i].Id, standings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
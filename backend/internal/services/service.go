@@ -3,44 +3,177 @@ package services
 import (
 	"crypto/rand"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"maxbot/internal/calendar"
+	"maxbot/internal/clock"
 	"maxbot/internal/dto"
 	"maxbot/internal/models"
+	"maxbot/internal/notifier"
 	"maxbot/internal/repository"
 	"time"
 	"unicode/utf8"
 )
 
 type ServiceInterface interface {
-	GetDuelLogs(user_id int64) ([]dto.LogDto, error)
-	CreateDuelLog(user *models.UserDb, ownerID int64, duelID int64, message string, photo []byte) error
-	CreateHabit(user_id int64, habit_name string, habit_category string) error
+	GetDuelLogs(viewer_id int64, duel_id int64) ([]dto.LogDto, error)
+	CreateDuelLog(user *models.UserDb, ownerID int64, duelID int64, message string, photo []byte, value *float64) error
+	EditDuelLog(user_id int64, log_id int64, message string, photo []byte, removePhoto bool) error
+	DeleteDuelLog(user_id int64, log_id int64) error
+	CreateHabit(user_id int64, habit_name string, habit_category string, unit string, dailyTarget float64) error
 	GetUserHabits(user_id int64) ([]dto.HabitDto, error)
-	CreateDuelAndGetHash(user_id int64, habit_id int, days int) (string, error)
+	CreateDuelAndGetHash(user_id int64, habit_id int, settings models.DuelSettings) (string, error)
 	AcceptInvitation(user_id int64, invitationHash string) error
+	AcceptTeamInvitation(user_id int64, team_id int64, invitationHash string) error
+	StartDuel(user_id int64, duel_id int64) error
+	ForfeitDuel(user_id int64, duel_id int64) error
+	ReviewActiveDuels() error
+	ResumeTournaments() error
+	CreateTeam(user_id int64, name string) (*dto.TeamDto, error)
+	JoinTeam(user_id int64, invitationHash string) error
+	LeaveTeam(user_id int64, team_id int64) error
+	GetUserTeams(user_id int64) ([]dto.TeamDto, error)
+	CreateTournament(user_id int64, req dto.CreateTournamentDto) (*dto.TournamentDto, error)
+	JoinTournament(user_id int64, invitationHash string) error
+	StartTournament(user_id int64, tournament_id int64) error
+	GetTournament(tournament_id int64) (*dto.TournamentDto, error)
+	PostToLobby(user_id int64, habit_id int, days int) (*dto.LobbyPostResultDto, error)
+	GetLobby(user_id int64, category string, days int) ([]models.LobbyEntryDb, error)
+	JoinLobbyEntry(user_id int64, entry_id int64) error
+	CancelLobbyEntry(user_id int64, entry_id int64) error
+	GetLeaderboard(user_id int64, metric string, window string, scope string, cursor string, limit int) (*dto.LeaderboardDto, error)
+	RefreshLeaderboards() error
+	CreateSeason(name string, startDate string, endDate string) (*models.SeasonDb, error)
+	GetSeasons() ([]models.SeasonDb, error)
+	GetSeasonStandings(season_id int64, limit int) (*dto.SeasonStandingsDto, error)
+	GetUserSeasonStanding(user_id int64) (*models.SeasonDb, *models.SeasonStandingDb, error)
+	CloseEndedSeasons() error
+	GetUserAchievements(user_id int64) ([]dto.AchievementDto, error)
+	BackfillAchievements() error
+	GetCoinBalance(user_id int64) (int64, error)
+	GetCoinHistory(user_id int64, limit int) ([]models.CoinEntryDb, error)
+	AuditCoins() (*dto.CoinAuditDto, error)
+	Rematch(user_id int64, duel_id int64) (*models.DuelDb, error)
+	GetDirectInvitations(user_id int64) ([]models.DuelDb, error)
+	AcceptDirectInvitation(user_id int64, duel_id int64) error
+	DeclineDirectInvitation(user_id int64, duel_id int64) error
+	CancelInvitation(user_id int64, duel_id int64) error
+	GetHeadToHead(user_id int64, opponent_id int64) (*dto.HeadToHeadDto, error)
+	ChallengeUser(user_id int64, targetId int64, targetMaxId string, habit_id int, settings models.DuelSettings) (*models.DuelDb, error)
+	SendFriendRequest(user *models.UserDb, other_id int64) (string, error)
+	AcceptFriendRequest(user *models.UserDb, requester_id int64) error
+	RemoveFriend(user_id int64, other_id int64) error
+	GetFriends(user_id int64) ([]models.FriendDb, error)
+	GetFriendRequests(user_id int64) ([]models.FriendRequestDb, error)
+	GetFriendSuggestions(user_id int64) ([]models.FriendSuggestionDb, error)
+	BlockUser(user_id int64, other_id int64) error
+	UnblockUser(user_id int64, other_id int64) error
+	GetBlockedUsers(user_id int64) ([]models.BlockedUserDb, error)
+	GetVisibility(user_id int64) (string, error)
+	SetVisibility(user_id int64, visibility string) error
+	CanView(viewer_id int64, owner_id int64) (bool, error)
+	GetUserProfile(viewer_id int64, user_id int64) (*dto.ProfileDto, error)
+	ReportLog(reporter_id int64, log_id int64, reason string, details string) (int64, error)
+	ReportUser(reporter_id int64, user_id int64, reason string, details string) (int64, error)
+	GetReports(status string) ([]models.ReportDb, error)
+	ResolveReport(admin_id int64, report_id int64, action string) error
+	SetUserSuspended(admin_id int64, user_id int64, suspended bool) error
+	SetLogHidden(log_id int64, hidden bool) error
+	DisputeLog(user *models.UserDb, log_id int64, reason string, exclude bool) (int64, error)
+	ConcedeDispute(user_id int64, dispute_id int64) error
+	WithdrawDispute(user_id int64, dispute_id int64) error
+	AdminResolveDispute(admin_id int64, dispute_id int64, upheld bool) error
+	GetOpenDisputes() ([]models.DisputeDb, error)
+	ReactToLog(user_id int64, log_id int64, emoji string) error
+	CommentOnLog(user *models.UserDb, log_id int64, parent_id int64, message string) (int64, error)
+	GetLogComments(viewer_id int64, log_id int64) ([]models.LogCommentDb, error)
+	RequestBackfill(user *models.UserDb, duel_id int64, day string, message string, photo []byte, value *float64) (int64, error)
+	ResolveBackfill(user_id int64, backfill_id int64, approved bool) error
+	GetBackfills(user_id int64, duel_id int64) ([]models.BackfillDb, error)
 	CreateTestData() error
 }
 
 type Service struct {
-	Repository *repository.Repository
+	Repository repository.RepositoryInterface
+	Clock      clock.Clock
+	Notifier   notifier.Notifier
+	// Сколько времени после отправки отметку можно изменить или удалить, 0 - 15 минут
+	LogEditWindow time.Duration
 }
 
 var _ ServiceInterface = &Service{}
 
-func (s *Service) GetDuelLogs(duel_id int64) ([]dto.LogDto, error) {
+// GetDuelLogs returns the logs of the duel the viewer may see, each with its
+// dispute history, reactions and number of comments. Participants see every log
+// of their duel except those of users blocked with them, others only the logs
+// of users visible to them. viewer_id 0 is an anonymous viewer.
+func (s *Service) GetDuelLogs(viewer_id int64, duel_id int64) ([]dto.LogDto, error) {
 	logs, err := s.Repository.FindDuelLogsByDuelId(duel_id)
 	if err != nil {
 		return nil, err
 	}
+	duel, err := s.Repository.GetDuelById(duel_id)
+	if err != nil {
+		return nil, err
+	}
+	disputes, err := s.Repository.FindDisputesByDuelId(duel_id)
+	if err != nil {
+		return nil, err
+	}
+	logDisputes := map[int64][]models.DisputeDb{}
+	for _, dispute := range disputes {
+		logDisputes[dispute.LogId] = append(logDisputes[dispute.LogId], dispute)
+	}
+	reactions, err := s.Repository.FindDuelReactions(duel_id, viewer_id)
+	if err != nil {
+		return nil, err
+	}
+	logReactions := map[int64][]models.LogReactionDb{}
+	for _, reaction := range reactions {
+		logReactions[reaction.LogId] = append(logReactions[reaction.LogId], reaction)
+	}
+	commentCounts, err := s.Repository.CountDuelComments(duel_id)
+	if err != nil {
+		return nil, err
+	}
 
-	return logs, nil
+	visible := map[int64]bool{viewer_id: true}
+	var visibleLogs []dto.LogDto = []dto.LogDto{}
+	for _, log := range logs {
+		canView, ok := visible[log.OwnerID]
+		if !ok {
+			if canView, err = s.canViewDuelLogsOf(viewer_id, duel, log.OwnerID); err != nil {
+				return nil, err
+			}
+			visible[log.OwnerID] = canView
+		}
+		if !canView {
+			continue
+		}
+		log.Disputes = logDisputes[log.LogID]
+		if log.Disputes == nil {
+			log.Disputes = []models.DisputeDb{}
+		}
+		log.Reactions = []dto.ReactionCountDto{}
+		for _, reaction := range logReactions[log.LogID] {
+			log.Reactions = append(log.Reactions, dto.ReactionCountDto{Emoji: reaction.Emoji, Count: reaction.Count})
+			if reaction.Mine {
+				log.MyReaction = reaction.Emoji
+			}
+		}
+		log.CommentsCount = commentCounts[log.LogID]
+		visibleLogs = append(visibleLogs, log)
+	}
+	return visibleLogs, nil
 }
 
-func (s *Service) CreateDuelLog(user *models.UserDb, ownerID int64, duelID int64, message string, photo []byte) error {
+const maxMessageLength = 500
 
-	if len([]rune(message)) > 500 {
+func (s *Service) CreateDuelLog(user *models.UserDb, ownerID int64, duelID int64, message string, photo []byte, value *float64) error {
+
+	if len([]rune(message)) > maxMessageLength {
 		return errors.New("message too long (max 500 characters)")
 	}
 
@@ -54,6 +187,8 @@ func (s *Service) CreateDuelLog(user *models.UserDb, ownerID int64, duelID int64
 		DuelID:  duelID,
 		Message: message,
 		Photo:   photoPtr,
+		Value:   value,
+		Counted: true,
 	}
 
 	duel, err := s.Repository.GetDuelById(duelID)
@@ -65,71 +200,150 @@ func (s *Service) CreateDuelLog(user *models.UserDb, ownerID int64, duelID int64
 	}
 
 	// Проверка, что user – участник дуэли
-	if duel.User1_id != ownerID && (!duel.User2_id.Valid || duel.User2_id.Int64 != ownerID) {
+	if !isParticipant(duel, ownerID) {
 		return errors.New("user is not a participant of this duel")
 	}
 
-	today := time.Now().Format("2006-01-02")
+	if err := s.checkProofRules(duel, message, photo); err != nil {
+		return err
+	}
+
+	today := s.Clock.Today()
+
+	if err := s.checkSchedule(duel, ownerID, today); err != nil {
+		return err
+	}
 
 	alreadyLogged, err := s.Repository.HasUserContributedToDuelToday(ownerID, duelID, today)
 	if err != nil {
 		return err
 	}
 
-	if alreadyLogged {
+	if duel.HabitDailyTarget > 0 {
+		// Числовая привычка: день засчитывается, когда сумма за день достигла цели.
+		// После этого можно продолжать вносить объём, но день второй раз не считается.
+		if value == nil || *value <= 0 {
+			return fmt.Errorf("value in %s should be greater than 0", duel.HabitUnit)
+		}
+		todayTotal, err := s.Repository.SumUserDuelValueOnDate(ownerID, duelID, today)
+		if err != nil {
+			return err
+		}
+		log.Counted = !alreadyLogged && todayTotal+*value >= duel.HabitDailyTarget
+	} else if alreadyLogged {
 		return errors.New("you have already contributed to this duel today")
 	}
 
+	if log.Counted && user.LastTimeContributed.String != today {
+		// Отметка обновит стрик - запоминаем прежний, чтобы её можно было удалить
+		log.StreakBefore = &models.StreakSnapshot{
+			Streak:              user.Streak,
+			StreakFreezes:       user.StreakFreezes,
+			LastTimeContributed: user.LastTimeContributed,
+		}
+	}
+
 	if err := s.Repository.CreateDuelLog(log); err != nil {
 		return err
 	}
 
+	if !log.Counted {
+		return nil
+	}
+
 	// Проверяем прогресс по дуэли
-	won, err := s.Repository.IncrementDuelCounter(duel, ownerID)
+	if _, err := s.Repository.IncrementDuelCounter(duel, ownerID); err != nil {
+		return err
+	}
+	// Перечитываем дуэль: счёт команды зависит от отметок всех её участников
+	duel, err = s.Repository.GetDuelById(duelID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.updateUserStreak(user, today); err != nil {
+		return err
+	}
+	if err := s.Repository.RewardCoins(ownerID, models.CoinsPerCheckIn, models.CoinReasonCheckIn, duelID); err != nil {
+		return err
+	}
+	if err := s.evaluateAchievements(ownerID, achievementEventCheckIn); err != nil {
+		return err
+	}
+
+	strategy, err := scoringFor(duel)
+	if err != nil {
+		return err
+	}
+	todayDate, err := time.Parse(clock.DateLayout, today)
 	if err != nil {
 		return err
 	}
+	if result := strategy.checkIn(duel, ownerID, todayDate); result.ended {
+		if err := s.finishDuel(duel, result); err != nil {
+			return err
+		}
+	}
 
+	return nil
+}
+
+// Streak freezes are earned every streakFreezeEvery days of a streak and are
+// spent automatically to cover missed days instead of resetting the streak.
+const (
+	streakFreezeEvery = 7
+	maxStreakFreezes  = 3
+)
+
+func (s *Service) updateUserStreak(user *models.UserDb, today string) error {
 	// Обновляем СТРИК ТОЛЬКО если это первая "учтённая" запись за день
-	switch {
-	case user.LastTimeContributed.String == today:
+	if user.LastTimeContributed.String == today {
 		// Уже был лог сегодня - стрик уже обновлён, ничего не делаем.
+		return nil
+	}
 
-	case user.LastTimeContributed.String == "":
+	if user.LastTimeContributed.String == "" {
 		// Первая запись
-		if err := s.Repository.ResetUserStreakToOneAndUpdateLastTimeContributed(user); err != nil {
-			return err
-		}
+		return s.Repository.ResetUserStreakToOneAndUpdateLastTimeContributed(user)
+	}
 
-	default:
-		lastDate, err := time.Parse("2006-01-02", user.LastTimeContributed.String)
-		if err != nil {
-			return err
-		}
+	lastDate, err := time.Parse(clock.DateLayout, user.LastTimeContributed.String)
+	if err != nil {
+		return err
+	}
+	todayDate, err := time.Parse(clock.DateLayout, today)
+	if err != nil {
+		return err
+	}
 
-		// Вчера был лог => продолжаем стрик
-		if lastDate.Add(24*time.Hour).Format("2006-01-02") == today {
-			if err := s.Repository.IncrementUserStreakAndUpdateLastTimeContributed(user); err != nil {
-				return err
-			}
-		} else {
-			// Стрик закончился => сбрасываем и начинаем новый
-			if err := s.Repository.ResetUserStreakToOneAndUpdateLastTimeContributed(user); err != nil {
-				return err
-			}
-		}
+	var missedDays []string
+	for day := lastDate.AddDate(0, 0, 1); day.Before(todayDate); day = day.AddDate(0, 0, 1) {
+		missedDays = append(missedDays, day.Format(clock.DateLayout))
 	}
 
-	if won {
-		if err := s.Repository.IncrementWinCounter(user); err != nil {
+	switch {
+	case len(missedDays) == 0:
+		// Вчера был лог => продолжаем стрик
+		if err := s.Repository.IncrementUserStreakAndUpdateLastTimeContributed(user); err != nil {
+			return err
+		}
+	case len(missedDays) <= user.StreakFreezes:
+		// Пропуски покрываются заморозками => стрик продолжается
+		if err := s.Repository.UseStreakFreezesAndIncrementUserStreak(user, missedDays); err != nil {
 			return err
 		}
+	default:
+		// Стрик закончился => сбрасываем и начинаем новый
+		return s.Repository.ResetUserStreakToOneAndUpdateLastTimeContributed(user)
 	}
 
+	if (user.Streak+1)%streakFreezeEvery == 0 && user.StreakFreezes-len(missedDays) < maxStreakFreezes {
+		return s.Repository.AwardStreakFreeze(user)
+	}
 	return nil
 }
 
-func (s *Service) CreateHabit(user_id int64, habit_name string, habit_category string) error {
+func (s *Service) CreateHabit(user_id int64, habit_name string, habit_category string, unit string, dailyTarget float64) error {
 	nameLength := utf8.RuneCountInString(habit_name)
 	categoryLength := utf8.RuneCountInString(habit_category)
 	if nameLength < 2 || nameLength > 30 {
@@ -138,7 +352,16 @@ func (s *Service) CreateHabit(user_id int64, habit_name string, habit_category s
 	if categoryLength < 2 || categoryLength > 30 {
 		return errors.New("habit category should be from 2 to 30 symbols")
 	}
-	err := s.Repository.CreateHabit(user_id, habit_name, habit_category)
+	if dailyTarget < 0 {
+		return errors.New("daily target should not be negative")
+	}
+	if dailyTarget > 0 && (utf8.RuneCountInString(unit) < 1 || utf8.RuneCountInString(unit) > 16) {
+		return errors.New("habit with a daily target should have a unit from 1 to 16 symbols")
+	}
+	if dailyTarget == 0 {
+		unit = ""
+	}
+	err := s.Repository.CreateHabit(user_id, habit_name, habit_category, unit, dailyTarget)
 	if err != nil {
 		return err
 	}
@@ -149,10 +372,48 @@ func (s *Service) GetUserHabits(user_id int64) ([]dto.HabitDto, error) {
 	return s.Repository.FindHabitsByUserId(user_id)
 }
 
-func (s *Service) CreateDuelAndGetHash(user_id int64, habit_id int, days int) (string, error) {
-	if days < 1 || days > 30 {
-		return "", errors.New("days value should be from 1 to 30")
+// validateDuelSettings checks the rules of a new duel and fills in the defaults.
+func (s *Service) validateDuelSettings(habit_id int, settings *models.DuelSettings) error {
+	if settings.Days < 1 || settings.Days > 30 {
+		return errors.New("days value should be from 1 to 30")
 	}
+	if err := validateSchedule(&settings.Schedule); err != nil {
+		return err
+	}
+	if err := validateProofRules(&settings.ProofRules); err != nil {
+		return err
+	}
+	habit, err := s.Repository.FindHabitById(habit_id)
+	if err != nil {
+		return err
+	}
+	if settings.ScoringMode == "" {
+		settings.ScoringMode = models.ScoringFirstToTarget
+	}
+	if _, ok := scoringStrategies[settings.ScoringMode]; !ok {
+		return errors.New("scoring mode should be one of: first_to_target, highest_in_period, volume, last_one_standing")
+	}
+	if settings.ScoringMode == models.ScoringVolume && habit.DailyTarget == 0 {
+		return errors.New("volume scoring is only available for habits with a daily target")
+	}
+	if settings.MaxParticipants == 0 {
+		settings.MaxParticipants = 2
+	}
+	if settings.MaxParticipants < 2 || settings.MaxParticipants > maxDuelParticipants {
+		return fmt.Errorf("max participants value should be from 2 to %d", maxDuelParticipants)
+	}
+	if settings.Stake < 0 || settings.Stake > maxDuelStake {
+		return fmt.Errorf("stake should be from 0 to %d coins", maxDuelStake)
+	}
+	if settings.Stake > 0 && (settings.MaxParticipants != 2 || settings.TeamId != 0) {
+		return errors.New("stakes are only available for 1v1 duels")
+	}
+	return nil
+}
+
+const maxDuelParticipants = 50
+
+func newInvitationHash() (string, error) {
 	randomBytes := make([]byte, 32)
 	_, err := rand.Read(randomBytes)
 	if err != nil {
@@ -161,19 +422,174 @@ func (s *Service) CreateDuelAndGetHash(user_id int64, habit_id int, days int) (s
 	hasher := sha256.New()
 	hasher.Write(randomBytes)
 	hashBytes := hasher.Sum(nil)
-	randomHash := hex.EncodeToString(hashBytes)
+	return hex.EncodeToString(hashBytes), nil
+}
+
+func (s *Service) CreateDuelAndGetHash(user_id int64, habit_id int, settings models.DuelSettings) (string, error) {
+	randomHash, err := s.createDuel(user_id, habit_id, settings)
+	if err != nil {
+		return "", err
+	}
+
+	return duelInvitationLink(randomHash), nil
+}
+
+func duelInvitationLink(invitationHash string) string {
+	return fmt.Sprintf("https://max.ru/t272_hakaton_bot?startapp=%s", invitationHash)
+}
 
-	err = s.Repository.CreateDuel(user_id, habit_id, randomHash, days)
+// createDuel validates the settings, creates an invited duel and returns its invitation hash.
+func (s *Service) createDuel(user_id int64, habit_id int, settings models.DuelSettings) (string, error) {
+	if err := s.validateDuelSettings(habit_id, &settings); err != nil {
+		return "", err
+	}
+	if settings.TeamId != 0 {
+		if err := s.validateTeamDuelSettings(user_id, &settings); err != nil {
+			return "", err
+		}
+	}
+	if settings.InvitedUserId != 0 {
+		if blocked, err := s.Repository.IsBlocked(user_id, settings.InvitedUserId); err != nil {
+			return "", err
+		} else if blocked {
+			return "", errors.New("you cannot invite a user you have blocked or who has blocked you")
+		}
+	}
+	randomHash, err := newInvitationHash()
 	if err != nil {
 		return "", err
 	}
+	if err := s.Repository.CreateDuel(user_id, habit_id, randomHash, settings); err != nil {
+		return "", err
+	}
+	return randomHash, nil
+}
 
-	invitationLink := fmt.Sprintf("https://max.ru/t272_hakaton_bot?startapp=%s", randomHash)
-	return invitationLink, nil
+// targetIfStartedToday is the number of scheduled check-ins needed to win a duel starting today.
+func (s *Service) targetIfStartedToday(duel *models.DuelDb) (int, error) {
+	today, err := time.Parse(clock.DateLayout, s.Clock.Today())
+	if err != nil {
+		return 0, err
+	}
+	target := scheduledCheckIns(duel.Schedule, today, duel.Duration)
+	if target == 0 {
+		return 0, errors.New("duel schedule has no check-in days if started today")
+	}
+	return target, nil
 }
 
+// AcceptInvitation joins the duel; the duel starts once all places are taken.
 func (s *Service) AcceptInvitation(user_id int64, invitationHash string) error {
-	return s.Repository.ActivateDuelFromInvitationHash(user_id, invitationHash)
+	duel, err := s.Repository.FindDuelByInvitationHash(invitationHash)
+	if err != nil {
+		return err
+	}
+	target, err := s.targetIfStartedToday(duel)
+	if err != nil {
+		return err
+	}
+	_, err = s.Repository.ActivateDuelFromInvitationHash(user_id, invitationHash, target)
+	return err
+}
+
+// StartDuel lets the creator start a group duel before all places are taken.
+func (s *Service) StartDuel(user_id int64, duel_id int64) error {
+	duel, err := s.Repository.GetDuelById(duel_id)
+	if err != nil {
+		return err
+	}
+	if duel.User1_id != user_id {
+		return errors.New("only the creator can start the duel")
+	}
+	if duel.Status != "invited" {
+		return errors.New("duel is not waiting for participants")
+	}
+	if duel.DuelType == models.DuelTypeTeam {
+		return errors.New("team duel starts when the opposing team accepts the invitation")
+	}
+	if len(duel.Participants) < 2 {
+		return errors.New("at least 2 participants are needed to start the duel")
+	}
+	target, err := s.targetIfStartedToday(duel)
+	if err != nil {
+		return err
+	}
+	return s.Repository.StartDuel(duel_id, target)
+}
+
+// ForfeitDuel lets a player give up an active 1v1 duel: the opponent wins today.
+func (s *Service) ForfeitDuel(user_id int64, duel_id int64) error {
+	duel, err := s.Repository.GetDuelById(duel_id)
+	if err != nil {
+		return err
+	}
+	if !isParticipant(duel, user_id) {
+		return errors.New("you are not a participant of this duel")
+	}
+	if duel.Status != "active" {
+		return errors.New("duel is not active")
+	}
+	if duel.DuelType == models.DuelTypeTeam || len(duel.Participants) != 2 {
+		return errors.New("only 1v1 duels can be forfeited")
+	}
+	today, err := time.Parse(clock.DateLayout, s.Clock.Today())
+	if err != nil {
+		return err
+	}
+
+	places := map[int64]int{}
+	for _, participant := range duel.Participants {
+		places[participant.UserId] = 1
+	}
+	places[user_id] = 2
+	return s.finishDuel(duel, duelResult{
+		ended:       true,
+		places:      places,
+		endDate:     today,
+		forfeitedBy: sql.NullInt64{Int64: user_id, Valid: true},
+	})
+}
+
+func isParticipant(duel *models.DuelDb, user_id int64) bool {
+	for _, participant := range duel.Participants {
+		if participant.UserId == user_id {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSchedule validates that a contribution made today fits the duel period and schedule.
+func (s *Service) checkSchedule(duel *models.DuelDb, ownerID int64, today string) error {
+	startDate, err := time.Parse(clock.DateLayout, duel.StartDate)
+	if err != nil {
+		return err
+	}
+	todayDate, err := time.Parse(clock.DateLayout, today)
+	if err != nil {
+		return err
+	}
+
+	if todayDate.After(startDate.AddDate(0, 0, duel.Duration-1)) {
+		return errors.New("duel period is over")
+	}
+	if !calendar.IsScheduledDay(duel.Schedule, todayDate) {
+		return errors.New("today is not a scheduled day for this duel")
+	}
+
+	if duel.Schedule.Type == models.ScheduleWeekly {
+		monday, sunday := calendar.WeekBounds(todayDate)
+		count, err := s.Repository.CountUserDuelLogsBetween(
+			ownerID, duel.Id, monday.Format(clock.DateLayout), sunday.Format(clock.DateLayout),
+		)
+		if err != nil {
+			return err
+		}
+		if count >= duel.Schedule.TimesPerWeek {
+			return fmt.Errorf("you have already checked in %d times this week", count)
+		}
+	}
+	return nil
 }
 
 // --For dev testing-- //
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"strings"
+)
+
+func teamInvitationLink(invitationHash string) string {
+	return fmt.Sprintf("https://max.ru/t272_hakaton_bot?startapp=team_%s", invitationHash)
+}
+
+func (s *Service) CreateTeam(user_id int64, name string) (*dto.TeamDto, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 64 {
+		return nil, errors.New("team name should be from 1 to 64 characters")
+	}
+	invitationHash, err := newInvitationHash()
+	if err != nil {
+		return nil, err
+	}
+	teamId, err := s.Repository.CreateTeam(user_id, name, invitationHash)
+	if err != nil {
+		return nil, err
+	}
+	team, err := s.Repository.FindTeamById(teamId)
+	if err != nil {
+		return nil, err
+	}
+	return s.teamToDto(team)
+}
+
+func (s *Service) JoinTeam(user_id int64, invitationHash string) error {
+	return s.Repository.JoinTeamByInvitationHash(user_id, strings.TrimPrefix(invitationHash, "team_"))
+}
+
+// LeaveTeam removes the user from the team. Duels the team already plays are
+// not affected: the user's check-ins there still count for the team.
+func (s *Service) LeaveTeam(user_id int64, team_id int64) error {
+	return s.Repository.LeaveTeam(user_id, team_id)
+}
+
+func (s *Service) GetUserTeams(user_id int64) ([]dto.TeamDto, error) {
+	teams, err := s.Repository.FindTeamsByUserId(user_id)
+	if err != nil {
+		return nil, err
+	}
+	var result []dto.TeamDto = []dto.TeamDto{}
+	for i := range teams {
+		team, err := s.teamToDto(&teams[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *team)
+	}
+	return result, nil
+}
+
+func (s *Service) teamToDto(team *models.TeamDb) (*dto.TeamDto, error) {
+	members, err := s.Repository.FindTeamMembers(team.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.TeamDto{
+		Id:             team.Id,
+		Name:           team.Name,
+		OwnerId:        team.OwnerId,
+		InvitationLink: teamInvitationLink(team.InvitationHash),
+		Members:        members,
+	}, nil
+}
+
+// validateTeamDuelSettings checks the rules of a team duel and fills in the defaults.
+func (s *Service) validateTeamDuelSettings(user_id int64, settings *models.DuelSettings) error {
+	member, err := s.Repository.IsTeamMember(user_id, settings.TeamId)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return errors.New("you are not a member of this team")
+	}
+	if settings.ScoringMode == models.ScoringLastOneStanding {
+		return errors.New("last_one_standing scoring is not available for team duels")
+	}
+	switch settings.TeamScoring {
+	case "":
+		settings.TeamScoring = models.TeamScoringCombined
+	case models.TeamScoringCombined, models.TeamScoringAverage:
+	default:
+		return errors.New("team scoring should be one of: combined, average")
+	}
+	return nil
+}
+
+// AcceptTeamInvitation enters the user's team into a team duel as the opponent;
+// the duel starts right away.
+func (s *Service) AcceptTeamInvitation(user_id int64, team_id int64, invitationHash string) error {
+	member, err := s.Repository.IsTeamMember(user_id, team_id)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return errors.New("you are not a member of this team")
+	}
+	duel, err := s.Repository.FindDuelByInvitationHash(invitationHash)
+	if err != nil {
+		return err
+	}
+	target, err := s.targetIfStartedToday(duel)
+	if err != nil {
+		return err
+	}
+	return s.Repository.ActivateTeamDuelFromInvitationHash(team_id, invitationHash, target)
+}
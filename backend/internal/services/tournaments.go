@@ -0,0 +1,375 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"sort"
+	"strings"
+)
+
+const maxTournamentPlayers = 64
+
+func tournamentInvitationLink(invitationHash string) string {
+	return fmt.Sprintf("https://max.ru/t272_hakaton_bot?startapp=tournament_%s", invitationHash)
+}
+
+func (s *Service) CreateTournament(user_id int64, req dto.CreateTournamentDto) (*dto.TournamentDto, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > 64 {
+		return nil, errors.New("tournament name should be from 1 to 64 characters")
+	}
+	if req.Format != models.TournamentSingleElimination && req.Format != models.TournamentRoundRobin {
+		return nil, errors.New("tournament format should be one of: single_elimination, round_robin")
+	}
+	// Дуэли турнира создаются с этими же настройками, проверяем их заранее
+	settings := models.DuelSettings{Days: req.Days, ScoringMode: req.ScoringMode}
+	if err := s.validateDuelSettings(req.HabitId, &settings); err != nil {
+		return nil, err
+	}
+	invitationHash, err := newInvitationHash()
+	if err != nil {
+		return nil, err
+	}
+
+	tournamentId, err := s.Repository.CreateTournament(&models.TournamentDb{
+		Name:           name,
+		OrganizerId:    user_id,
+		HabitId:        req.HabitId,
+		Days:           settings.Days,
+		ScoringMode:    settings.ScoringMode,
+		Format:         req.Format,
+		InvitationHash: invitationHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTournament(tournamentId)
+}
+
+func (s *Service) JoinTournament(user_id int64, invitationHash string) error {
+	return s.Repository.JoinTournamentByInvitationHash(
+		user_id, strings.TrimPrefix(invitationHash, "tournament_"), maxTournamentPlayers,
+	)
+}
+
+// StartTournament closes the registration, builds the bracket and starts the first duels.
+func (s *Service) StartTournament(user_id int64, tournament_id int64) error {
+	tournament, err := s.Repository.FindTournamentById(tournament_id)
+	if err != nil {
+		return err
+	}
+	if tournament.OrganizerId != user_id {
+		return errors.New("only the organizer can start the tournament")
+	}
+	if tournament.Status != models.TournamentRegistration {
+		return errors.New("tournament has already started")
+	}
+	players, err := s.Repository.FindTournamentPlayers(tournament_id)
+	if err != nil {
+		return err
+	}
+	if len(players) < 2 {
+		return errors.New("at least 2 players are needed to start the tournament")
+	}
+
+	var matches []models.TournamentMatchDb
+	if tournament.Format == models.TournamentSingleElimination {
+		matches = singleEliminationBracket(players)
+	} else {
+		matches = roundRobinSchedule(players)
+	}
+	if err := s.Repository.StartTournament(tournament_id, matches); err != nil {
+		return err
+	}
+	tournament.Status = models.TournamentActive
+	return s.playReadyMatches(tournament)
+}
+
+func playerSlot(players []models.TournamentPlayerDb, i int) sql.NullInt64 {
+	if i >= len(players) {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: players[i].UserId, Valid: true}
+}
+
+// bracketSeeds returns the seeds (0 is the top seed) in the order of the
+// round 1 slots of a bracket of the given size: 1v8, 4v5, 2v7, 3v6 for 8 slots.
+// Winners of neighbouring matches meet next, so the top two seeds can only
+// meet in the final.
+func bracketSeeds(size int) []int {
+	seeds := []int{0}
+	for count := 1; count < size; count *= 2 {
+		next := make([]int, 0, count*2)
+		for _, seed := range seeds {
+			next = append(next, seed, count*2-1-seed)
+		}
+		seeds = next
+	}
+	return seeds
+}
+
+// singleEliminationBracket pads the bracket to a power of two and pairs the
+// seeds with standard seeding, so the top seeds get the byes. Matches of later
+// rounds are created empty and filled as players advance.
+func singleEliminationBracket(players []models.TournamentPlayerDb) []models.TournamentMatchDb {
+	size := 2
+	for size < len(players) {
+		size *= 2
+	}
+
+	seeds := bracketSeeds(size)
+	var matches []models.TournamentMatchDb
+	for position := 0; position < size/2; position++ {
+		matches = append(matches, models.TournamentMatchDb{
+			Round:     1,
+			Position:  position,
+			Player1Id: playerSlot(players, seeds[2*position]),
+			Player2Id: playerSlot(players, seeds[2*position+1]),
+		})
+	}
+	for round, count := 2, size/4; count >= 1; round, count = round+1, count/2 {
+		for position := 0; position < count; position++ {
+			matches = append(matches, models.TournamentMatchDb{Round: round, Position: position})
+		}
+	}
+	return matches
+}
+
+// roundRobinSchedule splits all pairs into rounds with the circle method, so
+// every player has at most one duel per round.
+func roundRobinSchedule(players []models.TournamentPlayerDb) []models.TournamentMatchDb {
+	var ids []int64
+	for _, player := range players {
+		ids = append(ids, player.UserId)
+	}
+	if len(ids)%2 == 1 {
+		ids = append(ids, 0) // 0 - пропуск раунда
+	}
+
+	var matches []models.TournamentMatchDb
+	n := len(ids)
+	for round := 1; round < n; round++ {
+		for i := 0; i < n/2; i++ {
+			a, b := ids[i], ids[n-1-i]
+			if a == 0 || b == 0 {
+				continue
+			}
+			matches = append(matches, models.TournamentMatchDb{
+				Round:     round,
+				Position:  i,
+				Player1Id: sql.NullInt64{Int64: a, Valid: true},
+				Player2Id: sql.NullInt64{Int64: b, Valid: true},
+			})
+		}
+		// Первый игрок на месте, остальные сдвигаются по кругу
+		ids = append([]int64{ids[0], ids[n-1]}, ids[1:n-1]...)
+	}
+	return matches
+}
+
+// playReadyMatches resolves byes and starts the duels of every match whose
+// players are known. Round robin rounds are played one after another. Ends the
+// tournament when all matches are finished.
+func (s *Service) playReadyMatches(tournament *models.TournamentDb) error {
+	for {
+		matches, err := s.Repository.FindTournamentMatches(tournament.Id)
+		if err != nil {
+			return err
+		}
+
+		currentRound := 0
+		for _, match := range matches {
+			if !match.Finished {
+				currentRound = match.Round
+				break
+			}
+		}
+		if currentRound == 0 {
+			return s.finishTournament(tournament, matches)
+		}
+
+		progressed := false
+		for _, match := range matches {
+			if match.Finished || match.DuelId.Valid {
+				continue
+			}
+			if match.Round == 1 && !match.Player2Id.Valid {
+				// Пропуск раунда - игрок проходит дальше без дуэли
+				if err := s.Repository.CompleteTournamentMatch(match.Id, match.Player1Id); err != nil {
+					return err
+				}
+				progressed = true
+				continue
+			}
+			if !match.Player1Id.Valid || !match.Player2Id.Valid {
+				continue
+			}
+			if tournament.Format == models.TournamentRoundRobin && match.Round != currentRound {
+				continue
+			}
+			if err := s.startMatchDuel(tournament, match); err != nil {
+				return err
+			}
+		}
+		if !progressed {
+			return nil
+		}
+	}
+}
+
+// startMatchDuel creates the duel of a match and starts it right away, in one
+// step, so a match is never left with a duel that is still waiting for a player.
+func (s *Service) startMatchDuel(tournament *models.TournamentDb, match models.TournamentMatchDb) error {
+	settings := models.DuelSettings{Days: tournament.Days, ScoringMode: tournament.ScoringMode}
+	if err := s.validateDuelSettings(tournament.HabitId, &settings); err != nil {
+		return err
+	}
+	target, err := s.targetIfStartedToday(&models.DuelDb{Schedule: settings.Schedule, Duration: settings.Days})
+	if err != nil {
+		return err
+	}
+	return s.Repository.StartTournamentMatchDuel(
+		match.Id, match.Player1Id.Int64, match.Player2Id.Int64, tournament.HabitId, settings, target,
+	)
+}
+
+// advanceTournament is called when a duel ends; the repository has already
+// completed its match, so the next duels of the tournament are started.
+func (s *Service) advanceTournament(duel *models.DuelDb) error {
+	tournamentId, err := s.Repository.FindTournamentIdByDuelId(duel.Id)
+	if err != nil || tournamentId == 0 {
+		return err
+	}
+	tournament, err := s.Repository.FindTournamentById(tournamentId)
+	if err != nil {
+		return err
+	}
+	return s.playReadyMatches(tournament)
+}
+
+// ResumeTournaments starts the duels of matches that are ready but have none,
+// for example when starting them failed right after the previous duel ended.
+func (s *Service) ResumeTournaments() error {
+	ids, err := s.Repository.FindActiveTournamentIds()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		tournament, err := s.Repository.FindTournamentById(id)
+		if err != nil {
+			return err
+		}
+		if err := s.playReadyMatches(tournament); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// roundRobinPoints gives 1 point for a win and 0.5 for a draw.
+func roundRobinPoints(matches []models.TournamentMatchDb) map[int64]float64 {
+	points := map[int64]float64{}
+	for _, match := range matches {
+		if !match.Finished {
+			continue
+		}
+		if match.WinnerId.Valid {
+			points[match.WinnerId.Int64]++
+			continue
+		}
+		points[match.Player1Id.Int64] += 0.5
+		points[match.Player2Id.Int64] += 0.5
+	}
+	return points
+}
+
+func (s *Service) finishTournament(tournament *models.TournamentDb, matches []models.TournamentMatchDb) error {
+	if tournament.Status == models.TournamentEnded {
+		return nil
+	}
+
+	var winnerID sql.NullInt64
+	if tournament.Format == models.TournamentSingleElimination {
+		winnerID = matches[len(matches)-1].WinnerId
+	} else {
+		players, err := s.Repository.FindTournamentPlayers(tournament.Id)
+		if err != nil {
+			return err
+		}
+		points := roundRobinPoints(matches)
+		// Стабильная сортировка: при равенстве очков выше лучший посев
+		sort.SliceStable(players, func(i, j int) bool {
+			return points[players[i].UserId] > points[players[j].UserId]
+		})
+		winnerID = sql.NullInt64{Int64: players[0].UserId, Valid: true}
+	}
+
+	if err := s.Repository.EndTournament(tournament.Id, winnerID); err != nil {
+		return err
+	}
+	tournament.Status = models.TournamentEnded
+	return nil
+}
+
+// GetTournament returns the tournament with its players and the bracket.
+func (s *Service) GetTournament(tournament_id int64) (*dto.TournamentDto, error) {
+	tournament, err := s.Repository.FindTournamentById(tournament_id)
+	if err != nil {
+		return nil, err
+	}
+	players, err := s.Repository.FindTournamentPlayers(tournament_id)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.Repository.FindTournamentMatches(tournament_id)
+	if err != nil {
+		return nil, err
+	}
+
+	eliminated := map[int64]bool{}
+	if tournament.Format == models.TournamentSingleElimination {
+		for _, match := range matches {
+			if !match.Finished || !match.WinnerId.Valid {
+				continue
+			}
+			for _, player := range []sql.NullInt64{match.Player1Id, match.Player2Id} {
+				if player.Valid && player.Int64 != match.WinnerId.Int64 {
+					eliminated[player.Int64] = true
+				}
+			}
+		}
+	}
+	points := roundRobinPoints(matches)
+
+	result := &dto.TournamentDto{
+		Id:             tournament.Id,
+		Name:           tournament.Name,
+		OrganizerId:    tournament.OrganizerId,
+		HabitId:        tournament.HabitId,
+		HabitName:      tournament.HabitName,
+		Days:           tournament.Days,
+		ScoringMode:    tournament.ScoringMode,
+		Format:         tournament.Format,
+		Status:         tournament.Status,
+		InvitationLink: tournamentInvitationLink(tournament.InvitationHash),
+		Players:        []dto.TournamentPlayerDto{},
+		Matches:        matches,
+	}
+	if tournament.WinnerId.Valid {
+		result.WinnerId = &tournament.WinnerId.Int64
+	}
+	for _, player := range players {
+		result.Players = append(result.Players, dto.TournamentPlayerDto{
+			UserId:     player.UserId,
+			FirstName:  player.FirstName,
+			PhotoUrl:   player.PhotoUrl.String,
+			Seed:       player.Seed,
+			Points:     points[player.UserId],
+			Eliminated: eliminated[player.UserId],
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"maxbot/internal/clock"
+	"maxbot/internal/dto"
+	"maxbot/internal/models"
+	"maxbot/internal/repository"
+	"testing"
+	"time"
+)
+
+// tournamentRepository keeps one tournament in memory. Blocks are stored so a
+// flow that checks them between the seeded players would fail.
+type tournamentRepository struct {
+	repository.RepositoryInterface
+	tournament models.TournamentDb
+	players    []models.TournamentPlayerDb
+	matches    []models.TournamentMatchDb
+	duels      map[int64]models.DuelDb
+	blocked    map[[2]int64]bool
+}
+
+func (m *tournamentRepository) FindTournamentById(tournament_id int64) (*models.TournamentDb, error) {
+	copied := m.tournament
+	return &copied, nil
+}
+
+func (m *tournamentRepository) FindTournamentPlayers(tournament_id int64) ([]models.TournamentPlayerDb, error) {
+	return append([]models.TournamentPlayerDb(nil), m.players...), nil
+}
+
+func (m *tournamentRepository) FindHabitById(habit_id int) (*dto.HabitDto, error) {
+	return &dto.HabitDto{Id: habit_id}, nil
+}
+
+func (m *tournamentRepository) IsBlocked(user_id int64, other_id int64) (bool, error) {
+	return m.blocked[[2]int64{user_id, other_id}] || m.blocked[[2]int64{other_id, user_id}], nil
+}
+
+func (m *tournamentRepository) StartTournament(tournament_id int64, matches []models.TournamentMatchDb) error {
+	m.tournament.Status = models.TournamentActive
+	for i, match := range matches {
+		match.Id = int64(i + 1)
+		m.matches = append(m.matches, match)
+	}
+	return nil
+}
+
+func (m *tournamentRepository) FindTournamentMatches(tournament_id int64) ([]models.TournamentMatchDb, error) {
+	return append([]models.TournamentMatchDb(nil), m.matches...), nil
+}
+
+func (m *tournamentRepository) StartTournamentMatchDuel(match_id int64, player1_id int64, player2_id int64, habit_id int, settings models.DuelSettings, target int) error {
+	for i := range m.matches {
+		if m.matches[i].Id != match_id {
+			continue
+		}
+		if m.matches[i].DuelId.Valid {
+			return errors.New("match duel has already been started")
+		}
+		duelId := int64(len(m.duels) + 1)
+		m.duels[duelId] = models.DuelDb{
+			Id:       int(duelId),
+			Status:   "active",
+			Target:   target,
+			User1_id: player1_id,
+			User2_id: sql.NullInt64{Int64: player2_id, Valid: true},
+		}
+		m.matches[i].DuelId = sql.NullInt64{Int64: duelId, Valid: true}
+		return nil
+	}
+	return errors.New("match does not exist")
+}
+
+// TestStartTournamentWithBlockedPairing seeds two players who blocked each other
+// into the same first round match: the bracket still starts both duels.
+func TestStartTournamentWithBlockedPairing(t *testing.T) {
+	const organizerID, tournamentID = 1, 5
+	repo := &tournamentRepository{
+		tournament: models.TournamentDb{
+			Id:          tournamentID,
+			OrganizerId: organizerID,
+			HabitId:     3,
+			Days:        7,
+			ScoringMode: models.ScoringFirstToTarget,
+			Format:      models.TournamentSingleElimination,
+			Status:      models.TournamentRegistration,
+		},
+		duels: map[int64]models.DuelDb{},
+		// Seeds 1 and 4 meet in the first round
+		blocked: map[[2]int64]bool{{1, 4}: true},
+	}
+	for seed := 1; seed <= 4; seed++ {
+		repo.players = append(repo.players, models.TournamentPlayerDb{UserId: int64(seed), Seed: seed})
+	}
+	fake := clock.NewFake(time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC))
+	s := &Service{Repository: repo, Clock: fake}
+
+	if err := s.StartTournament(organizerID, tournamentID); err != nil {
+		t.Fatalf("StartTournament: %v", err)
+	}
+
+	for _, match := range repo.matches {
+		if match.Round != 1 {
+			if match.DuelId.Valid {
+				t.Errorf("round %d match %d started before its players are known", match.Round, match.Position)
+			}
+			continue
+		}
+		if !match.DuelId.Valid {
+			t.Fatalf("round 1 match %d has no duel", match.Position)
+		}
+		duel := repo.duels[match.DuelId.Int64]
+		if duel.Status != "active" {
+			t.Errorf("round 1 match %d duel status = %q, want active", match.Position, duel.Status)
+		}
+		if duel.User1_id != match.Player1Id.Int64 || duel.User2_id.Int64 != match.Player2Id.Int64 {
+			t.Errorf("round 1 match %d duel players = %d, %d, want %d, %d", match.Position,
+				duel.User1_id, duel.User2_id.Int64, match.Player1Id.Int64, match.Player2Id.Int64)
+		}
+		if duel.Target != 7 {
+			t.Errorf("round 1 match %d duel target = %d, want 7", match.Position, duel.Target)
+		}
+	}
+	if first := repo.matches[0]; first.Player1Id.Int64 != 1 || first.Player2Id.Int64 != 4 {
+		t.Errorf("first match players = %d, %d, want the blocked pair 1, 4", first.Player1Id.Int64, first.Player2Id.Int64)
+	}
+}
@@ -23,16 +23,80 @@ type WinnerId = {
     Valid: boolean
 }
 
+export type Schedule = {
+    type: 'daily' | 'weekdays' | 'weekly',
+    weekdays?: number[],
+    times_per_week?: number,
+}
+
+type Place = {
+    Int64: number,
+    Valid: boolean,
+}
+
+export type Participant = {
+    user_id: number,
+    first_name: string,
+    photo_url: UserPhotoUrl,
+    completed: number,
+    volume: number,
+    streak: number,
+    best_streak: number,
+    place: Place,
+    team_id: Place,
+}
+
+export type DuelTeam = {
+    team_id: number,
+    name: string,
+    members: number,
+    score: number,
+    volume: number,
+    place: Place,
+}
+
+export type TeamMember = {
+    user_id: number,
+    first_name: string,
+    photo_url: string,
+}
+
+export type Team = {
+    id: number,
+    name: string,
+    owner_id: number,
+    invitation_link: string,
+    members: TeamMember[],
+}
+
 export type Duel = {
     id: number,
     duration_in_days: number,
+    target: number,
+    schedule: Schedule,
     habit_id: number,
     habit_name: string,
     habit_category: string,
+    habit_unit: string,
+    habit_daily_target: number,
+    scoring_mode: 'first_to_target' | 'highest_in_period' | 'volume' | 'last_one_standing',
+    max_participants: number,
+    duel_type: 'individual' | 'team',
+    team_scoring: 'combined' | 'average',
+    stake: number,
+    rematch_of: WinnerId,
+    invited_user_id: WinnerId,
+    proof_rules: ProofRules,
     user1_id: number,
     user2_id: User2_id,
     user1_completed: number,
     user2_completed: number,
+    user1_volume: number,
+    user2_volume: number,
+    user1_streak: number,
+    user2_streak: number,
+    user1_best_streak: number,
+    user2_best_streak: number,
     user1_first_name: string,
     user2_first_name: User2_first_name,
     user1_photo_url: UserPhotoUrl,
@@ -40,7 +104,63 @@ export type Duel = {
     start_date: string,
     end_date: EndDate,
     winner_id: WinnerId,
-    status: 'active' | 'invited' | 'ended',
+    winner_team_id: Place,
+    status: 'active' | 'invited' | 'ended' | 'declined' | 'cancelled',
+    participants: Participant[],
+    teams?: DuelTeam[],
+}
+
+export type ProofRules = {
+    photo_required: boolean,
+    min_message_length: number,
+    not_before: string,
+    not_after: string,
+    timezone: string,
+}
+
+export type CategoryRating = {
+    category: string,
+    rating: number,
+}
+
+export type RatingChange = {
+    duel_id: number,
+    category: string,
+    old_rating: number,
+    new_rating: number,
+    result: 'win' | 'draw' | 'loss' | 'forfeit',
+    created_at: string,
+}
+
+export type Season = {
+    id: number,
+    name: string,
+    start_date: string,
+    end_date: string,
+    status: 'active' | 'closed',
+}
+
+export type SeasonStanding = {
+    rank: number,
+    user_id: number,
+    first_name: string,
+    photo_url: string,
+    wins: number,
+    duels_played: number,
+    completions: number,
+    reward?: 'champion' | 'silver' | 'bronze' | 'top10',
+}
+
+export type SeasonStandings = {
+    season: Season,
+    standings: SeasonStanding[],
+}
+
+export type Achievement = {
+    code: string,
+    title: string,
+    description: string,
+    awarded_at: string,
 }
 
 export type UserInfo = {
@@ -48,16 +168,33 @@ export type UserInfo = {
     streak: number,
     wins: number,
     winrate: number,
+    season: Season | null,
+    season_wins: number,
+    season_duels: number,
+    season_completions: number,
+    season_rank: number,
     first_name: string,
     photo_url: string,
     last_time_contributed: string,
+    streak_freezes: number,
+    frozen_days: string[],
+    visibility: Visibility,
+    coins: number,
+    rating: number,
+    category_ratings: CategoryRating[],
+    rating_history: RatingChange[],
+    achievements: Achievement[],
     duels_info: Duel[]
 }
 
 export type Habit = {
     category: string,
     id: number,
-    name: string
+    name: string,
+    unit: string,
+    daily_target: number,
+    streak: number,
+    best_streak: number
 }
 
 export type Log = {
@@ -67,5 +204,245 @@ export type Log = {
     message: string,
     owner_id: number,
     photo: string,
-    max_id: string
-}
\ No newline at end of file
+    max_id: string,
+    owner_first_name: string,
+    team_id: number | null,
+    team_name: string | null,
+    value: number | null,
+    counted: boolean,
+    disputes: Dispute[],
+    reactions: { emoji: Reaction, count: number }[],
+    my_reaction: Reaction | '',
+    comments_count: number,
+}
+
+export type Reaction = '👍' | '🔥' | '💪' | '👏' | '😂' | '😮'
+
+export type LogComment = {
+    id: number,
+    log_id: number,
+    parent_id: WinnerId,
+    user_id: number,
+    first_name: string,
+    photo_url: string,
+    message: string,
+    created_at: string,
+}
+
+export type Dispute = {
+    id: number,
+    log_id: number,
+    duel_id: number,
+    owner_id: number,
+    disputer_id: number,
+    disputer_first_name: string,
+    reason: string,
+    excluded: boolean,
+    status: 'open' | 'upheld' | 'rejected',
+    resolution: { String: 'conceded' | 'withdrawn' | 'admin' | '', Valid: boolean },
+    resolved_by: WinnerId,
+    created_at: string,
+    resolved_at: EndDate,
+}
+export type TournamentPlayer = {
+    user_id: number,
+    first_name: string,
+    photo_url: string,
+    seed: number,
+    points: number,
+    eliminated: boolean,
+}
+
+export type TournamentMatch = {
+    id: number,
+    round: number,
+    position: number,
+    player1_id: Place,
+    player2_id: Place,
+    duel_id: Place,
+    winner_id: Place,
+    finished: boolean,
+}
+
+export type Tournament = {
+    id: number,
+    name: string,
+    organizer_id: number,
+    habit_id: number,
+    habit_name: string,
+    days: number,
+    scoring_mode: Duel['scoring_mode'],
+    format: 'single_elimination' | 'round_robin',
+    status: 'registration' | 'active' | 'ended',
+    invitation_link: string,
+    winner_id: number | null,
+    players: TournamentPlayer[],
+    matches: TournamentMatch[],
+}
+
+export type LobbyEntry = {
+    id: number,
+    user_id: number,
+    first_name: string,
+    photo_url: string,
+    duel_id: number,
+    habit_name: string,
+    habit_category: string,
+    days: number,
+    rating: number,
+    created_at: string,
+}
+
+export type LobbyPostResult = {
+    matched: boolean,
+    duel_id: number,
+    entry_id: number,
+}
+
+export type LeaderboardEntry = {
+    rank: number,
+    user_id: number,
+    first_name: string,
+    photo_url: string,
+    score: number,
+}
+
+export type Leaderboard = {
+    metric: 'wins' | 'streak' | 'rating' | 'completions',
+    window: 'week' | 'month' | 'all',
+    scope: 'global' | 'friends',
+    entries: LeaderboardEntry[],
+    next_cursor: string,
+}
+
+export type CoinEntry = {
+    transaction_id: number,
+    reason: 'check_in' | 'win' | 'stake' | 'payout' | 'refund' | 'reversal',
+    duel_id: WinnerId,
+    amount: number,
+    created_at: string,
+}
+
+export type HeadToHeadResult = 'win' | 'loss' | 'draw'
+
+export type HeadToHeadDuel = {
+    duel_id: number,
+    habit_name: string,
+    habit_category: string,
+    start_date: string,
+    end_date: string,
+    result: HeadToHeadResult,
+    completed: number,
+    opponent_completed: number,
+    rematch_of: WinnerId,
+}
+
+export type HeadToHead = {
+    opponent_id: number,
+    opponent_first_name: string,
+    opponent_photo_url: string,
+    wins: number,
+    losses: number,
+    draws: number,
+    series_result: HeadToHeadResult | '',
+    series_length: number,
+    habits: {
+        habit_name: string,
+        habit_category: string,
+        wins: number,
+        losses: number,
+        draws: number,
+    }[],
+    rematch_chains: {
+        duel_ids: number[],
+        wins: number,
+        losses: number,
+        draws: number,
+    }[],
+    duels: HeadToHeadDuel[],
+}
+
+export type Visibility = 'everyone' | 'friends'
+
+export type FriendshipStatus = 'pending' | 'accepted'
+
+export type Friend = {
+    user_id: number,
+    first_name: string,
+    photo_url: string,
+    since: string,
+    active_duels: Duel[],
+}
+
+export type FriendRequest = {
+    user_id: number,
+    first_name: string,
+    photo_url: string,
+    incoming: boolean,
+    created_at: string,
+}
+
+export type FriendSuggestion = {
+    user_id: number,
+    first_name: string,
+    photo_url: string,
+    shared_duels: number,
+}
+
+export type BlockedUser = {
+    user_id: number,
+    first_name: string,
+    photo_url: string,
+    created_at: string,
+}
+
+export type Profile = {
+    user_id: number,
+    first_name: string,
+    photo_url: string,
+    is_friend: boolean,
+    hidden: boolean,
+    streak: number,
+    wins: number,
+    rating: number,
+    achievements: Achievement[],
+    active_duels: Duel[],
+}
+
+export type ReportReason = 'spam' | 'abuse' | 'inappropriate' | 'fake_proof' | 'other'
+
+export type ReportAction = 'hide_log' | 'suspend_user' | 'dismiss'
+
+export type Report = {
+    id: number,
+    reporter_id: number,
+    reporter_first_name: string,
+    user_id: number,
+    user_first_name: string,
+    user_suspended: boolean,
+    log_id: WinnerId,
+    log_message: { String: string, Valid: boolean },
+    log_hidden: boolean,
+    reason: ReportReason,
+    details: string,
+    status: 'open' | 'resolved',
+    action: { String: string, Valid: boolean },
+    resolved_by: WinnerId,
+    created_at: string,
+}
+
+export type Backfill = {
+    id: number,
+    duel_id: number,
+    owner_id: number,
+    owner_first_name: string,
+    day: string,
+    message: string,
+    photo?: string,
+    value: number | null,
+    status: 'pending' | 'approved' | 'rejected',
+    resolved_by: WinnerId,
+    log_id: WinnerId,
+    created_at: string,
+    resolved_at: EndDate,
+}
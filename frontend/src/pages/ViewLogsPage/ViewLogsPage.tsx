@@ -50,7 +50,8 @@ function ViewLogsPage() {
         if (!duelID) return;
 
         const response = await fetch(
-            `${API_BASE}/duel/getDuelLogs?duel_id=${encodeURIComponent(duelID)}`
+            `${API_BASE}/duel/getDuelLogs?duel_id=${encodeURIComponent(duelID)}` +
+            (maxId ? `&max_id=${encodeURIComponent(maxId)}` : "")
         );
 
         if (!response.ok) {